@@ -3,6 +3,7 @@
 // Default configuration
 const DEFAULT_CONFIG = {
   serverUrl: 'http://localhost:8084',
+  apiKey: '',
   autoCollect: false,
   followLinks: false,
   collectDelay: 5000  // 5 seconds delay for content to load (modern Jira uses virtual scrolling)
@@ -204,6 +205,29 @@ chrome.runtime.onMessage.addListener((request, sender, sendResponse) => {
   }
 });
 
+// Build request headers, including the API key when one is configured
+function buildHeaders(config) {
+  const headers = { 'Content-Type': 'application/json' };
+  if (config.apiKey) {
+    headers['X-API-Key'] = config.apiKey;
+  }
+  return headers;
+}
+
+// Build an error from a failed receiver response, using the server's message
+// when it sent one (e.g. asking for an extension update)
+async function serverError(response) {
+  try {
+    const body = await response.json();
+    if (body && body.message) {
+      return new Error(body.message);
+    }
+  } catch (e) {
+    // Not a JSON response
+  }
+  return new Error(`Server returned ${response.status}: ${response.statusText}`);
+}
+
 // Handle page data from content script
 async function handlePageData(pageData, tab) {
   // Get server URL from config
@@ -233,14 +257,12 @@ async function handlePageData(pageData, tab) {
   try {
     const response = await fetch(serverUrl, {
       method: 'POST',
-      headers: {
-        'Content-Type': 'application/json'
-      },
+      headers: buildHeaders(config),
       body: JSON.stringify(payload)
     });
 
     if (!response.ok) {
-      throw new Error(`Server returned ${response.status}: ${response.statusText}`);
+      throw await serverError(response);
     }
 
     const result = await response.json();
@@ -271,7 +293,8 @@ async function collectPageFromTab(tabId) {
       return {
         html: document.documentElement.outerHTML,
         url: window.location.href,
-        title: document.title
+        title: document.title,
+        loadedAt: new Date(performance.timeOrigin).toISOString()
       };
     }
   });
@@ -298,7 +321,9 @@ async function collectPageFromTab(tabId) {
     data: {
       pageType: 'generic',
       html: pageData.html,
-      url: pageData.url
+      url: pageData.url,
+      // Lets the server ignore tickets cleared after this tab was loaded
+      page_loaded_at: pageData.loadedAt
     },
     collector: {
       name: 'aktis-jira-collector-extension',
@@ -311,14 +336,12 @@ async function collectPageFromTab(tabId) {
   // Send to server
   const response = await fetch(serverUrl, {
     method: 'POST',
-    headers: {
-      'Content-Type': 'application/json'
-    },
+    headers: buildHeaders(config),
     body: JSON.stringify(payload)
   });
 
   if (!response.ok) {
-    throw new Error(`Server returned ${response.status}: ${response.statusText}`);
+    throw await serverError(response);
   }
 
   const result = await response.json();
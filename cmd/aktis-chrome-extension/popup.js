@@ -1,9 +1,13 @@
 // Popup script for Aktis Jira Collector
 
+// Last loaded configuration (keeps settings this popup doesn't edit, such as the API key)
+let currentConfig = {};
+
 // Load saved configuration on popup open
 document.addEventListener('DOMContentLoaded', () => {
   chrome.runtime.sendMessage({ type: 'GET_CONFIG' }, (response) => {
     const config = response.config;
+    currentConfig = config;
     document.getElementById('serverUrl').value = config.serverUrl;
     document.getElementById('autoCollect').checked = config.autoCollect;
     document.getElementById('followLinks').checked = config.followLinks;
@@ -34,6 +38,7 @@ document.getElementById('collectBtn').addEventListener('click', async () => {
 // Save settings button
 document.getElementById('saveBtn').addEventListener('click', () => {
   const config = {
+    ...currentConfig,
     serverUrl: document.getElementById('serverUrl').value,
     autoCollect: document.getElementById('autoCollect').checked,
     followLinks: document.getElementById('followLinks').checked
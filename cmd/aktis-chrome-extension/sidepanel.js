@@ -3,6 +3,7 @@ console.log('Aktis Jira Collector sidepanel loaded');
 
 let config = {
   serverUrl: 'http://localhost:8084',
+  apiKey: '',
   autoCollect: false,
   autoNavigate: false
 };
@@ -60,6 +61,15 @@ function clearLogs() {
   updateLogDisplay();
 }
 
+// Build request headers, including the API key when one is configured
+function authHeaders() {
+  const headers = { 'Content-Type': 'application/json' };
+  if (config.apiKey) {
+    headers['X-API-Key'] = config.apiKey;
+  }
+  return headers;
+}
+
 // WebSocket connection management
 function connectWebSocket() {
   if (ws && ws.readyState === WebSocket.OPEN) {
@@ -70,7 +80,8 @@ function connectWebSocket() {
   addLog(`Connecting to WebSocket: ${wsUrl}`);
 
   try {
-    ws = new WebSocket(wsUrl);
+    // Pass the API key as a query token; the server rejects the upgrade without it when a key is configured
+    ws = new WebSocket(config.apiKey ? `${wsUrl}?token=${encodeURIComponent(config.apiKey)}` : wsUrl);
 
     ws.onopen = () => {
       addLog('WebSocket connected');
@@ -250,6 +261,7 @@ function loadConfig() {
     if (result.config) {
       config = result.config;
       document.getElementById('server-url').value = config.serverUrl || 'http://localhost:8084';
+      document.getElementById('api-key').value = config.apiKey || '';
       document.getElementById('auto-collect').checked = config.autoCollect || false;
     }
     checkServerStatus();
@@ -265,6 +277,7 @@ document.getElementById('save-settings-btn').addEventListener('click', async ()
   const isAutoCollectEnabled = document.getElementById('auto-collect').checked;
 
   config.serverUrl = document.getElementById('server-url').value;
+  config.apiKey = document.getElementById('api-key').value;
   config.autoCollect = isAutoCollectEnabled;
 
   chrome.storage.sync.set({ config }, async () => {
@@ -333,6 +346,8 @@ async function checkServerStatus() {
       console.log('Server health data:', data);
       statusEl.textContent = 'Online';
       statusEl.className = 'status-value online';
+      statusEl.title = '';
+      await checkServerSetup(statusEl);
     } else {
       console.warn('Server returned non-OK status:', response.status);
       statusEl.textContent = 'Offline';
@@ -346,6 +361,26 @@ async function checkServerStatus() {
 }
 
 
+// Flag setup problems found by the server's self-check
+async function checkServerSetup(statusEl) {
+  try {
+    const response = await fetch(`${config.serverUrl}/selfcheck`);
+    if (!response.ok) {
+      return;
+    }
+    const report = (await response.json()).selfcheck;
+    const problems = report.checks.filter(check => check.status !== 'pass');
+    if (problems.length === 0) {
+      return;
+    }
+    statusEl.textContent = report.status === 'fail' ? 'Online - setup failing' : 'Online - setup incomplete';
+    statusEl.className = 'status-value ' + (report.status === 'fail' ? 'offline' : 'warning');
+    statusEl.title = problems.map(check => `${check.name}: ${check.message}`).join('\n');
+  } catch (error) {
+    console.warn('Failed to check server setup:', error);
+  }
+}
+
 // Detect current page type using server assessment
 async function detectPageType() {
   // Show processing status
@@ -372,7 +407,7 @@ async function detectPageType() {
     // Send to server for assessment
     const response = await fetch(`${config.serverUrl}/assess`, {
       method: 'POST',
-      headers: { 'Content-Type': 'application/json' },
+      headers: authHeaders(),
       body: JSON.stringify({
         url: pageData.url,
         html: pageData.html
@@ -553,7 +588,7 @@ document.getElementById('clear-buffer-btn').addEventListener('click', async () =
   try {
     const response = await fetch(`${config.serverUrl}/database`, {
       method: 'DELETE',
-      headers: { 'Content-Type': 'application/json' }
+      headers: authHeaders()
     });
 
     if (response.ok) {
@@ -697,7 +732,7 @@ async function loadCounts() {
     }
 
     // Load tickets
-    const ticketsResp = await fetch(`${config.serverUrl}/database`);
+    const ticketsResp = await fetch(`${config.serverUrl}/database`, { headers: authHeaders() });
     if (ticketsResp.ok) {
       const data = await ticketsResp.json();
       document.getElementById('tickets-count').textContent = data.count || 0;
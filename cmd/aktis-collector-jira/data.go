@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+	"aktis-collector-jira/internal/services"
+)
+
+// openStorage opens the configured database for a one-off command
+func openStorage(cfg *common.Config) (interfaces.Storage, error) {
+	storage, err := services.NewStorage(&cfg.Storage)
+	if errors.Is(err, services.ErrDatabaseLocked) {
+		return nil, fmt.Errorf("%w - stop the running collector and try again", err)
+	}
+	return storage, err
+}
+
+// runExport writes all projects and tickets to an NDJSON file
+func runExport(cfg *common.Config, path string) error {
+	storage, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	// Write to a temporary file so a failed export never leaves a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	counts, err := services.ExportData(storage, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	fmt.Printf("Exported %d projects and %d tickets to %s\n", counts.Projects, counts.TotalTickets(), path)
+	printTicketCounts(counts)
+	return nil
+}
+
+// runImport loads projects and tickets from an NDJSON file written by runExport
+func runImport(cfg *common.Config, path string, force bool) error {
+	if cfg.Storage.ReadOnly {
+		return fmt.Errorf("storage.read_only is set - import into the database the writing collector uses")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	storage, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	counts, err := services.ImportData(storage, file, force)
+	if err != nil {
+		if counts != nil && (counts.Projects > 0 || counts.TotalTickets() > 0) {
+			fmt.Printf("Imported %d projects and %d tickets before the error\n", counts.Projects, counts.TotalTickets())
+			return fmt.Errorf("%w: %w", errPartialImport, err)
+		}
+		return err
+	}
+
+	fmt.Printf("Imported %d projects and %d tickets from %s\n", counts.Projects, counts.TotalTickets(), path)
+	printTicketCounts(counts)
+	if counts.Tombstoned > 0 {
+		fmt.Printf("Skipped %d tickets of projects cleared since they were updated - use -force to import them\n", counts.Tombstoned)
+	}
+	return nil
+}
+
+// optionalPath is a flag that can be given bare (-backup) or with a value
+// (-backup=/path/to/file)
+type optionalPath struct {
+	set  bool
+	path string
+}
+
+func (p *optionalPath) String() string   { return p.path }
+func (p *optionalPath) IsBoolFlag() bool { return true }
+
+func (p *optionalPath) Set(value string) error {
+	p.set = value != "false"
+	if value != "true" && value != "false" {
+		p.path = value
+	}
+	return nil
+}
+
+// runBackup copies the database to path, or to a timestamped file in the
+// configured backup directory when path is empty
+func runBackup(cfg *common.Config, path string) error {
+	if path == "" {
+		name := strings.TrimSuffix(filepath.Base(cfg.Storage.DatabasePath), filepath.Ext(cfg.Storage.DatabasePath))
+		path = filepath.Join(cfg.Storage.BackupDir, fmt.Sprintf("%s-%s.db", name, time.Now().Format("20060102-150405")))
+	}
+
+	storage, err := services.NewReadOnlyStorage(&cfg.Storage)
+	if errors.Is(err, services.ErrDatabaseLocked) {
+		return fmt.Errorf("%w - stop the running collector and try again", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	size, err := storage.Backup(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Backup written to %s (%d bytes)\n", path, size)
+	return nil
+}
+
+// runClear deletes all stored projects and tickets after confirmation
+func runClear(cfg *common.Config, confirmed bool) error {
+	storage, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	projects, err := storage.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+	tickets, err := storage.QueryTickets(models.TicketQuery{PageSize: 1, IncludeReferences: true})
+	if err != nil {
+		return fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	if !confirmed {
+		fmt.Printf("This will permanently delete %d projects and %d tickets from %s.\n", len(projects), tickets.Total, cfg.Storage.DatabasePath)
+		fmt.Print("Type 'yes' to continue: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) != "yes" {
+			return errCancelled
+		}
+	}
+
+	if err := storage.ClearAllTickets(); err != nil {
+		return fmt.Errorf("failed to clear tickets: %w", err)
+	}
+	if err := storage.ClearAllProjects(); err != nil {
+		return fmt.Errorf("failed to clear projects: %w", err)
+	}
+
+	fmt.Printf("Removed %d projects and %d tickets from %s\n", len(projects), tickets.Total, cfg.Storage.DatabasePath)
+	return nil
+}
+
+// Project sources in the -list-projects output
+const (
+	projectSourceConfigured = "configured"
+	projectSourceDiscovered = "discovered"
+)
+
+// projectListing is one row of the -list-projects output. Remote is only set
+// with -remote: "yes" when Jira still has the project, "missing" when not.
+type projectListing struct {
+	Key        string `json:"key"`
+	Name       string `json:"name"`
+	Source     string `json:"source"`
+	Disabled   bool   `json:"disabled"`
+	Tickets    int    `json:"tickets"`
+	LastUpdate string `json:"last_update"`
+	Remote     string `json:"remote,omitempty"`
+}
+
+// runListProjects prints the stored projects and the projects named in
+// [projects] with their ticket counts and whether collection is disabled, as
+// a table or as JSON when asJSON is
+// set. With remote the Jira project list is fetched and projects Jira no
+// longer has are marked missing.
+func runListProjects(cfg *common.Config, asJSON, remote bool) error {
+	// Names of the Jira projects by key, filled with -remote
+	var remoteNames map[string]string
+	if remote {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Jira.TimeoutSeconds)*time.Second)
+		defer cancel()
+		remoteProjects, err := services.FetchJiraProjects(ctx, &cfg.Jira)
+		if err != nil {
+			return err
+		}
+		remoteNames = make(map[string]string, len(remoteProjects))
+		for _, project := range remoteProjects {
+			remoteNames[strings.ToUpper(project.Key)] = project.Name
+		}
+	}
+
+	storage, err := services.NewReadOnlyStorage(&cfg.Storage)
+	if errors.Is(err, services.ErrDatabaseLocked) {
+		return fmt.Errorf("%w - stop the running collector and try again", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	projects, err := storage.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	// Configured projects nothing has been collected for yet are listed too
+	for _, key := range cfg.Projects.Projects {
+		key = strings.ToUpper(key)
+		if !slices.ContainsFunc(projects, func(p *models.ProjectData) bool { return p.Key == key }) {
+			projects = append(projects, &models.ProjectData{Key: key})
+		}
+	}
+
+	listings := make([]projectListing, 0, len(projects))
+	for _, project := range projects {
+		tickets, err := storage.QueryTickets(models.TicketQuery{Project: project.Key, PageSize: 1, IncludeReferences: true})
+		if err != nil {
+			return fmt.Errorf("failed to count tickets for %s: %w", project.Key, err)
+		}
+		lastUpdate, _ := storage.GetLastUpdate(project.Key)
+
+		listing := projectListing{
+			Key:        project.Key,
+			Name:       project.Name,
+			Source:     projectSourceDiscovered,
+			Disabled:   project.Disabled,
+			Tickets:    tickets.Total,
+			LastUpdate: lastUpdate,
+		}
+		if cfg.Projects.IsConfigured(project.Key) {
+			listing.Source = projectSourceConfigured
+		}
+		if remote {
+			listing.Remote = "missing"
+			if name, ok := remoteNames[strings.ToUpper(project.Key)]; ok {
+				listing.Remote = "yes"
+				if listing.Name == "" {
+					listing.Name = name
+				}
+			}
+		}
+		listings = append(listings, listing)
+	}
+	slices.SortFunc(listings, func(a, b projectListing) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listings)
+	}
+
+	if len(listings) == 0 {
+		fmt.Println("No projects stored")
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "KEY\tNAME\tSOURCE\tSTATE\tTICKETS\tLAST UPDATE"
+	if remote {
+		header += "\tREMOTE"
+	}
+	fmt.Fprintln(writer, header)
+	for _, listing := range listings {
+		lastUpdate := listing.LastUpdate
+		if lastUpdate == "" {
+			lastUpdate = "never"
+		}
+		state := "enabled"
+		if listing.Disabled {
+			state = models.FreshnessDisabled
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s", listing.Key, listing.Name, listing.Source, state, listing.Tickets, lastUpdate)
+		if remote {
+			fmt.Fprintf(writer, "\t%s", listing.Remote)
+		}
+		fmt.Fprintln(writer)
+	}
+	return writer.Flush()
+}
+
+// databaseStats is the -stats output
+type databaseStats struct {
+	DatabasePath    string               `json:"database_path"`
+	DatabaseSize    int64                `json:"database_size"`
+	Tickets         handlers.TicketStats `json:"tickets"`
+	LastCollections map[string]string    `json:"last_collections"`
+}
+
+// runStats prints a summary of the stored tickets using the same aggregation
+// as the /stats endpoint, as text or as JSON when asJSON is set
+func runStats(cfg *common.Config, asJSON bool) error {
+	storage, err := services.NewReadOnlyStorage(&cfg.Storage)
+	if errors.Is(err, services.ErrDatabaseLocked) {
+		return fmt.Errorf("%w - stop the running collector and try again", err)
+	}
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	info, err := os.Stat(cfg.Storage.DatabasePath)
+	if err != nil {
+		return fmt.Errorf("failed to read database file: %w", err)
+	}
+
+	tickets, err := storage.LoadAllTickets()
+	if err != nil {
+		return fmt.Errorf("failed to load tickets: %w", err)
+	}
+	projects, err := storage.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	stats := databaseStats{
+		DatabasePath:    cfg.Storage.DatabasePath,
+		DatabaseSize:    info.Size(),
+		Tickets:         handlers.ComputeTicketStats(tickets),
+		LastCollections: make(map[string]string, len(projects)),
+	}
+	for _, project := range projects {
+		if lastUpdate, err := storage.GetLastUpdate(project.Key); err == nil && lastUpdate != "" {
+			stats.LastCollections[project.Key] = lastUpdate
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Database\t%s (%d bytes)\n", stats.DatabasePath, stats.DatabaseSize)
+	fmt.Fprintf(writer, "Tickets\t%d\n", stats.Tickets.Total)
+	if stats.Tickets.OldestUpdated != nil {
+		fmt.Fprintf(writer, "Oldest update\t%s\n", stats.Tickets.OldestUpdated.Format(time.RFC3339))
+		fmt.Fprintf(writer, "Newest update\t%s\n", stats.Tickets.NewestUpdated.Format(time.RFC3339))
+	}
+
+	for _, breakdown := range []struct {
+		title  string
+		counts map[string]int
+	}{
+		{"By project", stats.Tickets.ByProject},
+		{"By status", stats.Tickets.ByStatus},
+		{"By type", stats.Tickets.ByType},
+	} {
+		if len(breakdown.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(writer, "\n%s\t\n", breakdown.title)
+		for _, entry := range handlers.SortedCounts(breakdown.counts) {
+			fmt.Fprintf(writer, "  %s\t%d\n", entry.Label, entry.Count)
+		}
+	}
+
+	if len(projects) > 0 {
+		fmt.Fprintf(writer, "\nLast collection\t\n")
+		for _, project := range projects {
+			lastUpdate, ok := stats.LastCollections[project.Key]
+			if !ok {
+				lastUpdate = "never"
+			}
+			fmt.Fprintf(writer, "  %s\t%s\n", project.Key, lastUpdate)
+		}
+	}
+	return writer.Flush()
+}
+
+func printTicketCounts(counts *services.TransferCounts) {
+	for _, key := range counts.ProjectKeys() {
+		fmt.Printf("  %-12s %d tickets\n", key, counts.Tickets[key])
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+	"aktis-collector-jira/internal/services"
+)
+
+// testConfig returns the default configuration with the database and backups
+// in a temporary directory
+func testConfig(t *testing.T) *common.Config {
+	t.Helper()
+	cfg := common.DefaultConfig()
+	dir := t.TempDir()
+	cfg.Storage.DatabasePath = filepath.Join(dir, "tickets.db")
+	cfg.Storage.BackupDir = filepath.Join(dir, "backups")
+	return cfg
+}
+
+// seedDatabase stores projects ABC and XYZ with three and one tickets
+func seedDatabase(t *testing.T, cfg *common.Config) {
+	t.Helper()
+	withStorage(t, cfg, func(storage interfaces.Storage) {
+		projects := []*models.ProjectData{{Key: "ABC", Name: "Alpha"}, {Key: "XYZ", Name: "Xylophone"}}
+		if _, err := storage.SaveProjects(projects); err != nil {
+			t.Fatalf("SaveProjects: %v", err)
+		}
+		abc := map[string]*models.TicketData{
+			"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Summary: "First", Status: "Open", IssueType: "Bug"},
+			"ABC-2": {Key: "ABC-2", ProjectID: "ABC", Summary: "Second", Status: "Done", IssueType: "Task"},
+			"ABC-3": {Key: "ABC-3", ProjectID: "ABC", Summary: "Third", Status: "Open", IssueType: "Task"},
+		}
+		if _, err := storage.SaveTickets("ABC", abc); err != nil {
+			t.Fatalf("SaveTickets: %v", err)
+		}
+		xyz := map[string]*models.TicketData{"XYZ-1": {Key: "XYZ-1", ProjectID: "XYZ", Summary: "Other", Status: "Open", IssueType: "Bug"}}
+		if _, err := storage.SaveTickets("XYZ", xyz); err != nil {
+			t.Fatalf("SaveTickets: %v", err)
+		}
+	})
+}
+
+// withStorage opens the configured database for the duration of fn
+func withStorage(t *testing.T, cfg *common.Config, fn func(interfaces.Storage)) {
+	t.Helper()
+	storage, err := services.NewStorage(&cfg.Storage)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	defer storage.Close()
+	fn(storage)
+}
+
+// storedTickets returns the tickets in the configured database
+func storedTickets(t *testing.T, cfg *common.Config) map[string]*models.TicketData {
+	t.Helper()
+	var tickets map[string]*models.TicketData
+	withStorage(t, cfg, func(storage interfaces.Storage) {
+		var err error
+		if tickets, err = storage.LoadAllTickets(); err != nil {
+			t.Fatalf("LoadAllTickets: %v", err)
+		}
+	})
+	return tickets
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+	fnErr := fn()
+	writer.Close()
+	return <-output, fnErr
+}
+
+func TestRunExportImport(t *testing.T) {
+	source := testConfig(t)
+	seedDatabase(t, source)
+	exportPath := filepath.Join(t.TempDir(), "exports", "collector.ndjson")
+
+	output, err := captureStdout(t, func() error { return runExport(source, exportPath) })
+	if err != nil {
+		t.Fatalf("runExport: %v", err)
+	}
+	if !strings.Contains(output, "Exported 2 projects and 4 tickets") {
+		t.Errorf("export output = %q", output)
+	}
+
+	target := testConfig(t)
+	output, err = captureStdout(t, func() error { return runImport(target, exportPath, false) })
+	if err != nil {
+		t.Fatalf("runImport: %v", err)
+	}
+	if !strings.Contains(output, "Imported 2 projects and 4 tickets") {
+		t.Errorf("import output = %q", output)
+	}
+	if got, want := storedTickets(t, target), storedTickets(t, source); !reflect.DeepEqual(got, want) {
+		t.Errorf("imported tickets = %v, want %v", got, want)
+	}
+
+	// A file that fails part way reports what was stored
+	broken := filepath.Join(t.TempDir(), "broken.ndjson")
+	data, _ := os.ReadFile(exportPath)
+	if err := os.WriteFile(broken, append(data, "{not json\n"...), 0600); err != nil {
+		t.Fatalf("writing broken export: %v", err)
+	}
+	if _, err := captureStdout(t, func() error { return runImport(testConfig(t), broken, false) }); !errors.Is(err, errPartialImport) {
+		t.Errorf("runImport of a broken file: err = %v, want errPartialImport", err)
+	}
+}
+
+func TestRunBackup(t *testing.T) {
+	cfg := testConfig(t)
+	seedDatabase(t, cfg)
+
+	// Without a path the backup goes to a timestamped file in backup_dir
+	output, err := captureStdout(t, func() error { return runBackup(cfg, "") })
+	if err != nil {
+		t.Fatalf("runBackup: %v", err)
+	}
+	backups, _ := filepath.Glob(filepath.Join(cfg.Storage.BackupDir, "tickets-*.db"))
+	if len(backups) != 1 || !strings.Contains(output, backups[0]) {
+		t.Fatalf("backups = %v, output %q", backups, output)
+	}
+
+	restored := testConfig(t)
+	restored.Storage.DatabasePath = backups[0]
+	if got, want := storedTickets(t, restored), storedTickets(t, cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("backup tickets = %v, want %v", got, want)
+	}
+
+	explicit := filepath.Join(t.TempDir(), "copy.db")
+	if _, err := captureStdout(t, func() error { return runBackup(cfg, explicit) }); err != nil {
+		t.Fatalf("runBackup to %s: %v", explicit, err)
+	}
+	if info, err := os.Stat(explicit); err != nil || info.Size() == 0 {
+		t.Errorf("backup file: %v, %v", info, err)
+	}
+}
+
+// withStdin runs fn with input as standard input
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatalf("writing stdin: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening stdin: %v", err)
+	}
+	defer file.Close()
+	stdin := os.Stdin
+	os.Stdin = file
+	defer func() { os.Stdin = stdin }()
+	fn()
+}
+
+func TestRunClear(t *testing.T) {
+	cfg := testConfig(t)
+	seedDatabase(t, cfg)
+
+	// Anything but "yes" at the prompt leaves the data alone
+	withStdin(t, "no\n", func() {
+		output, err := captureStdout(t, func() error { return runClear(cfg, false) })
+		if !errors.Is(err, errCancelled) {
+			t.Errorf("runClear answered no: err = %v, want errCancelled", err)
+		}
+		if !strings.Contains(output, "permanently delete 2 projects and 4 tickets") {
+			t.Errorf("prompt = %q", output)
+		}
+	})
+	if tickets := storedTickets(t, cfg); len(tickets) != 4 {
+		t.Fatalf("stored %d tickets after cancelling, want 4", len(tickets))
+	}
+
+	withStdin(t, "YES\n", func() {
+		if _, err := captureStdout(t, func() error { return runClear(cfg, false) }); err != nil {
+			t.Errorf("runClear answered yes: %v", err)
+		}
+	})
+	if tickets := storedTickets(t, cfg); len(tickets) != 0 {
+		t.Errorf("stored %d tickets after clearing, want 0", len(tickets))
+	}
+
+	// -yes skips the prompt
+	seedDatabase(t, cfg)
+	output, err := captureStdout(t, func() error { return runClear(cfg, true) })
+	if err != nil || !strings.Contains(output, "Removed 2 projects and 4 tickets") {
+		t.Errorf("runClear confirmed: output %q, err %v", output, err)
+	}
+	withStorage(t, cfg, func(storage interfaces.Storage) {
+		if projects, err := storage.LoadProjects(); err != nil || len(projects) != 0 {
+			t.Errorf("projects after clearing = %v, %v", projects, err)
+		}
+	})
+}
+
+func TestRunListProjects(t *testing.T) {
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"1","key":"ABC","name":"Alpha"},{"id":"3","key":"DEF","name":"Delta"}]`))
+	}))
+	defer jira.Close()
+
+	cfg := testConfig(t)
+	cfg.Projects.Projects = []string{"def"}
+	cfg.Jira.BaseURL = jira.URL
+	cfg.Jira.API.Username = "me@example.com"
+	cfg.Jira.API.APIToken = "secret"
+	seedDatabase(t, cfg)
+	withStorage(t, cfg, func(storage interfaces.Storage) {
+		// Configured but not collected yet
+		if err := storage.SetProjectDisabled("DEF", true); err != nil {
+			t.Fatalf("SetProjectDisabled: %v", err)
+		}
+	})
+
+	listProjects := func(remote bool) []projectListing {
+		t.Helper()
+		output, err := captureStdout(t, func() error { return runListProjects(cfg, true, remote) })
+		if err != nil {
+			t.Fatalf("runListProjects: %v", err)
+		}
+		var listings []projectListing
+		if err := json.Unmarshal([]byte(output), &listings); err != nil {
+			t.Fatalf("decoding %q: %v", output, err)
+		}
+		return listings
+	}
+
+	// The configured project is listed before anything is collected for it
+	want := []projectListing{
+		{Key: "ABC", Name: "Alpha", Source: projectSourceDiscovered, Tickets: 3},
+		{Key: "DEF", Source: projectSourceConfigured, Disabled: true},
+		{Key: "XYZ", Name: "Xylophone", Source: projectSourceDiscovered, Tickets: 1},
+	}
+	got := listProjects(false)
+	for i := range got {
+		got[i].LastUpdate = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listings = %+v\nwant %+v", got, want)
+	}
+
+	// -remote marks projects Jira no longer has and fills in missing names
+	remote := map[string]projectListing{}
+	for _, listing := range listProjects(true) {
+		remote[listing.Key] = listing
+	}
+	if remote["ABC"].Remote != "yes" || remote["XYZ"].Remote != "missing" || remote["DEF"].Remote != "yes" || remote["DEF"].Name != "Delta" {
+		t.Errorf("remote listings = %+v", remote)
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	cfg := testConfig(t)
+	seedDatabase(t, cfg)
+
+	output, err := captureStdout(t, func() error { return runStats(cfg, true) })
+	if err != nil {
+		t.Fatalf("runStats: %v", err)
+	}
+	var stats databaseStats
+	if err := json.Unmarshal([]byte(output), &stats); err != nil {
+		t.Fatalf("decoding %q: %v", output, err)
+	}
+	if stats.DatabasePath != cfg.Storage.DatabasePath || stats.DatabaseSize == 0 || stats.Tickets.Total != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if want := map[string]int{"ABC": 3, "XYZ": 1}; !reflect.DeepEqual(stats.Tickets.ByProject, want) {
+		t.Errorf("by project = %v, want %v", stats.Tickets.ByProject, want)
+	}
+	if want := map[string]int{"Open": 3, "Done": 1}; !reflect.DeepEqual(stats.Tickets.ByStatus, want) {
+		t.Errorf("by status = %v, want %v", stats.Tickets.ByStatus, want)
+	}
+	if len(stats.LastCollections) != 2 {
+		t.Errorf("last collections = %v, want ABC and XYZ", stats.LastCollections)
+	}
+
+	output, err = captureStdout(t, func() error { return runStats(cfg, false) })
+	if err != nil {
+		t.Fatalf("runStats as text: %v", err)
+	}
+	for _, want := range []string{`(?m)^Tickets +4$`, `(?m)^By project`, `(?m)^  ABC +3$`, `(?m)^Last collection`} {
+		if !regexp.MustCompile(want).MatchString(output) {
+			t.Errorf("text output does not match %s:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/services"
+)
+
+// Process exit codes, printed by -help so wrapper scripts can tell failure
+// classes apart
+const (
+	exitSuccess = 0 // completed successfully
+	exitFailure = 1 // unclassified failure or cancelled by the user
+	exitConfig  = 2 // invalid configuration, flags or validation failure
+	exitAuth    = 3 // Jira rejected the credentials
+	exitNetwork = 4 // listen port unavailable, web server failed to start or Jira unreachable
+	exitStorage = 5 // database could not be opened, read or written
+	exitPartial = 6 // command stopped part way, some data was written
+)
+
+var exitCodeDescriptions = []struct {
+	code        int
+	description string
+}{
+	{exitSuccess, "success"},
+	{exitFailure, "unclassified failure or cancelled"},
+	{exitConfig, "invalid configuration, flags or failed -validate"},
+	{exitAuth, "Jira rejected the credentials"},
+	{exitNetwork, "listen port unavailable, web server failed to start, or Jira unreachable or failing"},
+	{exitStorage, "database could not be opened, read or written"},
+	{exitPartial, "partial success, e.g. -import stopped after writing some records"},
+}
+
+var (
+	// errCancelled is returned when the user declines a confirmation prompt
+	errCancelled = errors.New("cancelled")
+
+	// errPartialImport is returned when an import fails after storing records
+	errPartialImport = errors.New("import incomplete")
+
+	// errUsage is returned for flags that cannot be used as given
+	errUsage = errors.New("invalid usage")
+)
+
+// exitCode maps an error to its exit code. Usage errors and configuration
+// problems exit with exitConfig, a locked or full database with exitStorage,
+// and Jira API errors by their CollectorError type; anything unclassified is
+// a plain failure.
+func exitCode(err error) int {
+	var problems common.ValidationErrors
+	var collectorErr *common.CollectorError
+	if errors.As(err, &collectorErr) {
+		switch collectorErr.Type {
+		case common.ErrorTypeConfiguration:
+			return exitConfig
+		case common.ErrorTypeAuth:
+			return exitAuth
+		case common.ErrorTypeNetwork, common.ErrorTypeJira:
+			return exitNetwork
+		}
+	}
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.Is(err, errPartialImport):
+		return exitPartial
+	case errors.Is(err, errCancelled):
+		return exitFailure
+	case errors.Is(err, errUsage), errors.As(err, &problems):
+		return exitConfig
+	case errors.Is(err, services.ErrDatabaseLocked), errors.Is(err, common.ErrStorageFull):
+		return exitStorage
+	default:
+		return exitFailure
+	}
+}
+
+// dataCommandExitCode maps an error from a data command to its exit code.
+// Data commands work on the database, so their unclassified errors are
+// storage errors.
+func dataCommandExitCode(err error) int {
+	if code := exitCode(err); code != exitFailure || errors.Is(err, errCancelled) {
+		return code
+	}
+	return exitStorage
+}
+
+// exitOnError prints a failed data command and exits with its exit code
+func exitOnError(action string, err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s failed: %v\n", action, err)
+	os.Exit(dataCommandExitCode(err))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/services"
+)
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		code        int
+		dataCommand int
+	}{
+		{"success", nil, exitSuccess, exitSuccess},
+		{"config problems", fmt.Errorf("invalid configuration: %w", common.ValidationErrors{"collector.port: must be between 1 and 65535"}), exitConfig, exitConfig},
+		{"usage", fmt.Errorf("%w: -export and -import cannot be used together", errUsage), exitConfig, exitConfig},
+		{"override", launchOverrides{port: -1}.apply(common.DefaultConfig()), exitConfig, exitConfig},
+		{"database locked", fmt.Errorf("%w - stop the running collector and try again", services.ErrDatabaseLocked), exitStorage, exitStorage},
+		{"storage full", fmt.Errorf("failed to save tickets: %w", common.ErrStorageFull), exitStorage, exitStorage},
+		{"jira credentials missing", common.NewConfigurationError("jira_credentials_missing", "jira base_url must be configured"), exitConfig, exitConfig},
+		{"jira auth", common.NewAuthError("jira_credentials_rejected", "jira rejected the credentials"), exitAuth, exitAuth},
+		{"jira unreachable", fmt.Errorf("list: %w", common.NewNetworkError("jira_unreachable", "failed to reach Jira")), exitNetwork, exitNetwork},
+		{"jira error", common.NewJiraError("jira_status", "jira returned 500"), exitNetwork, exitNetwork},
+		{"partial import", fmt.Errorf("%w: stopped at line 7", errPartialImport), exitPartial, exitPartial},
+		{"cancelled", errCancelled, exitFailure, exitFailure},
+		{"generic", errors.New("something went wrong"), exitFailure, exitStorage},
+		{"file error", fmt.Errorf("failed to open export: %w", fs.ErrNotExist), exitFailure, exitStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.code {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, code, tt.code)
+			}
+			if code := dataCommandExitCode(tt.err); code != tt.dataCommand {
+				t.Errorf("dataCommandExitCode(%v) = %d, want %d", tt.err, code, tt.dataCommand)
+			}
+		})
+	}
+}
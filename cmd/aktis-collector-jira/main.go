@@ -7,6 +7,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -33,20 +34,55 @@ func main() {
 		quiet          = flag.Bool("quiet", false, "Suppress banner output")
 		version        = flag.Bool("version", false, "Show version information")
 		help           = flag.Bool("help", false, "Show help message")
-		validateConfig = flag.Bool("validate", false, "Validate configuration file and exit")
+		validateConfig = flag.Bool("validate", false, "Validate configuration, storage and ports and exit (JSON with -quiet)")
+		strict         = flag.Bool("strict", false, "Fail -validate when the config file has unknown keys")
+		exportFile     = flag.String("export", "", "Export all projects and tickets to an NDJSON file and exit")
+		importFile     = flag.String("import", "", "Import projects and tickets from an NDJSON file and exit")
+		clearData      = flag.Bool("clear", false, "Delete all stored projects and tickets and exit")
+		confirm        = flag.Bool("yes", false, "Skip the confirmation prompt for -clear")
+		listProjects   = flag.Bool("list-projects", false, "List stored projects with ticket counts and exit (JSON with -quiet)")
+		remote         = flag.Bool("remote", false, "With -list-projects, mark projects that no longer exist in Jira (needs [jira] credentials)")
+		showStats      = flag.Bool("stats", false, "Summarise the stored tickets and exit (JSON with -quiet)")
+		force          = flag.Bool("force", false, "Allow -init-config to overwrite an existing file and -import to restore recently cleared tickets")
+		overrides      launchOverrides
+		backup         optionalPath
+		initConfig     optionalPath
 	)
+	flag.IntVar(&overrides.port, "port", 0, "Override the collector port from the configuration")
+	flag.StringVar(&overrides.dbPath, "db", "", "Override the database path from the configuration")
+	flag.StringVar(&overrides.logLevel, "log-level", "", "Override the log level from the configuration")
+	flag.StringVar(&overrides.logFormat, "log-format", "", "Override the log format from the configuration")
+	flag.Var(&initConfig, "init-config", "Write a commented default configuration to config.toml, or to the given path, and exit")
+	flag.Var(&backup, "backup", "Back up the database to the backup directory, or to the given path, and exit")
 	flag.Parse()
 
 	// Handle version flag
 	if *version {
 		fmt.Printf("%s v%s (build: %s)\n", pluginName, common.GetVersion(), common.GetBuild())
-		os.Exit(0)
+		os.Exit(exitSuccess)
 	}
 
 	// Handle help flag
 	if *help {
 		showHelp()
-		os.Exit(0)
+		os.Exit(exitSuccess)
+	}
+
+	// Handle init-config before loading, as there may be no config yet
+	if initConfig.set {
+		if err := runInitConfig(initConfig.path, *force); err != nil {
+			fmt.Fprintf(os.Stderr, "Init config failed: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		os.Exit(exitSuccess)
+	}
+
+	// Handle validate flag before loading so config errors are reported as a check
+	if *validateConfig {
+		if !runValidation(*configPath, overrides, *strict, *quiet) {
+			os.Exit(exitConfig)
+		}
+		os.Exit(exitSuccess)
 	}
 
 	// Parse environment from mode
@@ -55,32 +91,58 @@ func main() {
 	// Load configuration with priority: defaults -> TOML
 	cfg, err := common.LoadConfig(*configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
-		os.Exit(1)
+		printConfigError("Failed to load configuration", err)
+		os.Exit(exitConfig)
 	}
 
 	// Update environment from command line
 	cfg.Collector.Environment = environment
 
-	// Handle validate flag
-	if *validateConfig {
-		fmt.Println("Configuration is valid")
-		os.Exit(0)
+	// Command line overrides take precedence over the config file and environment
+	if err := overrides.apply(cfg); err != nil {
+		printConfigError("Invalid command line override", err)
+		os.Exit(exitCode(err))
 	}
 
-	// Initialize logger
-	loggingConfig := &common.LoggingConfig{
-		Level:  "info",
-		Format: "text",
-		Output: "both",
+	// Handle data commands; these open the database directly and exit
+	if *exportFile != "" && *importFile != "" {
+		err := fmt.Errorf("%w: -export and -import cannot be used together", errUsage)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCode(err))
+	}
+	if *exportFile != "" {
+		exitOnError("Export", runExport(cfg, *exportFile))
+		os.Exit(exitSuccess)
+	}
+	if *importFile != "" {
+		exitOnError("Import", runImport(cfg, *importFile, *force))
+		os.Exit(exitSuccess)
+	}
+	if *listProjects {
+		exitOnError("List projects", runListProjects(cfg, *quiet, *remote))
+		os.Exit(exitSuccess)
+	}
+	if *showStats {
+		exitOnError("Stats", runStats(cfg, *quiet))
+		os.Exit(exitSuccess)
+	}
+	if *clearData {
+		exitOnError("Clear", runClear(cfg, *confirm))
+		os.Exit(exitSuccess)
 	}
-	if err := common.InitLogger(loggingConfig); err != nil {
+	if backup.set {
+		exitOnError("Backup", runBackup(cfg, backup.path))
+		os.Exit(exitSuccess)
+	}
+
+	// Initialize logger from the [logging] section, env and flag overrides
+	if err := common.InitLogger(&cfg.Logging); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 
 	// Now get the configured logger
-	logger := common.GetLogger()
+	logger := common.GetLogger("app")
 
 	// Log startup information first to ensure log file is created
 	logger.Info().
@@ -93,60 +155,95 @@ func main() {
 		Str("config_path", *configPath).
 		Msg("Configuration loaded")
 
+	logFile := "none"
+	if cfg.Logging.Output != "console" {
+		logFile = common.GetLogFilePath()
+	}
+	logger.Info().
+		Str("log_level", cfg.Logging.Level).
+		Str("log_format", cfg.Logging.Format).
+		Str("log_output", cfg.Logging.Output).
+		Str("log_file", logFile).
+		Msg("Logging configured")
+
+	warnUnknownKeys(cfg, logger)
+
+	if overrides.any() {
+		logger.Info().
+			Int("port", cfg.Collector.Port).
+			Str("database_path", cfg.Storage.DatabasePath).
+			Str("log_level", cfg.Logging.Level).
+			Str("log_format", cfg.Logging.Format).
+			Msg("Command line overrides applied")
+	}
+
 	// Display startup banner after initial log messages (to ensure log file exists)
 	if !*quiet {
 		logFilePath := common.GetLogFilePath()
-		common.PrintBanner(pluginName, environment, "Server", logFilePath)
+		mode := "Server"
+		if cfg.Storage.ReadOnly {
+			mode = "Server (read-only)"
+		}
+		common.PrintBanner(pluginName, environment, mode, cfg.ServerURL(), cfg.ListenAddress(), logFilePath)
 	}
 
 	// Initialize services
 	logger.Info().Msg("Initializing services...")
 
 	// Create storage
-	storage, err := services.NewStorage(&cfg.Storage)
+	storage, err := services.OpenStorage(&cfg.Storage, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to initialize storage")
-		os.Exit(1)
+		os.Exit(exitStorage)
 	}
-	defer storage.Close()
 
 	logger.Info().Msg("Services initialized successfully")
 
 	// Server mode - start web server and run continuously
-	runServerMode(cfg, storage, logger, environment)
+	code := runServerMode(cfg, storage, logger, *configPath, overrides, *quiet)
+	storage.Close()
 
 	logger.Info().Msg("Aktis Collector Jira Service shutdown complete")
+	os.Exit(code)
 }
 
-func runServerMode(cfg *common.Config, storage interfaces.Storage, logger arbor.ILogger, environment string) {
+// runServerMode serves until a shutdown signal and returns the exit code
+func runServerMode(cfg *common.Config, storage interfaces.Storage, logger arbor.ILogger, configPath string, overrides launchOverrides, quiet bool) int {
 	logger.Info().Msg("Starting in server mode")
 
 	// Create web server
-	webServer, err := services.NewWebServer(cfg, storage, logger)
+	webServer, err := services.NewWebServer(cfg, storage, common.GetLogger("webserver"))
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create web server")
-		return
+		return exitFailure
+	}
+	if !quiet {
+		common.PrintSelfCheck(webServer.SelfCheck())
 	}
 
 	// Start web server
 	ctx := context.Background()
 	if err := webServer.Start(ctx); err != nil {
 		logger.Error().Err(err).Msg("Failed to start web server")
-		return
+		return exitNetwork
 	}
 
 	logger.Info().
-		Int("port", cfg.Collector.Port).
+		Str("address", cfg.ListenAddress()).
+		Str("url", cfg.ServerURL()).
 		Msg("Web server started successfully")
 
-	// Set up signal handling for graceful shutdown
+	// Set up signal handling for graceful shutdown and configuration reload
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	logger.Info().Msg("Server running - press Ctrl+C to stop")
+	logger.Info().Msg("Server running - press Ctrl+C to stop, send SIGHUP to reload configuration")
 
-	// Wait for shutdown signal
-	<-sigChan
+	// Wait for shutdown signal, reloading the configuration on SIGHUP
+	for sig := <-sigChan; sig == syscall.SIGHUP; sig = <-sigChan {
+		logger.Info().Msg("Reload signal received")
+		reloadConfig(cfg, configPath, overrides, logger)
+	}
 	logger.Info().Msg("Shutdown signal received")
 
 	// Stop web server
@@ -155,6 +252,66 @@ func runServerMode(cfg *common.Config, storage interfaces.Storage, logger arbor.
 	}
 
 	logger.Info().Msg("Server mode shutdown complete")
+	return exitSuccess
+}
+
+// printConfigError prints a configuration error, listing each validation
+// problem on its own line
+func printConfigError(message string, err error) {
+	var problems common.ValidationErrors
+	if !errors.As(err, &problems) || len(problems) == 1 {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %d problems\n", message, len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+}
+
+// warnUnknownKeys logs config file keys that were ignored, usually typos
+func warnUnknownKeys(cfg *common.Config, logger arbor.ILogger) {
+	for _, key := range cfg.UnknownKeys() {
+		logger.Warn().
+			Str("key", key.Key).
+			Int("line", key.Line).
+			Int("column", key.Column).
+			Msg("Unknown configuration key ignored")
+	}
+}
+
+// launchOverrides are command line values that take precedence over the
+// config file and environment
+type launchOverrides struct {
+	port      int
+	dbPath    string
+	logLevel  string
+	logFormat string
+}
+
+func (o launchOverrides) any() bool {
+	return o.port != 0 || o.dbPath != "" || o.logLevel != "" || o.logFormat != ""
+}
+
+// apply sets the overrides that were given and revalidates the config
+func (o launchOverrides) apply(cfg *common.Config) error {
+	if o.port < 0 {
+		return fmt.Errorf("%w: -port %d", errUsage, o.port)
+	}
+	if o.port != 0 {
+		cfg.Collector.Port = o.port
+	}
+	if o.dbPath != "" {
+		cfg.Storage.DatabasePath = o.dbPath
+	}
+	if o.logLevel != "" {
+		cfg.Logging.Level = strings.ToLower(o.logLevel)
+	}
+	if o.logFormat != "" {
+		cfg.Logging.Format = strings.ToLower(o.logFormat)
+	}
+	return cfg.Validate()
 }
 
 func parseMode(mode string) string {
@@ -176,13 +333,35 @@ func showHelp() {
 	fmt.Println("Flags:")
 	fmt.Println("  -mode string        Environment mode: 'dev', 'development', 'prod', or 'production' (default \"dev\")")
 	fmt.Println("  -config string      Configuration file path")
+	fmt.Println("  -port int           Override the collector port from the configuration")
+	fmt.Println("  -db string          Override the database path from the configuration")
+	fmt.Println("  -log-level string   Override the log level: debug, info, warn, error, fatal or panic")
+	fmt.Println("  -log-format string  Override the log format: text or json")
 	fmt.Println("  -quiet              Suppress banner output")
 	fmt.Println("  -version            Show version information")
 	fmt.Println("  -help               Show help message")
-	fmt.Println("  -validate           Validate configuration file and exit")
+	fmt.Println("  -validate           Validate configuration, storage and ports and exit (JSON with -quiet)")
+	fmt.Println("  -strict             Fail -validate when the config file has unknown keys")
+	fmt.Println("  -init-config[=path] Write a commented default configuration to config.toml, or to path, and exit")
+	fmt.Println("  -force              Allow -init-config to overwrite an existing file and -import")
+	fmt.Println("                      to restore recently cleared tickets")
+	fmt.Println("  -export string      Export all projects and tickets to an NDJSON file and exit")
+	fmt.Println("  -import string      Import projects and tickets from an NDJSON file and exit")
+	fmt.Println("  -backup[=path]      Back up the database to the backup directory, or to path, and exit")
+	fmt.Println("  -clear              Delete all stored projects and tickets and exit (prompts unless -yes)")
+	fmt.Println("  -yes                Skip the confirmation prompt for -clear")
+	fmt.Println("  -list-projects      List stored projects with ticket counts and exit (JSON with -quiet)")
+	fmt.Println("  -remote             With -list-projects, mark projects that no longer exist in Jira")
+	fmt.Println("                      (needs [jira] credentials)")
+	fmt.Println("  -stats              Summarise the stored tickets and exit (JSON with -quiet)")
+	fmt.Println("\nExit codes:")
+	for _, exit := range exitCodeDescriptions {
+		fmt.Printf("  %d  %s\n", exit.code, exit.description)
+	}
 	fmt.Println("\nExamples:")
 	fmt.Printf("  %s                                  # Run in server mode\n", os.Args[0])
 	fmt.Printf("  %s -mode prod                       # Run server in production mode\n", os.Args[0])
 	fmt.Printf("  %s -config /path/to/config.toml     # Use custom config file\n", os.Args[0])
+	fmt.Printf("  %s -export backup.ndjson            # Export stored data (server must be stopped)\n", os.Args[0])
 	fmt.Println("\nNote: Data collection is performed via the Chrome extension, not the collector binary.")
 }
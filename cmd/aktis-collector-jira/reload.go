@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+
+	"aktis-collector-jira/internal/common"
+	"github.com/ternarybob/arbor"
+)
+
+// reloadConfig re-reads the configuration file on SIGHUP and applies the
+// runtime settings to the live config. The reload is rejected entirely when
+// the new configuration is invalid. Values that need a restart are reported
+// but left unchanged.
+func reloadConfig(cfg *common.Config, configPath string, overrides launchOverrides, logger arbor.ILogger) {
+	next, err := common.LoadConfig(configPath)
+	if err == nil {
+		// Keep the launch environment and command line overrides
+		next.Collector.Environment = cfg.Collector.Environment
+		err = overrides.apply(next)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("config_path", configPath).Msg("Configuration reload rejected")
+		return
+	}
+
+	warnUnknownKeys(next, logger)
+
+	for _, change := range restartRequiredChanges(cfg, next) {
+		logger.Warn().
+			Str("setting", change.setting).
+			Str("current", change.current).
+			Str("configured", change.configured).
+			Msg("Configuration change requires a restart")
+	}
+
+	current := cfg.RuntimeSettings()
+	settings := next.RuntimeSettings()
+	if current.Equal(settings) {
+		logger.Info().Str("config_path", configPath).Msg("Configuration reloaded, no runtime settings changed")
+		return
+	}
+
+	if err := cfg.ApplyRuntimeSettings(settings); err != nil {
+		logger.Error().Err(err).Msg("Configuration reload rejected")
+		return
+	}
+
+	if settings.LogLevel != current.LogLevel {
+		logger.Info().
+			Str("from", current.LogLevel).
+			Str("to", settings.LogLevel).
+			Msg("Reloaded log level")
+	}
+	if !maps.Equal(settings.LogLevels, current.LogLevels) {
+		logger.Info().
+			Str("from", fmt.Sprint(current.LogLevels)).
+			Str("to", fmt.Sprint(settings.LogLevels)).
+			Msg("Reloaded component log levels")
+	}
+	if settings.RetentionDays != current.RetentionDays {
+		logger.Info().
+			Int("from", current.RetentionDays).
+			Int("to", settings.RetentionDays).
+			Msg("Reloaded retention days")
+	}
+	logger.Info().Str("config_path", configPath).Msg("Configuration reloaded")
+}
+
+// settingChange is a configuration value that differs between the live and
+// reloaded config
+type settingChange struct {
+	setting    string
+	current    string
+	configured string
+}
+
+// runtimeSettingPaths are the values reloadConfig applies to the running
+// server, keyed by TOML path. collector.environment is set by -mode.
+var runtimeSettingPaths = map[string]bool{
+	"logging.level":          true,
+	"logging.levels":         true,
+	"storage.retention_days": true,
+	"collector.environment":  true,
+}
+
+// restartRequiredChanges lists the values that differ in the reloaded config
+// but cannot be changed while the server runs. Every setting outside
+// runtimeSettingPaths is compared, so nothing that changed is dropped
+// silently. Secrets, the fields kept out of JSON, are shown as redacted.
+func restartRequiredChanges(cfg, next *common.Config) []settingChange {
+	var changes []settingChange
+	if !reflect.DeepEqual(cfg.Notifications, next.Notifications) {
+		// Webhook URLs often hold secret tokens, so only the count is shown
+		changes = append(changes, settingChange{
+			setting:    "notifications",
+			current:    fmt.Sprintf("%d webhook(s)", len(cfg.Notifications.Webhooks)),
+			configured: fmt.Sprintf("%d webhook(s), changed", len(next.Notifications.Webhooks)),
+		})
+	}
+	compareSettings("", reflect.ValueOf(*cfg), reflect.ValueOf(*next), &changes)
+	return changes
+}
+
+// compareSettings appends the settings under prefix that differ between
+// current and next, walking nested tables by their TOML keys
+func compareSettings(prefix string, current, next reflect.Value, changes *[]settingChange) {
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if !field.IsExported() || key == "" || key == "-" {
+			continue
+		}
+		path := prefix + key
+		if path == "notifications" || runtimeSettingPaths[path] {
+			continue
+		}
+
+		currentValue, nextValue := current.Field(i), next.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			compareSettings(path+".", currentValue, nextValue, changes)
+			continue
+		}
+		if reflect.DeepEqual(currentValue.Interface(), nextValue.Interface()) {
+			continue
+		}
+
+		change := settingChange{
+			setting:    path,
+			current:    fmt.Sprint(currentValue.Interface()),
+			configured: fmt.Sprint(nextValue.Interface()),
+		}
+		if field.Tag.Get("json") == "-" {
+			change.current = redactedSetting(currentValue)
+			change.configured = redactedSetting(nextValue)
+			if !currentValue.IsZero() && !nextValue.IsZero() {
+				change.configured += ", changed"
+			}
+		}
+		*changes = append(*changes, change)
+	}
+}
+
+// redactedSetting describes a secret value without revealing it
+func redactedSetting(value reflect.Value) string {
+	if value.IsZero() {
+		return "(empty)"
+	}
+	return "(redacted)"
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+)
+
+func TestRestartRequiredChanges(t *testing.T) {
+	cfg := common.DefaultConfig()
+	cfg.Collector.APIKey = "old-secret-key"
+
+	next := common.DefaultConfig()
+	next.Collector.APIKey = "new-secret-key"
+	next.Storage.MaxDatabaseMB = 512
+	next.Receiver.AllowedOrigins = []string{"chrome-extension://abc"}
+	// Applied at runtime, so never reported
+	next.Logging.Level = "debug"
+	next.Storage.RetentionDays = cfg.Storage.RetentionDays + 1
+
+	changes := make(map[string]settingChange)
+	for _, change := range restartRequiredChanges(cfg, next) {
+		changes[change.setting] = change
+	}
+
+	apiKey, ok := changes["collector.api_key"]
+	if !ok {
+		t.Fatalf("api_key change not reported: %v", changes)
+	}
+	for _, value := range []string{apiKey.current, apiKey.configured} {
+		if strings.Contains(value, "secret") {
+			t.Errorf("api_key change shows the key: %+v", apiKey)
+		}
+	}
+	if apiKey.configured != "(redacted), changed" {
+		t.Errorf("api_key configured = %q", apiKey.configured)
+	}
+
+	if change := changes["storage.max_database_mb"]; change.current != "0" || change.configured != "512" {
+		t.Errorf("max_database_mb change = %+v", change)
+	}
+	if _, ok := changes["receiver.allowed_origins"]; !ok {
+		t.Errorf("receiver.allowed_origins change not reported: %v", changes)
+	}
+	for _, setting := range []string{"logging.level", "storage.retention_days"} {
+		if _, ok := changes[setting]; ok {
+			t.Errorf("runtime setting %s reported as needing a restart", setting)
+		}
+	}
+	if len(changes) != 3 {
+		t.Errorf("changes = %v, want only api_key, max_database_mb and allowed_origins", changes)
+	}
+
+	if changes := restartRequiredChanges(cfg, cfg); len(changes) != 0 {
+		t.Errorf("unchanged config reported changes: %v", changes)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/services"
+)
+
+// validationCheck is the result of one -validate stage
+type validationCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// runValidation checks the configuration, the database path and the listen
+// ports, printing a result table (JSON when asJSON is set). Unknown config
+// keys fail the check only when strict is set. It reports whether every check
+// passed.
+func runValidation(configPath string, overrides launchOverrides, strict, asJSON bool) bool {
+	var checks []validationCheck
+
+	cfg, err := common.LoadConfig(configPath)
+	if err == nil {
+		err = overrides.apply(cfg)
+	}
+	var problems common.ValidationErrors
+	if errors.As(err, &problems) {
+		for _, problem := range problems {
+			checks = append(checks, validationCheck{Name: "config", Detail: problem})
+		}
+	} else if err != nil {
+		checks = append(checks, validationCheck{Name: "config", Detail: err.Error()})
+	} else {
+		checks = append(checks,
+			validationCheck{Name: "config", Passed: true, Detail: "configuration is valid"},
+			checkUnknownKeys(cfg, strict),
+			checkStorage(cfg),
+			checkPort("port", net.JoinHostPort(cfg.Collector.BindAddress, strconv.Itoa(cfg.Collector.Port))),
+		)
+		if cfg.Collector.TLS.Enabled() && cfg.Collector.TLS.RedirectPort > 0 {
+			checks = append(checks, checkPort("redirect_port",
+				net.JoinHostPort(cfg.Collector.BindAddress, strconv.Itoa(cfg.Collector.TLS.RedirectPort))))
+		}
+	}
+
+	valid := true
+	for _, check := range checks {
+		valid = valid && check.Passed
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(map[string]interface{}{
+			"valid":  valid,
+			"checks": checks,
+		})
+		return valid
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CHECK\tRESULT\tDETAIL")
+	for _, check := range checks {
+		result := "PASS"
+		if !check.Passed {
+			result = "FAIL"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", check.Name, result, check.Detail)
+	}
+	writer.Flush()
+
+	if valid {
+		fmt.Println("Configuration is valid")
+	}
+	return valid
+}
+
+// defaultConfigFile is written by -init-config when no path is given
+const defaultConfigFile = "config.toml"
+
+// runInitConfig writes a commented default configuration to path, refusing to
+// replace an existing file unless force is set
+func runInitConfig(path string, force bool) error {
+	if path == "" {
+		path = defaultConfigFile
+	}
+
+	data, err := common.GenerateConfig()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists - use -force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Printf("Configuration written to %s\n", path)
+	return nil
+}
+
+// checkUnknownKeys reports config file keys that do not match any setting
+func checkUnknownKeys(cfg *common.Config, strict bool) validationCheck {
+	keys := cfg.UnknownKeys()
+	if len(keys) == 0 {
+		return validationCheck{Name: "keys", Passed: true, Detail: "no unknown keys"}
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.String()
+	}
+	return validationCheck{
+		Name:   "keys",
+		Passed: !strict,
+		Detail: fmt.Sprintf("unknown keys ignored: %s", strings.Join(names, ", ")),
+	}
+}
+
+// checkStorage opens an existing database read-only, or checks that a new one
+// could be created, without modifying anything
+func checkStorage(cfg *common.Config) validationCheck {
+	check := validationCheck{Name: "storage"}
+	path := cfg.Storage.DatabasePath
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			check.Detail = fmt.Sprintf("cannot create database directory: %v", err)
+			return check
+		}
+		probe, err := os.CreateTemp(dir, ".validate-*")
+		if err != nil {
+			check.Detail = fmt.Sprintf("database directory is not writable: %v", err)
+			return check
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+
+		check.Passed = true
+		check.Detail = fmt.Sprintf("%s will be created", path)
+		return check
+	}
+
+	storage, err := services.NewReadOnlyStorage(&cfg.Storage)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	storage.Close()
+
+	check.Passed = true
+	check.Detail = fmt.Sprintf("%s opened successfully", path)
+	return check
+}
+
+// checkPort reports whether address can be listened on
+func checkPort(name, address string) validationCheck {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return validationCheck{Name: name, Detail: fmt.Sprintf("%s is not available: %v", address, err)}
+	}
+	listener.Close()
+	return validationCheck{Name: name, Passed: true, Detail: fmt.Sprintf("%s is available", address)}
+}
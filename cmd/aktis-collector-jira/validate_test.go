@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+)
+
+func TestRunInitConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf", "collector.toml")
+
+	if err := runInitConfig(path, false); err != nil {
+		t.Fatalf("runInitConfig: %v", err)
+	}
+	if _, err := common.LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig of the written file: %v", err)
+	}
+
+	// An existing file is only replaced with -force
+	if err := os.WriteFile(path, []byte("# edited by hand\n"), 0600); err != nil {
+		t.Fatalf("editing config: %v", err)
+	}
+	if err := runInitConfig(path, false); err == nil || !strings.Contains(err.Error(), "use -force") {
+		t.Errorf("runInitConfig over an existing file: err = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "# edited by hand\n" {
+		t.Errorf("existing file was changed without -force: %q", data)
+	}
+
+	if err := runInitConfig(path, true); err != nil {
+		t.Fatalf("runInitConfig with force: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "# Aktis Collector - Jira Configuration") {
+		t.Errorf("file not regenerated with force: %.60q", data)
+	}
+}
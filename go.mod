@@ -5,10 +5,12 @@ go 1.25
 require (
 	github.com/gorilla/websocket v1.5.3
 	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/phuslu/log v1.0.118
 	github.com/ternarybob/arbor v1.4.45
 	github.com/ternarybob/banner v0.0.5
 	go.etcd.io/bbolt v1.4.3
 	golang.org/x/net v0.44.0
+	golang.org/x/sys v0.36.0
 )
 
 require (
@@ -24,8 +26,6 @@ require (
 	github.com/gobwas/ws v1.4.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gookit/color v1.5.4 // indirect
-	github.com/phuslu/log v1.0.118 // indirect
 	github.com/playwright-community/playwright-go v0.5200.1 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
-	golang.org/x/sys v0.36.0 // indirect
 )
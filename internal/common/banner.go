@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"strings"
 
+	"aktis-collector-jira/internal/models"
+
 	"github.com/ternarybob/banner"
 )
 
 // PrintBanner displays the application startup banner
-func PrintBanner(serviceName, environment, mode, logFile string) {
+func PrintBanner(serviceName, environment, mode, serverURL, bindAddress, logFile string) {
 	version := GetVersion()
 	build := GetBuild()
 
@@ -41,6 +43,12 @@ func PrintBanner(serviceName, environment, mode, logFile string) {
 	// Print configuration details
 	fmt.Printf("📋 Configuration:\n")
 	fmt.Printf("   • Config File: config.json\n")
+	if serverURL != "" {
+		fmt.Printf("   • Web Interface: %s\n", serverURL)
+	}
+	if bindAddress != "" {
+		fmt.Printf("   • Listen Address: %s\n", bindAddress)
+	}
 
 	// Show log file if provided
 	if logFile != "" {
@@ -64,6 +72,29 @@ func printCollectorInfo() {
 	fmt.Printf("   • Web Interface - Real-time monitoring and control\n")
 }
 
+// PrintSelfCheck displays the startup self-check as one pass/fail line per
+// check
+func PrintSelfCheck(report *models.SelfCheckReport) {
+	if report == nil {
+		return
+	}
+
+	fmt.Printf("🩺 Self-Check:\n")
+	for _, check := range report.Checks {
+		line := fmt.Sprintf("%-10s %s", check.Name, check.Message)
+		fmt.Printf("   ")
+		switch check.Status {
+		case models.SelfCheckPass:
+			PrintSuccess(line)
+		case models.SelfCheckWarn:
+			PrintWarning(line)
+		default:
+			PrintError(line)
+		}
+	}
+	fmt.Printf("\n")
+}
+
 // PrintShutdownBanner displays the application shutdown banner
 func PrintShutdownBanner(serviceName string) {
 	b := banner.New().
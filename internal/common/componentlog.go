@@ -0,0 +1,138 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor"
+)
+
+// LogComponents lists the component names accepted in logging.levels
+var LogComponents = []string{"app", "webserver", "api", "ui", "parser", "receiver", "websocket", "notifier", "storage"}
+
+// logLevels holds the default level and per-component overrides. The writers
+// run at the most verbose of these and componentLogger drops anything below the
+// level of the component that logged it.
+var logLevels = struct {
+	sync.RWMutex
+	base       log.Level
+	components map[string]log.Level
+}{base: log.InfoLevel}
+
+// componentLogger filters events by the level configured for its component.
+// Events from a named component carry a "component" field, and events logged while
+// handling a receiver request carry its "transaction_id".
+type componentLogger struct {
+	arbor.ILogger
+	component     string
+	transactionID string
+}
+
+// WithComponent returns a logger for component whose level follows logging.levels,
+// falling back to logging.level
+func WithComponent(logger arbor.ILogger, component string) arbor.ILogger {
+	if l, ok := logger.(*componentLogger); ok {
+		logger = l.ILogger
+	}
+	return &componentLogger{ILogger: logger, component: component}
+}
+
+// WithTransaction returns a logger that adds transactionID to every event,
+// keeping the component of logger when it has one
+func WithTransaction(logger arbor.ILogger, transactionID string) arbor.ILogger {
+	l, ok := logger.(*componentLogger)
+	if !ok {
+		l = &componentLogger{ILogger: logger}
+	}
+	return &componentLogger{ILogger: l.ILogger, component: l.component, transactionID: transactionID}
+}
+
+type transactionKey struct{}
+
+// ContextWithTransaction returns a copy of ctx carrying transactionID, for
+// services that log on behalf of a receiver request
+func ContextWithTransaction(ctx context.Context, transactionID string) context.Context {
+	return context.WithValue(ctx, transactionKey{}, transactionID)
+}
+
+// TransactionID returns the transaction ID carried by ctx, or ""
+func TransactionID(ctx context.Context) string {
+	id, _ := ctx.Value(transactionKey{}).(string)
+	return id
+}
+
+func (l *componentLogger) enabled(level log.Level) bool {
+	logLevels.RLock()
+	defer logLevels.RUnlock()
+
+	min, ok := logLevels.components[l.component]
+	if !ok {
+		min = logLevels.base
+	}
+	return level >= min
+}
+
+func (l *componentLogger) event(level log.Level, newEvent func() arbor.ILogEvent) arbor.ILogEvent {
+	if !l.enabled(level) {
+		return noopEvent{}
+	}
+	event := newEvent()
+	if l.component != "" {
+		event = event.Str("component", l.component)
+	}
+	if l.transactionID != "" {
+		event = event.Str("transaction_id", l.transactionID)
+	}
+	return event
+}
+
+func (l *componentLogger) Trace() arbor.ILogEvent { return l.event(log.TraceLevel, l.ILogger.Trace) }
+func (l *componentLogger) Debug() arbor.ILogEvent { return l.event(log.DebugLevel, l.ILogger.Debug) }
+func (l *componentLogger) Info() arbor.ILogEvent  { return l.event(log.InfoLevel, l.ILogger.Info) }
+func (l *componentLogger) Warn() arbor.ILogEvent  { return l.event(log.WarnLevel, l.ILogger.Warn) }
+func (l *componentLogger) Error() arbor.ILogEvent { return l.event(log.ErrorLevel, l.ILogger.Error) }
+func (l *componentLogger) Fatal() arbor.ILogEvent { return l.event(log.FatalLevel, l.ILogger.Fatal) }
+func (l *componentLogger) Panic() arbor.ILogEvent { return l.event(log.PanicLevel, l.ILogger.Panic) }
+
+// applyLogLevels sets the default and per-component levels and moves the writers
+// to the most verbose of them
+func applyLogLevels(logger arbor.ILogger, level string, components map[string]string) error {
+	base, err := arbor.ParseLevelString(level)
+	if err != nil {
+		return err
+	}
+
+	parsed := make(map[string]log.Level, len(components))
+	writerLevel := base
+	for component, componentLevel := range components {
+		if parsed[component], err = arbor.ParseLevelString(componentLevel); err != nil {
+			return err
+		}
+		writerLevel = min(writerLevel, parsed[component])
+	}
+
+	logLevels.Lock()
+	logLevels.base = base
+	logLevels.components = parsed
+	logLevels.Unlock()
+
+	logger.WithLevel(arbor.LogLevel(writerLevel))
+	return nil
+}
+
+// noopEvent discards a log event below the component level
+type noopEvent struct{}
+
+func (e noopEvent) Strs(key string, values []string) arbor.ILogEvent    { return e }
+func (e noopEvent) Str(key, value string) arbor.ILogEvent               { return e }
+func (e noopEvent) Err(err error) arbor.ILogEvent                       { return e }
+func (e noopEvent) Msg(message string)                                  {}
+func (e noopEvent) Msgf(format string, args ...interface{})             {}
+func (e noopEvent) Int(key string, value int) arbor.ILogEvent           { return e }
+func (e noopEvent) Int32(key string, value int32) arbor.ILogEvent       { return e }
+func (e noopEvent) Int64(key string, value int64) arbor.ILogEvent       { return e }
+func (e noopEvent) Float32(key string, value float32) arbor.ILogEvent   { return e }
+func (e noopEvent) Dur(key string, value time.Duration) arbor.ILogEvent { return e }
+func (e noopEvent) Float64(key string, value float64) arbor.ILogEvent   { return e }
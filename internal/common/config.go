@@ -1,38 +1,237 @@
 package common
 
 import (
+	"bytes"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aktis-collector-jira/internal/models"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
+// ValidLogLevels lists the accepted logging levels
+var ValidLogLevels = []string{"debug", "info", "warn", "error", "fatal", "panic"}
+
+// ValidLogFormats lists the accepted logging formats
+var ValidLogFormats = []string{"text", "json"}
+
+// envReferenceRegex matches ${VAR} references in string config values
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var hostnameRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
 type Config struct {
-	Collector CollectorConfig `toml:"collector"`
-	Storage   StorageConfig   `toml:"storage"`
-	Logging   LoggingConfig   `toml:"logging"`
+	Collector CollectorConfig `toml:"collector" comment:"HTTP server and extension endpoint settings"`
+	Server    ServerConfig    `toml:"server" comment:"HTTP server limits (0 = no timeout). WebSocket connections are not affected by the write timeout."`
+	Storage   StorageConfig   `toml:"storage" comment:"BBolt database settings"`
+	Logging   LoggingConfig   `toml:"logging" comment:"Log settings; LOG_LEVEL, LOG_FORMAT and LOG_OUTPUT override these"`
+	Receiver  ReceiverConfig  `toml:"receiver" comment:"Extension payloads accepted by /receiver and /assess"`
+	// Notifications is read at startup; changes need a restart
+	Notifications NotificationsConfig `toml:"notifications" comment:"Webhooks posted when collections fail, complete or add many tickets"`
+	Jira          JiraConfig          `toml:"jira" comment:"Jira REST API access, used by -list-projects -remote and /backfill"`
+	Projects      ProjectsConfig      `toml:"projects" comment:"Projects the collector is expected to collect"`
+
+	unknownKeys []UnknownKey
+}
+
+// UnknownKey is a key in the config file that does not match any setting
+type UnknownKey struct {
+	Key    string `json:"key"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (k UnknownKey) String() string {
+	return fmt.Sprintf("%s (line %d)", k.Key, k.Line)
 }
 
 type CollectorConfig struct {
-	Name        string `toml:"name"`
-	Environment string `toml:"environment"`
-	Port        int    `toml:"port"`
+	Name           string       `toml:"name" comment:"Collector name (defaults to the executable name)"`
+	Environment    string       `toml:"environment" comment:"development or production; set by the -mode flag at startup"`
+	Port           int          `toml:"port" comment:"Web interface port; SERVER_PORT overrides"`
+	BindAddress    string       `toml:"bind_address" comment:"Address to listen on (0.0.0.0 = all interfaces)"`
+	APIKey         string       `toml:"api_key" json:"-" comment:"API key required by /receiver and other write endpoints (empty = no key). Prefer api_key_file, \"${VAR}\" or API_KEY over storing it here."`
+	APIKeyFile     string       `toml:"api_key_file" comment:"File to read the API key from, trimmed; cannot be combined with api_key"`
+	AllowedOrigins []string     `toml:"allowed_origins" comment:"Origins allowed to open WebSocket connections (empty = any origin)"`
+	TLS            TLSConfig    `toml:"tls" comment:"HTTPS; set both cert_file and key_file to enable"`
+	EnablePprof    bool         `toml:"enable_pprof" comment:"Serve /debug/pprof in production (always on in development)"`
+	StaticDir      string       `toml:"static_dir" comment:"Directory served at /static/ for dashboard assets and the extension bundle (empty = pages/static)"`
+	ExtensionDir   string       `toml:"extension_dir" comment:"Directory holding extension builds uploaded to /extension/upload and the latest release"`
+	UIAuth         UIAuthConfig `toml:"ui_auth" comment:"Basic authentication for the web UI (empty username = no authentication)"`
+}
+
+type UIAuthConfig struct {
+	Username     string `toml:"username"`
+	Password     string `toml:"password" json:"-" comment:"Prefer password_file or \"${VAR}\" over storing it here"`
+	PasswordFile string `toml:"password_file" comment:"File to read the password from, trimmed; cannot be combined with password"`
+	UseAPIKey    bool   `toml:"use_api_key" comment:"Accept any username with the API key as the UI password"`
+}
+
+type TLSConfig struct {
+	CertFile     string `toml:"cert_file"`
+	KeyFile      string `toml:"key_file"`
+	RedirectPort int    `toml:"redirect_port" comment:"Plain HTTP port redirected to HTTPS (0 = disabled)"`
+}
+
+// Enabled reports whether a certificate and key are configured
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+type ServerConfig struct {
+	ReadHeaderTimeoutSeconds int `toml:"read_header_timeout_seconds"`
+	ReadTimeoutSeconds       int `toml:"read_timeout_seconds"`
+	WriteTimeoutSeconds      int `toml:"write_timeout_seconds"`
+	IdleTimeoutSeconds       int `toml:"idle_timeout_seconds"`
+	MaxHeaderBytes           int `toml:"max_header_bytes"`
+	ShutdownTimeoutSeconds   int `toml:"shutdown_timeout_seconds" comment:"Time allowed on shutdown to close WebSocket clients and finish in-flight requests"`
 }
 
 type StorageConfig struct {
-	DatabasePath  string `toml:"database_path"`
-	BackupDir     string `toml:"backup_dir"`
-	RetentionDays int    `toml:"retention_days"`
+	DatabasePath  string `toml:"database_path" comment:"Database file; DATABASE_PATH overrides"`
+	BackupDir     string `toml:"backup_dir" comment:"Directory for -backup files; BACKUP_DIR overrides"`
+	RetentionDays int    `toml:"retention_days" comment:"Data retention in days (0 = keep forever); can be changed at runtime"`
+	// AssessmentHistory caps the page assessments kept for GET /assessments
+	AssessmentHistory int `toml:"assessment_history" comment:"Most recent page assessments kept for /assessments (0 = none)"`
+	LockWaitSeconds   int `toml:"lock_wait_seconds" comment:"Seconds the server keeps retrying at startup while another process holds the database lock (0 = fail at once)"`
+	// ReadOnly serves an existing database without writing to it, e.g. a
+	// reporting instance over a backup copy
+	ReadOnly bool `toml:"read_only" comment:"Open the database read-only and reject changes with 403"`
+	// StaleAfterHours marks a project stale in /status and /stats when it
+	// has not been collected for this long
+	StaleAfterHours     int  `toml:"stale_after_hours" comment:"Hours since a project's last collection before it is reported stale (0 = never stale)"`
+	StaleDegradesHealth bool `toml:"stale_degrades_health" comment:"Report /health as degraded while any project is stale"`
+	// SaveBatchSize splits large ticket saves into several write
+	// transactions so receiver requests are not held up behind one
+	SaveBatchSize int `toml:"save_batch_size" comment:"Tickets written per transaction when saving a large batch (0 = one transaction)"`
+	// TombstoneHours keeps cleared tickets from being re-added by pages
+	// loaded before the clear
+	TombstoneHours int `toml:"tombstone_hours" comment:"Hours after a clear during which tickets from pages loaded before it are ignored (0 = never ignore)"`
+	// MaxDatabaseMB and MinFreeDiskMB refuse writes before the database or
+	// its disk fills up; AutoPrune frees space as either limit nears
+	MaxDatabaseMB int  `toml:"max_database_mb" comment:"Refuse writes once the database holds this many megabytes of data (0 = no limit)"`
+	MinFreeDiskMB int  `toml:"min_free_disk_mb" comment:"Refuse writes and backups that would leave less free disk space than this many megabytes (0 = no check)"`
+	AutoPrune     bool `toml:"auto_prune" comment:"Near either limit, delete tickets not updated within retention_days and strip stored raw HTML"`
+}
+
+// TombstoneWindow returns TombstoneHours as a duration, zero when disabled
+func (s StorageConfig) TombstoneWindow() time.Duration {
+	return time.Duration(s.TombstoneHours) * time.Hour
+}
+
+// StaleAfter returns StaleAfterHours as a duration, zero when disabled
+func (s StorageConfig) StaleAfter() time.Duration {
+	return time.Duration(s.StaleAfterHours) * time.Hour
+}
+
+// ReceiverConfig controls how extension payloads are accepted by /receiver
+// and /assess. The defaults accept any payload from any origin.
+type ReceiverConfig struct {
+	MaxPayloadBytes        int64    `toml:"max_payload_bytes" comment:"Maximum request body size in bytes (0 = no limit)"`
+	AllowedOrigins         []string `toml:"allowed_origins" comment:"Origins allowed to post, e.g. [\"chrome-extension://<extension-id>\"] (empty = any origin)"`
+	DedupeWindowSeconds    int      `toml:"dedupe_window_seconds" comment:"Ignore an identical page received again within this many seconds of it being stored (0 = store every payload)"`
+	StoreRawHTML           bool     `toml:"store_raw_html" comment:"Keep the page HTML on tickets collected from single-issue pages"`
+	LogNonCollectable      bool     `toml:"log_non_collectable" comment:"Log pages that are not collectable at info level (false = debug level)"`
+	SlowRequestMs          int      `toml:"slow_request_ms" comment:"Log a warning when handling one payload takes longer than this many milliseconds (0 = never)"`
+	ParseTimeoutSeconds    int      `toml:"parse_timeout_seconds" comment:"Stop assessing and parsing one payload after this many seconds and answer 408 (0 = no limit)"`
+	MaxReferenceTickets    int      `toml:"max_reference_tickets" comment:"Most key-only reference tickets stored from one payload (0 = no limit)"`
+	RejectDisabledProjects bool     `toml:"reject_disabled_projects" comment:"Drop received tickets for projects disabled with POST /projects/{key}/disable"`
+	MinExtensionVersion    string   `toml:"min_extension_version" comment:"Reject payloads from extension versions below this, e.g. \"0.1.150\" (empty = accept any version)"`
+}
+
+// NotificationsConfig lists the webhooks collection events are posted to and
+// how failed deliveries are retried
+type NotificationsConfig struct {
+	Webhooks               []WebhookConfig `toml:"webhooks" comment:"One [[notifications.webhooks]] table per URL"`
+	TimeoutSeconds         int             `toml:"timeout_seconds" comment:"Timeout for one delivery attempt"`
+	MaxRetries             int             `toml:"max_retries" comment:"Retries after a failed delivery, with doubling backoff from one second"`
+	BreakerThreshold       int             `toml:"breaker_threshold" comment:"Failed deliveries in a row before a webhook is paused (0 = never pause)"`
+	BreakerCooldownSeconds int             `toml:"breaker_cooldown_seconds" comment:"Seconds a paused webhook is skipped before it is tried again"`
+}
+
+// WebhookConfig is one notification endpoint and the events it receives
+type WebhookConfig struct {
+	URL                   string   `toml:"url" json:"-" comment:"Webhook URL, e.g. \"${SLACK_WEBHOOK_URL}\""`
+	Events                []string `toml:"events" comment:"run_failed, run_completed, tickets_added_threshold and/or storage_full (empty = all)"`
+	TicketsAddedThreshold int      `toml:"tickets_added_threshold" comment:"Send tickets_added_threshold when one collection adds at least this many tickets"`
+}
+
+// ValidNotificationEvents lists the accepted webhook event filters
+var ValidNotificationEvents = []string{models.EventRunFailed, models.EventRunCompleted, models.EventTicketsAddedThreshold, models.EventStorageFull}
+
+// Wants reports whether the webhook receives event
+func (w WebhookConfig) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// JiraConfig is the Jira site and credentials used to query the REST API
+type JiraConfig struct {
+	BaseURL           string        `toml:"base_url" comment:"Jira site, e.g. \"https://your-company.atlassian.net\" (empty = no API access)"`
+	TimeoutSeconds    int           `toml:"timeout_seconds" comment:"Timeout for one Jira API request"`
+	RequestsPerSecond int           `toml:"requests_per_second" comment:"Most Jira API requests per second made by /backfill (0 = no limit)"`
+	API               JiraAPIConfig `toml:"api"`
+}
+
+// JiraAPIConfig holds the Jira API credentials
+type JiraAPIConfig struct {
+	Username     string `toml:"username" comment:"Jira account email"`
+	APIToken     string `toml:"api_token" json:"-" comment:"Jira API token; prefer api_token_file or \"${VAR}\""`
+	APITokenFile string `toml:"api_token_file" comment:"File containing the API token, trimmed; cannot be combined with api_token"`
+}
+
+// HasCredentials reports whether the Jira REST API can be queried
+func (j JiraConfig) HasCredentials() bool {
+	return j.BaseURL != "" && j.API.Username != "" && j.API.APIToken != ""
+}
+
+// ProjectsConfig lists the project keys the collector is expected to collect.
+// -list-projects marks them as configured and other stored projects as
+// discovered.
+type ProjectsConfig struct {
+	Projects []string `toml:"projects" comment:"Project keys, e.g. [\"DEV\", \"PROJ\"]; case is ignored"`
+}
+
+// IsConfigured reports whether key is in the configured project list
+func (p ProjectsConfig) IsConfigured(key string) bool {
+	return slices.ContainsFunc(p.Projects, func(configured string) bool {
+		return strings.EqualFold(configured, key)
+	})
 }
 
 type LoggingConfig struct {
-	Level      string `toml:"level"`
-	Format     string `toml:"format"`
-	Output     string `toml:"output"`
-	MaxSize    int    `toml:"max_size"`
-	MaxBackups int    `toml:"max_backups"`
+	Level      string            `toml:"level" comment:"debug, info, warn, error, fatal or panic; can be changed at runtime"`
+	Levels     map[string]string `toml:"levels" comment:"Per-component levels overriding level, e.g. { parser = \"debug\" }. Components: app, webserver, api, ui, parser, receiver, websocket, notifier, storage. Can be changed at runtime."`
+	Format     string            `toml:"format" comment:"text or json"`
+	Output     string            `toml:"output" comment:"console, file or both"`
+	MaxSize    int               `toml:"max_size" comment:"Log file size in MB before rotation"`
+	MaxBackups int               `toml:"max_backups" comment:"Rotated log files to keep"`
+}
+
+// GenerateConfig renders the default configuration as commented TOML. Secrets
+// are left empty.
+func GenerateConfig() ([]byte, error) {
+	data, err := toml.Marshal(DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render configuration: %w", err)
+	}
+
+	header := "# Aktis Collector - Jira Configuration\n" +
+		"# Generated with -init-config. Values shown are the defaults.\n" +
+		"# String values may reference environment variables as \"${VAR}\".\n\n"
+	return append([]byte(header), data...), nil
 }
 
 func DefaultConfig() *Config {
@@ -42,17 +241,34 @@ func DefaultConfig() *Config {
 	execName = execName[:len(execName)-len(filepath.Ext(execName))]
 
 	defaultDBPath := filepath.Join(execDir, "data", execName+".db")
+	defaultExtensionDir := filepath.Join(execDir, "data", "extension")
 
 	return &Config{
 		Collector: CollectorConfig{
-			Name:        execName,
-			Environment: "development",
-			Port:        8080,
+			Name:         execName,
+			Environment:  "development",
+			Port:         8080,
+			BindAddress:  "0.0.0.0",
+			ExtensionDir: defaultExtensionDir,
+		},
+		Server: ServerConfig{
+			ReadHeaderTimeoutSeconds: 10,
+			ReadTimeoutSeconds:       60,
+			WriteTimeoutSeconds:      60,
+			IdleTimeoutSeconds:       120,
+			MaxHeaderBytes:           1 << 20,
+			ShutdownTimeoutSeconds:   30,
 		},
 		Storage: StorageConfig{
-			DatabasePath:  defaultDBPath,
-			BackupDir:     "./backups",
-			RetentionDays: 90,
+			DatabasePath:      defaultDBPath,
+			BackupDir:         "./backups",
+			RetentionDays:     90,
+			AssessmentHistory: 1000,
+			LockWaitSeconds:   30,
+			SaveBatchSize:     1000,
+			TombstoneHours:    24,
+			MinFreeDiskMB:     100,
+			StaleAfterHours:   168,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -61,6 +277,22 @@ func DefaultConfig() *Config {
 			MaxSize:    100,
 			MaxBackups: 3,
 		},
+		Receiver: ReceiverConfig{
+			LogNonCollectable:   true,
+			SlowRequestMs:       5000,
+			ParseTimeoutSeconds: 10,
+			MaxReferenceTickets: 50,
+		},
+		Notifications: NotificationsConfig{
+			TimeoutSeconds:         10,
+			MaxRetries:             3,
+			BreakerThreshold:       5,
+			BreakerCooldownSeconds: 300,
+		},
+		Jira: JiraConfig{
+			TimeoutSeconds:    30,
+			RequestsPerSecond: 5,
+		},
 	}
 }
 
@@ -97,8 +329,23 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
 	}
 
-	if err := toml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		// Unknown keys are reported as warnings; the rest of the file is decoded
+		var strictErr *toml.StrictMissingError
+		if !errors.As(err, &strictErr) {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		config.unknownKeys = unknownKeys(strictErr, config.Projects.Projects)
+	}
+
+	// Precedence: file values < ${VAR} expansion and secret files < env overrides
+	if err := expandEnvReferences(reflect.ValueOf(config).Elem(), ""); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := loadSecretFiles(config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	applyEnvOverrides(config)
@@ -110,6 +357,101 @@ func LoadConfig(configFile string) (*Config, error) {
 	return config, nil
 }
 
+// unknownKeys lists the keys rejected by strict decoding, skipping the legacy
+// per-project tables named in the projects list
+func unknownKeys(strictErr *toml.StrictMissingError, projects []string) []UnknownKey {
+	var keys []UnknownKey
+	for _, decodeErr := range strictErr.Errors {
+		key := decodeErr.Key()
+		if len(key) > 0 && slices.Contains(projects, key[0]) {
+			continue
+		}
+		line, column := decodeErr.Position()
+		keys = append(keys, UnknownKey{Key: strings.Join(key, "."), Line: line, Column: column})
+	}
+	return keys
+}
+
+// UnknownKeys returns the config file keys that were ignored because they do
+// not match any setting
+func (c *Config) UnknownKeys() []UnknownKey {
+	return c.unknownKeys
+}
+
+// expandEnvReferences replaces ${VAR} references in all string and string
+// slice values, failing when a referenced variable is not set
+func expandEnvReferences(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("toml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := expandEnvReferences(v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandEnvReferences(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		var missing string
+		expanded := envReferenceRegex.ReplaceAllStringFunc(v.String(), func(reference string) string {
+			name := envReferenceRegex.FindStringSubmatch(reference)[1]
+			value, ok := os.LookupEnv(name)
+			if !ok && missing == "" {
+				missing = name
+			}
+			return value
+		})
+		if missing != "" {
+			return fmt.Errorf("%s references environment variable %s, which is not set", path, missing)
+		}
+		v.SetString(expanded)
+	}
+	return nil
+}
+
+// loadSecretFiles reads secrets configured as file paths. The file contents
+// are trimmed of surrounding whitespace.
+func loadSecretFiles(config *Config) error {
+	secrets := []struct {
+		name   string
+		file   string
+		target *string
+	}{
+		{"collector.api_key", config.Collector.APIKeyFile, &config.Collector.APIKey},
+		{"collector.ui_auth.password", config.Collector.UIAuth.PasswordFile, &config.Collector.UIAuth.Password},
+		{"jira.api.api_token", config.Jira.API.APITokenFile, &config.Jira.API.APIToken},
+	}
+
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		if *secret.target != "" {
+			return fmt.Errorf("set either %s or %s_file, not both", secret.name, secret.name)
+		}
+		data, err := os.ReadFile(secret.file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s_file: %w", secret.name, err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return fmt.Errorf("%s_file %s is empty", secret.name, secret.file)
+		}
+		*secret.target = value
+	}
+	return nil
+}
+
 func applyEnvOverrides(config *Config) {
 	if dbPath := os.Getenv("DATABASE_PATH"); dbPath != "" {
 		config.Storage.DatabasePath = dbPath
@@ -128,6 +470,10 @@ func applyEnvOverrides(config *Config) {
 		config.Logging.Output = logOutput
 	}
 
+	if apiKey := os.Getenv("API_KEY"); apiKey != "" {
+		config.Collector.APIKey = apiKey
+	}
+
 	if port := os.Getenv("SERVER_PORT"); port != "" {
 		if portNum, err := strconv.Atoi(port); err == nil {
 			config.Collector.Port = portNum
@@ -135,42 +481,345 @@ func applyEnvOverrides(config *Config) {
 	}
 }
 
+// ValidationErrors lists every problem found by Validate, each prefixed with
+// the TOML path of the offending setting
+type ValidationErrors []string
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0]
+	}
+	return fmt.Sprintf("%d problems: %s", len(e), strings.Join(e, "; "))
+}
+
+// Validate applies defaults for unset values and reports all invalid
+// settings together as ValidationErrors
 func (c *Config) Validate() error {
+	var problems ValidationErrors
+	add := func(path, format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
 	if c.Storage.DatabasePath == "" {
-		return fmt.Errorf("storage database_path is required")
+		add("storage.database_path", "is required")
+	}
+	if c.Collector.ExtensionDir == "" {
+		add("collector.extension_dir", "is required")
 	}
 
 	if c.Collector.Port <= 0 {
 		c.Collector.Port = 8080
 	}
+	if c.Collector.Port > 65535 {
+		add("collector.port", "invalid port %d (must be 1-65535)", c.Collector.Port)
+	}
+
+	if c.Collector.BindAddress == "" {
+		c.Collector.BindAddress = "0.0.0.0"
+	}
+	if net.ParseIP(c.Collector.BindAddress) == nil && !hostnameRegex.MatchString(c.Collector.BindAddress) {
+		add("collector.bind_address", "invalid address %s (must be an IP address or hostname)", c.Collector.BindAddress)
+	}
 
-	validLogLevels := []string{"debug", "info", "warn", "error", "fatal", "panic"}
-	validLevel := false
-	for _, level := range validLogLevels {
-		if c.Logging.Level == level {
-			validLevel = true
-			break
+	if !IsValidLogLevel(c.Logging.Level) {
+		add("logging.level", "invalid level %s (must be one of: %s)", c.Logging.Level, strings.Join(ValidLogLevels, ", "))
+	}
+	if problem := validateComponentLevels(c.Logging.Levels); problem != "" {
+		add("logging.levels", "%s", problem)
+	}
+
+	if c.Storage.RetentionDays < 0 {
+		add("storage.retention_days", "must not be negative, got %d", c.Storage.RetentionDays)
+	}
+	if c.Storage.LockWaitSeconds < 0 {
+		add("storage.lock_wait_seconds", "must not be negative, got %d", c.Storage.LockWaitSeconds)
+	}
+	if c.Storage.TombstoneHours < 0 {
+		add("storage.tombstone_hours", "must not be negative, got %d", c.Storage.TombstoneHours)
+	}
+	if c.Storage.MaxDatabaseMB < 0 {
+		add("storage.max_database_mb", "must not be negative, got %d", c.Storage.MaxDatabaseMB)
+	}
+	if c.Storage.MinFreeDiskMB < 0 {
+		add("storage.min_free_disk_mb", "must not be negative, got %d", c.Storage.MinFreeDiskMB)
+	}
+	if c.Storage.SaveBatchSize < 0 {
+		add("storage.save_batch_size", "must not be negative, got %d", c.Storage.SaveBatchSize)
+	}
+	if c.Storage.AssessmentHistory < 0 {
+		add("storage.assessment_history", "must not be negative, got %d", c.Storage.AssessmentHistory)
+	}
+	if c.Storage.StaleAfterHours < 0 {
+		add("storage.stale_after_hours", "must not be negative, got %d", c.Storage.StaleAfterHours)
+	}
+
+	timeouts := []struct {
+		name  string
+		value int
+	}{
+		{"read_header_timeout_seconds", c.Server.ReadHeaderTimeoutSeconds},
+		{"read_timeout_seconds", c.Server.ReadTimeoutSeconds},
+		{"write_timeout_seconds", c.Server.WriteTimeoutSeconds},
+		{"idle_timeout_seconds", c.Server.IdleTimeoutSeconds},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			add("server."+timeout.name, "must not be negative, got %d", timeout.value)
+		}
+	}
+	if c.Server.MaxHeaderBytes < 0 {
+		add("server.max_header_bytes", "must not be negative, got %d", c.Server.MaxHeaderBytes)
+	}
+	if c.Receiver.MaxPayloadBytes < 0 {
+		add("receiver.max_payload_bytes", "must not be negative, got %d", c.Receiver.MaxPayloadBytes)
+	}
+	if c.Receiver.DedupeWindowSeconds < 0 {
+		add("receiver.dedupe_window_seconds", "must not be negative, got %d", c.Receiver.DedupeWindowSeconds)
+	}
+	if c.Receiver.SlowRequestMs < 0 {
+		add("receiver.slow_request_ms", "must not be negative, got %d", c.Receiver.SlowRequestMs)
+	}
+	if c.Receiver.ParseTimeoutSeconds < 0 {
+		add("receiver.parse_timeout_seconds", "must not be negative, got %d", c.Receiver.ParseTimeoutSeconds)
+	}
+	if c.Receiver.MaxReferenceTickets < 0 {
+		add("receiver.max_reference_tickets", "must not be negative, got %d", c.Receiver.MaxReferenceTickets)
+	}
+	if v := c.Receiver.MinExtensionVersion; v != "" {
+		if err := ValidateExtensionVersion(v); err != nil {
+			add("receiver.min_extension_version", "%v", err)
+		}
+	}
+
+	notifications := c.Notifications
+	if notifications.TimeoutSeconds <= 0 {
+		add("notifications.timeout_seconds", "must be positive, got %d", notifications.TimeoutSeconds)
+	}
+	if notifications.MaxRetries < 0 {
+		add("notifications.max_retries", "must not be negative, got %d", notifications.MaxRetries)
+	}
+	if notifications.BreakerThreshold < 0 {
+		add("notifications.breaker_threshold", "must not be negative, got %d", notifications.BreakerThreshold)
+	}
+	if notifications.BreakerCooldownSeconds < 0 {
+		add("notifications.breaker_cooldown_seconds", "must not be negative, got %d", notifications.BreakerCooldownSeconds)
+	}
+	for i, webhook := range notifications.Webhooks {
+		setting := fmt.Sprintf("notifications.webhooks[%d]", i)
+		if !IsAbsoluteURL(webhook.URL) {
+			add(setting+".url", "must be an absolute http or https URL")
+		}
+		for _, event := range webhook.Events {
+			if !slices.Contains(ValidNotificationEvents, event) {
+				add(setting+".events", "unknown event %q (must be one of %s)", event, strings.Join(ValidNotificationEvents, ", "))
+			}
 		}
+		if webhook.TicketsAddedThreshold < 0 {
+			add(setting+".tickets_added_threshold", "must not be negative, got %d", webhook.TicketsAddedThreshold)
+		}
+		if webhook.Wants(models.EventTicketsAddedThreshold) && len(webhook.Events) > 0 && webhook.TicketsAddedThreshold == 0 {
+			add(setting+".tickets_added_threshold", "must be set to receive tickets_added_threshold events")
+		}
+	}
+
+	if c.Server.ShutdownTimeoutSeconds <= 0 {
+		c.Server.ShutdownTimeoutSeconds = 30
+	}
+
+	if c.Jira.BaseURL != "" && !IsAbsoluteURL(c.Jira.BaseURL) {
+		add("jira.base_url", "must be an absolute http or https URL")
 	}
-	if !validLevel {
-		return fmt.Errorf("invalid log level: %s", c.Logging.Level)
+	if c.Jira.TimeoutSeconds <= 0 {
+		add("jira.timeout_seconds", "must be positive, got %d", c.Jira.TimeoutSeconds)
+	}
+	if c.Jira.RequestsPerSecond < 0 {
+		add("jira.requests_per_second", "must not be negative, got %d", c.Jira.RequestsPerSecond)
+	}
+	if (c.Jira.API.Username == "") != (c.Jira.API.APIToken == "") {
+		add("jira.api", "requires both username and api_token (api_token may come from api_token_file)")
+	}
+
+	ui := c.Collector.UIAuth
+	if (ui.Username == "") != (ui.Password == "") {
+		add("collector.ui_auth", "requires both username and password (password may come from password_file)")
+	}
+	if ui.UseAPIKey && c.Collector.APIKey == "" {
+		add("collector.ui_auth.use_api_key", "requires collector api_key (from api_key, api_key_file or the API_KEY environment variable, which takes precedence)")
+	}
+
+	c.validateTLS(add)
+
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+	}
+	if !slices.Contains(ValidLogFormats, c.Logging.Format) {
+		add("logging.format", "invalid format %s (must be one of: %s)", c.Logging.Format, strings.Join(ValidLogFormats, ", "))
 	}
 
 	validOutputs := []string{"console", "file", "both"}
-	validOutput := false
-	for _, output := range validOutputs {
-		if c.Logging.Output == output {
-			validOutput = true
-			break
+	if !slices.Contains(validOutputs, c.Logging.Output) {
+		add("logging.output", "invalid output %s (must be one of: %s)", c.Logging.Output, strings.Join(validOutputs, ", "))
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func (c *Config) validateTLS(add func(path, format string, args ...interface{})) {
+	t := c.Collector.TLS
+	if t.CertFile == "" && t.KeyFile == "" {
+		if t.RedirectPort != 0 {
+			add("collector.tls.redirect_port", "requires cert_file and key_file")
+		}
+		return
+	}
+
+	if t.CertFile == "" || t.KeyFile == "" {
+		add("collector.tls", "requires both cert_file and key_file")
+		return
+	}
+
+	if _, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile); err != nil {
+		add("collector.tls", "invalid certificate/key pair (cert_file=%s, key_file=%s): %v", t.CertFile, t.KeyFile, err)
+	}
+
+	if t.RedirectPort < 0 || t.RedirectPort > 65535 {
+		add("collector.tls.redirect_port", "invalid port %d (must be 1-65535)", t.RedirectPort)
+	} else if t.RedirectPort == c.Collector.Port {
+		add("collector.tls.redirect_port", "must differ from port %d", c.Collector.Port)
+	}
+}
+
+// validateComponentLevels checks logging.levels component names and levels,
+// returning a description of the first problem
+func validateComponentLevels(levels map[string]string) string {
+	for _, component := range slices.Sorted(maps.Keys(levels)) {
+		if !slices.Contains(LogComponents, component) {
+			return fmt.Sprintf("unknown component %s (must be one of: %s)", component, strings.Join(LogComponents, ", "))
+		}
+		if !IsValidLogLevel(levels[component]) {
+			return fmt.Sprintf("invalid level %s for %s (must be one of: %s)", levels[component], component, strings.Join(ValidLogLevels, ", "))
 		}
 	}
-	if !validOutput {
-		return fmt.Errorf("invalid log output: %s", c.Logging.Output)
+	return ""
+}
+
+// IsValidLogLevel reports whether level is one of ValidLogLevels
+func IsValidLogLevel(level string) bool {
+	return slices.Contains(ValidLogLevels, level)
+}
+
+// runtimeMu guards the configuration values that can change while the server runs
+var runtimeMu sync.RWMutex
+
+// RuntimeSettings are the configuration values that can be changed without a restart
+type RuntimeSettings struct {
+	LogLevel      string            `json:"log_level"`
+	LogLevels     map[string]string `json:"log_levels"`
+	RetentionDays int               `json:"retention_days"`
+}
+
+// Equal reports whether s and other hold the same values
+func (s RuntimeSettings) Equal(other RuntimeSettings) bool {
+	return s.LogLevel == other.LogLevel &&
+		maps.Equal(s.LogLevels, other.LogLevels) &&
+		s.RetentionDays == other.RetentionDays
+}
+
+// Validate returns field-level errors keyed by JSON name
+func (s RuntimeSettings) Validate() map[string]string {
+	errors := make(map[string]string)
+	if !IsValidLogLevel(s.LogLevel) {
+		errors["log_level"] = fmt.Sprintf("must be one of: %s", strings.Join(ValidLogLevels, ", "))
+	}
+	if problem := validateComponentLevels(s.LogLevels); problem != "" {
+		errors["log_levels"] = problem
 	}
+	if s.RetentionDays < 0 {
+		errors["retention_days"] = "must not be negative"
+	}
+	return errors
+}
+
+// RuntimeSettings returns the current runtime-adjustable values
+func (c *Config) RuntimeSettings() RuntimeSettings {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	return RuntimeSettings{
+		LogLevel:      c.Logging.Level,
+		LogLevels:     maps.Clone(c.Logging.Levels),
+		RetentionDays: c.Storage.RetentionDays,
+	}
+}
+
+// RuntimeSnapshot returns copies of the storage and logging sections taken
+// under the runtime lock, for readers that show the whole section while
+// runtime settings may be changing
+func (c *Config) RuntimeSnapshot() (StorageConfig, LoggingConfig) {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	logging := c.Logging
+	logging.Levels = maps.Clone(c.Logging.Levels)
+	return c.Storage, logging
+}
+
+// CurrentRetentionDays returns storage.retention_days. It can be changed at
+// runtime, so background work reads it through this rather than the field.
+func (c *StorageConfig) CurrentRetentionDays() int {
+	runtimeMu.RLock()
+	defer runtimeMu.RUnlock()
+	return c.RetentionDays
+}
+
+// ApplyRuntimeSettings validates and applies new runtime values. Changes are
+// not written back to the config file and last until the next restart.
+func (c *Config) ApplyRuntimeSettings(settings RuntimeSettings) error {
+	if errors := settings.Validate(); len(errors) > 0 {
+		return fmt.Errorf("invalid runtime settings: %v", errors)
+	}
+
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
 
+	if settings.LogLevel != c.Logging.Level || !maps.Equal(settings.LogLevels, c.Logging.Levels) {
+		if err := SetLogLevels(settings.LogLevel, settings.LogLevels); err != nil {
+			return err
+		}
+		c.Logging.Level = settings.LogLevel
+		c.Logging.Levels = maps.Clone(settings.LogLevels)
+	}
+	c.Storage.RetentionDays = settings.RetentionDays
 	return nil
 }
 
+// IsDevelopment reports whether the collector runs in the development environment
+func (c *Config) IsDevelopment() bool {
+	return c.Collector.Environment == "development"
+}
+
+// ListenAddress returns the host:port the web server binds to
+func (c *Config) ListenAddress() string {
+	return net.JoinHostPort(c.Collector.BindAddress, strconv.Itoa(c.Collector.Port))
+}
+
+// ServerURL returns the base URL of the web server, using localhost when
+// bound to all interfaces
+func (c *Config) ServerURL() string {
+	scheme := "http"
+	if c.Collector.TLS.Enabled() {
+		scheme = "https"
+	}
+
+	host := c.Collector.BindAddress
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(c.Collector.Port)))
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Logging.Level == "warn" || c.Logging.Level == "error" || c.Logging.Level == "fatal"
 }
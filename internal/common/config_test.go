@@ -0,0 +1,240 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file in a temporary directory and
+// returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigUnknownKeys(t *testing.T) {
+	path := writeConfig(t, `colector_name = "typo at the top level"
+
+[collector]
+prot = 9090
+
+[collector.tls]
+cert_fil = "server.crt"
+
+[storage]
+retention_days = 30
+retention_dayz = 7
+
+[[notifications.webhooks]]
+url = "https://hooks.example.com/collector"
+evnets = ["run_failed"]
+
+[projects]
+projects = ["ABC"]
+
+# A legacy per-project table named in the projects list is not a typo
+[ABC]
+send_limit = 5
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	want := []UnknownKey{
+		{Key: "colector_name", Line: 1},
+		{Key: "collector.prot", Line: 4},
+		{Key: "collector.tls.cert_fil", Line: 7},
+		{Key: "storage.retention_dayz", Line: 11},
+		{Key: "notifications.webhooks.evnets", Line: 15},
+	}
+	got := config.UnknownKeys()
+	if len(got) != len(want) {
+		t.Fatalf("unknown keys = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Key != want[i].Key || got[i].Line != want[i].Line {
+			t.Errorf("unknown key %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	// The rest of the file is still applied
+	if config.Storage.RetentionDays != 30 || config.Collector.Port != DefaultConfig().Collector.Port {
+		t.Errorf("retention_days = %d, port = %d", config.Storage.RetentionDays, config.Collector.Port)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, `[collector]
+port = 70000
+
+[logging]
+level = "loud"
+
+[storage]
+retention_days = -1
+
+[server]
+read_timeout_seconds = -5
+
+[jira]
+base_url = "jira.example.com"
+`)
+
+	_, err := LoadConfig(path)
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("LoadConfig error = %v, want ValidationErrors", err)
+	}
+
+	prefixes := []string{
+		"collector.port: ",
+		"logging.level: ",
+		"storage.retention_days: ",
+		"server.read_timeout_seconds: ",
+		"jira.base_url: ",
+	}
+	if len(problems) != len(prefixes) {
+		t.Fatalf("problems = %q, want %d", problems, len(prefixes))
+	}
+	for i, prefix := range prefixes {
+		if !strings.HasPrefix(problems[i], prefix) {
+			t.Errorf("problem %d = %q, want prefix %q", i, problems[i], prefix)
+		}
+	}
+	if !strings.Contains(err.Error(), "5 problems: collector.port: ") {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestGenerateConfigRoundTrip(t *testing.T) {
+	data, err := GenerateConfig()
+	if err != nil {
+		t.Fatalf("GenerateConfig: %v", err)
+	}
+	path := writeConfig(t, string(data))
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig of the generated file: %v", err)
+	}
+	if keys := config.UnknownKeys(); len(keys) != 0 {
+		t.Errorf("generated file has unknown keys: %v", keys)
+	}
+	// Unset lists are written as [] and load back empty rather than nil
+	want := DefaultConfig()
+	for _, list := range []*[]string{&want.Collector.AllowedOrigins, &want.Receiver.AllowedOrigins, &want.Projects.Projects} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if want.Notifications.Webhooks == nil {
+		want.Notifications.Webhooks = []WebhookConfig{}
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("loaded config differs from the defaults:\n%+v\nwant\n%+v", config, want)
+	}
+
+	// Every section is present and explained
+	for _, section := range []string{"collector", "server", "storage", "logging", "receiver", "notifications", "jira", "projects"} {
+		if !strings.Contains(string(data), "\n["+section+"]\n") {
+			t.Errorf("generated file has no [%s] section", section)
+		}
+	}
+	if !strings.Contains(string(data), "# Jira API token; prefer api_token_file") {
+		t.Error("generated file is missing field comments")
+	}
+}
+
+func TestLoadConfigExpandsEnvReferences(t *testing.T) {
+	t.Setenv("TEST_JIRA_HOST", "jira.example.com")
+	t.Setenv("TEST_JIRA_USER", "me@example.com")
+	t.Setenv("TEST_WEBHOOK", "https://hooks.example.com/abc")
+	tokenFile := filepath.Join(t.TempDir(), "jira-token")
+	if err := os.WriteFile(tokenFile, []byte("  s3cret\n"), 0600); err != nil {
+		t.Fatalf("writing token file: %v", err)
+	}
+
+	config, err := LoadConfig(writeConfig(t, `[jira]
+base_url = "https://${TEST_JIRA_HOST}"
+
+[jira.api]
+username = "${TEST_JIRA_USER}"
+api_token_file = "`+filepath.ToSlash(tokenFile)+`"
+
+[[notifications.webhooks]]
+url = "${TEST_WEBHOOK}"
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.Jira.BaseURL != "https://jira.example.com" || config.Jira.API.Username != "me@example.com" {
+		t.Errorf("jira = %+v", config.Jira)
+	}
+	if config.Jira.API.APIToken != "s3cret" {
+		t.Errorf("api_token from file = %q, want it trimmed", config.Jira.API.APIToken)
+	}
+	if len(config.Notifications.Webhooks) != 1 || config.Notifications.Webhooks[0].URL != "https://hooks.example.com/abc" {
+		t.Errorf("webhooks = %+v", config.Notifications.Webhooks)
+	}
+}
+
+func TestLoadConfigSecretErrors(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "jira-token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret"), 0600); err != nil {
+		t.Fatalf("writing token file: %v", err)
+	}
+	tokenPath := filepath.ToSlash(tokenFile)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unset variable",
+			content: "[jira.api]\nusername = \"${TEST_UNSET_VARIABLE}\"\n",
+			want:    "jira.api.username references environment variable TEST_UNSET_VARIABLE, which is not set",
+		},
+		{
+			name:    "missing file",
+			content: "[jira.api]\napi_token_file = \"" + tokenPath + ".missing\"\n",
+			want:    "failed to read jira.api.api_token_file",
+		},
+		{
+			name:    "value and file",
+			content: "[jira.api]\napi_token = \"inline\"\napi_token_file = \"" + tokenPath + "\"\n",
+			want:    "set either jira.api.api_token or jira.api.api_token_file, not both",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadConfig(writeConfig(t, tt.content)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigEnvOverridePrecedence(t *testing.T) {
+	// API_KEY wins over a ${VAR} reference in the file
+	t.Setenv("TEST_COLLECTOR_KEY", "from-reference")
+	t.Setenv("API_KEY", "from-override")
+
+	config, err := LoadConfig(writeConfig(t, "[collector]\napi_key = \"${TEST_COLLECTOR_KEY}\"\n"))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.Collector.APIKey != "from-override" {
+		t.Errorf("api_key = %q, want the API_KEY override", config.Collector.APIKey)
+	}
+}
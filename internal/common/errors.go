@@ -1,10 +1,15 @@
 package common
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
 
+// ErrStorageFull is the cause of storage errors for writes refused because
+// the database or its disk reached a storage limit
+var ErrStorageFull = errors.New("storage limit reached")
+
 // ErrorType represents the type of error
 type ErrorType string
 
@@ -21,6 +26,8 @@ const (
 	ErrorTypeNetwork ErrorType = "network"
 	// ErrorTypeAuth for authentication/authorization errors
 	ErrorTypeAuth ErrorType = "auth"
+	// ErrorTypeNotFound for missing resources
+	ErrorTypeNotFound ErrorType = "not_found"
 	// ErrorTypeJira for Jira-specific errors
 	ErrorTypeJira ErrorType = "jira"
 	// ErrorTypeCollection for data collection errors
@@ -108,6 +115,11 @@ func NewAuthError(code, message string) *CollectorError {
 	return NewError(ErrorTypeAuth, code, message)
 }
 
+// NewNotFoundError creates a not found error
+func NewNotFoundError(code, message string) *CollectorError {
+	return NewError(ErrorTypeNotFound, code, message)
+}
+
 // NewJiraError creates a Jira-specific error
 func NewJiraError(code, message string) *CollectorError {
 	return NewError(ErrorTypeJira, code, message)
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	"aktis-collector-jira/internal/models"
+)
+
+// TicketHashVersion identifies the fields and encoding used by TicketHash.
+// Bump it whenever either changes so stored hashes are recomputed rather
+// than compared.
+const TicketHashVersion = 1
+
+// TicketHash returns a hex SHA-256 over a ticket's content: key, summary,
+// description, type, status, priority, reporter, assignee, labels,
+// components, comments, subtasks, links and attachments. URLs, timestamps,
+// raw HTML, the source and custom fields are left out, and lists are
+// sorted, so the same content hashes the same whichever path collected it.
+func TicketHash(t *models.TicketData) string {
+	h := sha256.New()
+
+	writeHashFields(h, t.Key, t.Summary, t.Description, t.IssueType, t.Status, t.Priority, t.Reporter, t.Assignee)
+	writeHashList(h, t.Labels)
+	writeHashList(h, t.Components)
+
+	comments := make([]string, 0, len(t.Comments))
+	for _, c := range t.Comments {
+		comments = append(comments, hashRecord(c.ID, c.Author, c.Body))
+	}
+	writeHashList(h, comments)
+
+	subtasks := make([]string, 0, len(t.Subtasks))
+	for _, s := range t.Subtasks {
+		subtasks = append(subtasks, hashRecord(s.Key, s.Summary, s.Status, s.IssueType))
+	}
+	writeHashList(h, subtasks)
+
+	links := make([]string, 0, len(t.Links))
+	for _, l := range t.Links {
+		links = append(links, hashRecord(l.LinkType, l.Direction, l.IssueKey))
+	}
+	writeHashList(h, links)
+
+	attachments := make([]string, 0, len(t.Attachments))
+	for _, a := range t.Attachments {
+		attachments = append(attachments, hashRecord(a.ID, a.Filename))
+	}
+	writeHashList(h, attachments)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeHashFields writes each value length-prefixed so adjacent fields
+// cannot run together
+func writeHashFields(h hash.Hash, values ...string) {
+	var length [8]byte
+	for _, value := range values {
+		binary.BigEndian.PutUint64(length[:], uint64(len(value)))
+		h.Write(length[:])
+		h.Write([]byte(value))
+	}
+}
+
+// writeHashList writes the count and then the values in sorted order
+func writeHashList(h hash.Hash, values []string) {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+
+	var count [8]byte
+	binary.BigEndian.PutUint64(count[:], uint64(len(sorted)))
+	h.Write(count[:])
+	writeHashFields(h, sorted...)
+}
+
+// hashRecord encodes a nested record's fields as one list value
+func hashRecord(values ...string) string {
+	h := sha256.New()
+	writeHashFields(h, values...)
+	return string(h.Sum(nil))
+}
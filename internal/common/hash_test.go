@@ -0,0 +1,140 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"aktis-collector-jira/internal/models"
+)
+
+// goldenTicket is hashed to goldenTicketHash by TicketHashVersion 1
+func goldenTicket() *models.TicketData {
+	return &models.TicketData{
+		Key:         "ABC-7",
+		Summary:     "Fix login",
+		Description: "Login is broken after the upgrade",
+		IssueType:   "Bug",
+		Status:      "Open",
+		Priority:    "High",
+		Reporter:    "bob",
+		Assignee:    "alice",
+		Labels:      []string{"backend", "login"},
+		Components:  []string{"Auth"},
+		Comments:    []models.Comment{{ID: "c1", Author: "bob", Body: "Seen in production"}},
+		Subtasks:    []models.Subtask{{Key: "ABC-8", Summary: "Write test", Status: "Open", IssueType: "Sub-task"}},
+		Links:       []models.IssueLink{{LinkType: "blocks", Direction: "outward", IssueKey: "ABC-9"}},
+		Attachments: []models.Attachment{{ID: "a1", Filename: "log.txt"}},
+	}
+}
+
+const goldenTicketHash = "86747dd4d0d69ecc804feaef1d40759539b238d81829b725c7aef3ccd273cbee"
+
+func TestTicketHashGolden(t *testing.T) {
+	if TicketHashVersion != 1 {
+		t.Fatalf("TicketHashVersion is %d; add a golden hash for the new version", TicketHashVersion)
+	}
+	if got := TicketHash(goldenTicket()); got != goldenTicketHash {
+		t.Errorf("TicketHash = %s, want %s; if the encoding changed on purpose, bump TicketHashVersion", got, goldenTicketHash)
+	}
+}
+
+// TestTicketHashEncoding rebuilds the version 1 byte stream by hand: the
+// eight text fields each prefixed with their length as a big-endian uint64,
+// then labels, components, comments, subtasks, links and attachments, each
+// a count followed by its length-prefixed values
+func TestTicketHashEncoding(t *testing.T) {
+	var stream []byte
+	field := func(value string) {
+		stream = binary.BigEndian.AppendUint64(stream, uint64(len(value)))
+		stream = append(stream, value...)
+	}
+	field("ABC-7")
+	field("Fix login")
+	for i := 0; i < 6; i++ {
+		field("")
+	}
+	stream = binary.BigEndian.AppendUint64(stream, 2)
+	field("backend")
+	field("login")
+	for i := 0; i < 5; i++ {
+		stream = binary.BigEndian.AppendUint64(stream, 0)
+	}
+	sum := sha256.Sum256(stream)
+
+	ticket := &models.TicketData{Key: "ABC-7", Summary: "Fix login", Labels: []string{"login", "backend"}}
+	if got, want := TicketHash(ticket), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("TicketHash = %s, want %s", got, want)
+	}
+}
+
+func TestTicketHashBoundaries(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *models.TicketData
+	}{
+		{
+			"text moved across a field boundary",
+			&models.TicketData{Summary: "ab", Description: "c"},
+			&models.TicketData{Summary: "a", Description: "bc"},
+		},
+		{
+			"same text in a different field",
+			&models.TicketData{Summary: "Open"},
+			&models.TicketData{Status: "Open"},
+		},
+		{
+			"one label against two",
+			&models.TicketData{Labels: []string{"ab"}},
+			&models.TicketData{Labels: []string{"a", "b"}},
+		},
+		{
+			"label against component",
+			&models.TicketData{Labels: []string{"Auth"}},
+			&models.TicketData{Components: []string{"Auth"}},
+		},
+		{
+			"text moved across a comment field boundary",
+			&models.TicketData{Comments: []models.Comment{{Author: "bob", Body: "x"}}},
+			&models.TicketData{Comments: []models.Comment{{Author: "bo", Body: "bx"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if TicketHash(tt.a) == TicketHash(tt.b) {
+				t.Errorf("%+v and %+v hash the same", tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestTicketHashIgnoresOrderAndVolatileFields(t *testing.T) {
+	want := TicketHash(goldenTicket())
+
+	reordered := goldenTicket()
+	reordered.Labels = []string{"login", "backend"}
+	reordered.Comments = append(reordered.Comments, models.Comment{ID: "c2", Body: "Second"})
+	withSecond := TicketHash(reordered)
+	reordered.Comments = []models.Comment{reordered.Comments[1], reordered.Comments[0]}
+	if TicketHash(reordered) != withSecond {
+		t.Error("comment order changed the hash")
+	}
+
+	// The same content collected by another path, at another time
+	other := goldenTicket()
+	other.Labels = []string{"login", "backend"}
+	other.URL = "https://example.atlassian.net/browse/ABC-7"
+	other.ProjectID = "ABC"
+	other.Created = "2024-01-02T10:00:00Z"
+	other.Updated = "2024-06-01T00:00:00Z"
+	other.JiraUpdated = "2024-05-30T00:00:00Z"
+	other.RawHTML = "<html></html>"
+	other.Source = "api"
+	other.CustomFields = map[string]interface{}{"story_points": 3}
+	other.Comments[0].Created = "2024-01-03T00:00:00Z"
+	other.Attachments[0].URL = "https://example.atlassian.net/secure/attachment/a1/log.txt"
+	if got := TicketHash(other); got != want {
+		t.Errorf("hash with volatile fields = %s, want %s", got, want)
+	}
+}
@@ -1,11 +1,53 @@
 package common
 
 import (
+	"context"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
+// walkCheckInterval is the number of nodes a WalkCheck counts between
+// context checks
+const walkCheckInterval = 1024
+
+// WalkCheck stops long HTML tree walks once their context is done. It looks
+// at the context every walkCheckInterval nodes rather than at each one. A nil
+// WalkCheck never stops a walk.
+type WalkCheck struct {
+	ctx   context.Context
+	nodes int
+	err   error
+}
+
+// NewWalkCheck returns a WalkCheck for walks done on behalf of ctx
+func NewWalkCheck(ctx context.Context) *WalkCheck {
+	return &WalkCheck{ctx: ctx}
+}
+
+// Stop counts a visited node and reports whether the walk should stop. Once
+// it returns true it keeps doing so, unwinding every enclosing walk.
+func (c *WalkCheck) Stop() bool {
+	if c == nil {
+		return false
+	}
+	if c.err == nil {
+		c.nodes++
+		if c.nodes%walkCheckInterval == 0 {
+			c.err = c.ctx.Err()
+		}
+	}
+	return c.err != nil
+}
+
+// Err returns the context error that stopped the walk, or nil
+func (c *WalkCheck) Err() error {
+	if c == nil {
+		return nil
+	}
+	return c.err
+}
+
 // ExtractText gets all text content from an HTML node and its children
 func ExtractText(node *html.Node) string {
 	var text strings.Builder
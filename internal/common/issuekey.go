@@ -0,0 +1,53 @@
+package common
+
+import (
+	"cmp"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// issueKeyPattern matches a whole Jira issue key such as "PROJ-123": an
+// uppercase project key starting with a letter, a dash and the issue number
+var issueKeyPattern = regexp.MustCompile(`^([A-Z][A-Z0-9_]*)-(\d+)$`)
+
+// projectKeyPattern matches a whole Jira project key, the part of an issue
+// key before the dash
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// ValidProjectKey reports whether key is a valid uppercase Jira project key
+func ValidProjectKey(key string) bool {
+	return projectKeyPattern.MatchString(key)
+}
+
+// IssueKeyProject returns the project key of a Jira issue key, e.g. "PROJ"
+// for "PROJ-123". ok is false when key is not a valid issue key.
+func IssueKeyProject(key string) (project string, ok bool) {
+	matches := issueKeyPattern.FindStringSubmatch(key)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// ValidIssueKey reports whether key is a valid Jira issue key
+func ValidIssueKey(key string) bool {
+	return issueKeyPattern.MatchString(key)
+}
+
+// CompareIssueKeys orders issue keys by project, then numerically by issue
+// number, so PROJ-9 sorts before PROJ-10. Other keys compare as strings.
+func CompareIssueKeys(a, b string) int {
+	projectA, numA, okA := strings.Cut(a, "-")
+	projectB, numB, okB := strings.Cut(b, "-")
+	if !okA || !okB || projectA != projectB {
+		return strings.Compare(a, b)
+	}
+
+	na, errA := strconv.Atoi(numA)
+	nb, errB := strconv.Atoi(numB)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return cmp.Compare(na, nb)
+}
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareIssueKeys(t *testing.T) {
+	keys := []string{"ABC-10", "XYZ-1", "ABC-9", "ABC-100", "AB-2", "ABC-1"}
+	slices.SortFunc(keys, CompareIssueKeys)
+
+	want := []string{"AB-2", "ABC-1", "ABC-9", "ABC-10", "ABC-100", "XYZ-1"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("sorted keys = %v, want %v", keys, want)
+	}
+
+	if got := CompareIssueKeys("ABC-7", "ABC-7"); got != 0 {
+		t.Errorf("CompareIssueKeys of equal keys = %d, want 0", got)
+	}
+	// Keys without a number fall back to string order
+	if got := CompareIssueKeys("ABC-X", "ABC-10"); got <= 0 {
+		t.Errorf("CompareIssueKeys(ABC-X, ABC-10) = %d, want > 0", got)
+	}
+}
+
+func TestIssueKeyProject(t *testing.T) {
+	tests := []struct {
+		key     string
+		project string
+		valid   bool
+	}{
+		{"ABC-1", "ABC", true},
+		{"PROJ-12345", "PROJ", true},
+		{"A1-7", "A1", true},
+		{"MY_PROJ-3", "MY_PROJ", true},
+		{"abc-1", "", false},
+		{"Abc-1", "", false},
+		{"1ABC-2", "", false},
+		{"_ABC-4", "", false},
+		{"ABC_3", "", false},
+		{"ABC-", "", false},
+		{"ABC", "", false},
+		{"-1", "", false},
+		{"ABC-1a", "", false},
+		{"ABC--1", "", false},
+		{" ABC-1", "", false},
+		{"ABC-1 ", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		project, ok := IssueKeyProject(tt.key)
+		if project != tt.project || ok != tt.valid {
+			t.Errorf("IssueKeyProject(%q) = %q, %v, want %q, %v", tt.key, project, ok, tt.project, tt.valid)
+		}
+		if got := ValidIssueKey(tt.key); got != tt.valid {
+			t.Errorf("ValidIssueKey(%q) = %v, want %v", tt.key, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/phuslu/log"
+	"github.com/ternarybob/arbor/models"
+	"github.com/ternarybob/arbor/writers"
+)
+
+// jsonWriter writes arbor log events as JSON lines with level, time, message
+// and all fields as top-level keys. arbor's console writer only produces text.
+type jsonWriter struct {
+	logger log.Logger
+}
+
+func newJSONWriter(out io.Writer) writers.IWriter {
+	return &jsonWriter{
+		logger: log.Logger{
+			Writer: &log.IOWriter{Writer: out},
+		},
+	}
+}
+
+func (w *jsonWriter) WithLevel(level log.Level) writers.IWriter {
+	w.logger.SetLevel(level)
+	return w
+}
+
+// GetFilePath returns empty string as the JSON writer does not manage a file
+func (w *jsonWriter) GetFilePath() string {
+	return ""
+}
+
+func (w *jsonWriter) Write(data []byte) (int, error) {
+	var event models.LogEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		w.logger.Info().Msg(string(data))
+		return len(data), nil
+	}
+
+	entry := w.logger.WithLevel(event.Level)
+	if entry == nil {
+		return len(data), nil // below the configured level
+	}
+
+	if event.Prefix != "" {
+		entry = entry.Str("prefix", event.Prefix)
+	}
+	if event.Function != "" {
+		entry = entry.Str("function", event.Function)
+	}
+	if event.CorrelationID != "" {
+		entry = entry.Str("correlation_id", event.CorrelationID)
+	}
+	for key, value := range event.Fields {
+		entry = entry.Any(key, value)
+	}
+	if event.Error != "" {
+		entry = entry.Str("error", event.Error)
+	}
+
+	entry.Msg(event.Message)
+	return len(data), nil
+}
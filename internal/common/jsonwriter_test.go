@@ -0,0 +1,55 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ternarybob/arbor"
+)
+
+func TestJSONWriterWritesJSONLines(t *testing.T) {
+	// Route arbor's console output through the JSON writer the way
+	// createLogger does for format = "json"
+	var out bytes.Buffer
+	logger := arbor.NewLogger()
+	arbor.RegisterWriter(arbor.WRITER_CONSOLE, newJSONWriter(&out))
+	t.Cleanup(func() { arbor.UnregisterWriter(arbor.WRITER_CONSOLE) })
+
+	WithTransaction(WithComponent(logger, "receiver"), "txn-42").Warn().
+		Str("url", "https://example.atlassian.net/browse/ABC-1").
+		Int("tickets", 3).
+		Err(errors.New("disk full")).
+		Msg("Failed to store tickets")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("wrote %d lines, want 1:\n%s", len(lines), out.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v\n%s", err, lines[0])
+	}
+
+	want := map[string]interface{}{
+		"level":          "warn",
+		"message":        "Failed to store tickets",
+		"component":      "receiver",
+		"transaction_id": "txn-42",
+		"url":            "https://example.atlassian.net/browse/ABC-1",
+		"tickets":        float64(3),
+		"error":          "disk full",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %#v, want %#v", key, entry[key], value)
+		}
+	}
+	if stamp, _ := entry["time"].(string); stamp == "" {
+		t.Errorf("time missing from %s", lines[0])
+	} else if _, err := time.Parse(time.RFC3339, stamp); err != nil {
+		t.Errorf("time %q is not RFC 3339: %v", stamp, err)
+	}
+}
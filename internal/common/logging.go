@@ -15,11 +15,14 @@ var (
 	mu     sync.RWMutex
 )
 
-func GetLogger() arbor.ILogger {
+// GetLogger returns the shared logger for component. Its events carry a
+// "component" field and are filtered at the component's logging.levels entry,
+// falling back to logging.level.
+func GetLogger(component string) arbor.ILogger {
 	mu.RLock()
 	if logger != nil {
 		mu.RUnlock()
-		return logger
+		return WithComponent(logger, component)
 	}
 	mu.RUnlock()
 
@@ -30,7 +33,7 @@ func GetLogger() arbor.ILogger {
 	if logger == nil {
 		logger = initDefaultLogger()
 	}
-	return logger
+	return WithComponent(logger, component)
 }
 
 // GetLogFilePath returns the actual configured log file path from the arbor logger
@@ -67,6 +70,25 @@ func InitLogger(config *LoggingConfig) error {
 	return err
 }
 
+// SetLogLevels changes the default and per-component log levels at runtime
+func SetLogLevels(level string, components map[string]string) error {
+	if !IsValidLogLevel(level) {
+		return fmt.Errorf("invalid log level: %s", level)
+	}
+	for component, componentLevel := range components {
+		if !IsValidLogLevel(componentLevel) {
+			return fmt.Errorf("invalid log level for %s: %s", component, componentLevel)
+		}
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if logger == nil {
+		return fmt.Errorf("logger not initialized")
+	}
+	return applyLogLevels(logger, level, components)
+}
+
 func initDefaultLogger() arbor.ILogger {
 	config := DefaultLoggingConfig()
 	logger, err := createLogger(config)
@@ -102,32 +124,45 @@ func createLogger(config *LoggingConfig) (arbor.ILogger, error) {
 		DisableTimestamp: false,
 	})
 
+	// JSON lines use full timestamps so entries can be aggregated across days
+	jsonFormat := config.Format == "json"
+	timeFormat := "15:04:05"
+	if jsonFormat {
+		timeFormat = ""
+	}
+
 	// Configure file logging if requested
 	if config.Output == "both" || config.Output == "file" || config.Output == "" {
 		logFile := filepath.Join(logsDir, "aktis-collector-jira.log")
 		l = l.WithFileWriter(models.WriterConfiguration{
 			Type:             models.LogWriterTypeFile,
 			FileName:         logFile,
-			TimeFormat:       "15:04:05",
+			TimeFormat:       timeFormat,
 			MaxSize:          int64(config.MaxSize * 1024 * 1024), // Convert MB to bytes
 			MaxBackups:       config.MaxBackups,
-			TextOutput:       true,
+			TextOutput:       !jsonFormat,
 			DisableTimestamp: false,
 		})
 	}
 
 	// Configure console logging if requested
 	if config.Output == "both" || config.Output == "console" || config.Output == "" {
-		l = l.WithConsoleWriter(models.WriterConfiguration{
-			Type:             models.LogWriterTypeConsole,
-			TimeFormat:       "15:04:05",
-			TextOutput:       true,
-			DisableTimestamp: false,
-		})
+		if jsonFormat {
+			arbor.RegisterWriter(arbor.WRITER_CONSOLE, newJSONWriter(os.Stdout))
+		} else {
+			l = l.WithConsoleWriter(models.WriterConfiguration{
+				Type:             models.LogWriterTypeConsole,
+				TimeFormat:       timeFormat,
+				TextOutput:       true,
+				DisableTimestamp: false,
+			})
+		}
 	}
 
-	// Set log level
-	l = l.WithLevelFromString(config.Level)
+	// Set the default and per-component log levels
+	if err := applyLogLevels(l, config.Level, config.Levels); err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
 
 	// Test logging immediately to verify it's working
 	l.Info().Msg("Aktis Collector Jira logger initialized")
@@ -0,0 +1,47 @@
+package common
+
+import "net/url"
+
+// ResolveURL resolves ref against the absolute base URL, handling
+// protocol-relative, root-relative, query-only and ../ references. ref is
+// returned unchanged when it is empty, either URL is malformed or base is
+// not absolute.
+func ResolveURL(base, ref string) string {
+	if ref == "" {
+		return ref
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	if refURL.IsAbs() {
+		return ref
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil || !IsAbsoluteURL(base) {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// IsAbsoluteURL reports whether raw is an absolute http(s) URL with a host
+func IsAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// URLHostPath returns the host and path of raw without its scheme, query or
+// fragment, e.g. "example.atlassian.net/browse/ABC-1". Malformed URLs are
+// returned unchanged.
+func URLHostPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return u.Host + u.Path
+}
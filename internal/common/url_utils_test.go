@@ -0,0 +1,56 @@
+package common
+
+import "testing"
+
+func TestResolveURL(t *testing.T) {
+	const base = "https://example.atlassian.net:8443/jira/projects/ABC/issues?filter=all"
+
+	tests := []struct {
+		name string
+		base string
+		ref  string
+		want string
+	}{
+		{"absolute", base, "https://other.atlassian.net/browse/XYZ-1", "https://other.atlassian.net/browse/XYZ-1"},
+		{"protocol-relative", base, "//cdn.example.com/avatar.png", "https://cdn.example.com/avatar.png"},
+		{"root-relative keeps the port", base, "/browse/ABC-7", "https://example.atlassian.net:8443/browse/ABC-7"},
+		{"relative", base, "ABC-7", "https://example.atlassian.net:8443/jira/projects/ABC/ABC-7"},
+		{"parent directory", base, "../XYZ/issues", "https://example.atlassian.net:8443/jira/projects/XYZ/issues"},
+		{"past the root", base, "../../../../browse/ABC-7", "https://example.atlassian.net:8443/browse/ABC-7"},
+		{"query only", base, "?filter=open", "https://example.atlassian.net:8443/jira/projects/ABC/issues?filter=open"},
+		{"fragment only", base, "#comment-1", "https://example.atlassian.net:8443/jira/projects/ABC/issues?filter=all#comment-1"},
+		{"empty reference", base, "", ""},
+		{"malformed reference", base, "http://[::1", "http://[::1"},
+		{"relative base", "/browse/ABC-1", "/browse/ABC-7", "/browse/ABC-7"},
+		{"empty base", "", "/browse/ABC-7", "/browse/ABC-7"},
+		{"malformed base", "https://[::1", "/browse/ABC-7", "/browse/ABC-7"},
+		{"non-http base", "file:///tmp/page.html", "/browse/ABC-7", "/browse/ABC-7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveURL(tt.base, tt.ref); got != tt.want {
+				t.Errorf("ResolveURL(%q, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAbsoluteURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"https://example.atlassian.net", true},
+		{"http://localhost:8080/browse/ABC-1", true},
+		{"//example.atlassian.net/browse/ABC-1", false},
+		{"/browse/ABC-1", false},
+		{"ftp://example.com/file", false},
+		{"https://", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsAbsoluteURL(tt.raw); got != tt.want {
+			t.Errorf("IsAbsoluteURL(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package common
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -39,7 +41,8 @@ func GetFullVersion() string {
 	return Version
 }
 
-// GetExtensionVersion reads the extension version from .version file
+// GetExtensionVersion reads the extension version from the .version file
+// next to the executable or in the working directory or its parents
 func GetExtensionVersion() string {
 	// Try multiple possible locations for .version file
 	possiblePaths := []string{
@@ -47,6 +50,9 @@ func GetExtensionVersion() string {
 		filepath.Join("..", ".version"),
 		filepath.Join("..", "..", ".version"),
 	}
+	if execPath, err := os.Executable(); err == nil {
+		possiblePaths = append([]string{filepath.Join(filepath.Dir(execPath), ".version")}, possiblePaths...)
+	}
 
 	for _, versionPath := range possiblePaths {
 		data, err := os.ReadFile(versionPath)
@@ -68,3 +74,68 @@ func GetExtensionVersion() string {
 
 	return "unknown"
 }
+
+// CompareVersions compares two dotted numeric versions such as "0.1.164",
+// returning -1, 0 or 1. Missing parts count as zero.
+func CompareVersions(a, b string) (int, error) {
+	partsA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	partsB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// ValidateExtensionVersion checks a version against the Chrome manifest
+// format: one to four dot-separated integers between 0 and 65535
+func ValidateExtensionVersion(version string) error {
+	parts, err := parseVersion(version)
+	if err != nil {
+		return err
+	}
+	if len(parts) > 4 {
+		return fmt.Errorf("version %q has more than four parts", version)
+	}
+	for _, part := range parts {
+		if part > 65535 {
+			return fmt.Errorf("version %q has a part above 65535", version)
+		}
+	}
+	return nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	if version == "" {
+		return nil, fmt.Errorf("version is empty")
+	}
+
+	fields := strings.Split(version, ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 || field != strconv.Itoa(n) {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
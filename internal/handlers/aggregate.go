@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+// maxAggregateBuckets bounds the series length so a stray timestamp far in
+// the past cannot produce an enormous response
+const maxAggregateBuckets = 1000
+
+// AggregateBucket is the ticket count for one time interval
+type AggregateBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// AggregateResponse is a time series of ticket counts
+type AggregateResponse struct {
+	Success  bool              `json:"success"`
+	GroupBy  string            `json:"group_by"`
+	Interval string            `json:"interval"`
+	Project  string            `json:"project,omitempty"`
+	Series   []AggregateBucket `json:"series"`
+	Skipped  int               `json:"skipped"` // tickets without a parseable timestamp
+}
+
+// AggregateHandler buckets tickets by their created or updated time.
+// Query parameters: group_by (created|updated), interval (day|week|month)
+// and project. Empty buckets are included so charts have no gaps.
+func (h *APIHandlers) AggregateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	query := r.URL.Query()
+	groupBy := strings.ToLower(query.Get("group_by"))
+	if groupBy == "" {
+		groupBy = "created"
+	}
+	interval := strings.ToLower(query.Get("interval"))
+	if interval == "" {
+		interval = "week"
+	}
+	project := strings.ToUpper(query.Get("project"))
+
+	if groupBy == "resolved" {
+		respondError(w, r, http.StatusBadRequest, "group_by resolved is not available: resolution dates are not collected")
+		return
+	}
+	if groupBy != "created" && groupBy != "updated" {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid group_by %q: must be created or updated", groupBy))
+		return
+	}
+	if bucketStart(time.Now(), interval).IsZero() {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid interval %q: must be day, week or month", interval))
+		return
+	}
+
+	var tickets map[string]*models.TicketData
+	var err error
+	if project != "" {
+		tickets, err = h.storage.LoadTickets(project)
+	} else {
+		tickets, err = h.storage.LoadAllTickets()
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("project", project).Msg("Failed to load tickets for aggregation")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	series, skipped := aggregateTickets(tickets, groupBy, interval)
+	if len(series) > maxAggregateBuckets {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("Too many buckets (%d): use a longer interval", len(series)))
+		return
+	}
+
+	response := AggregateResponse{
+		Success:  true,
+		GroupBy:  groupBy,
+		Interval: interval,
+		Project:  project,
+		Series:   series,
+		Skipped:  skipped,
+	}
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode aggregate response")
+	}
+}
+
+// aggregateTickets counts tickets per interval from the earliest to the latest
+// bucket, returning the ordered series and the number of tickets skipped
+// because their timestamp could not be parsed
+func aggregateTickets(tickets map[string]*models.TicketData, groupBy, interval string) ([]AggregateBucket, int) {
+	counts := make(map[time.Time]int)
+	skipped := 0
+	var first, last time.Time
+
+	for _, ticket := range tickets {
+		value := ticket.Created
+		if groupBy == "updated" {
+			value = ticket.Updated
+		}
+
+		t, ok := parseTime(value)
+		if !ok {
+			skipped++
+			continue
+		}
+
+		start := bucketStart(t, interval)
+		counts[start]++
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	series := []AggregateBucket{}
+	if first.IsZero() {
+		return series, skipped
+	}
+	for start := first; !start.After(last); start = nextBucket(start, interval) {
+		series = append(series, AggregateBucket{Start: start, Count: counts[start]})
+		if len(series) > maxAggregateBuckets {
+			break
+		}
+	}
+	return series, skipped
+}
+
+// bucketStart returns the start of the UTC interval containing t, or the zero
+// time for an unknown interval. Weeks start on Monday.
+func bucketStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch interval {
+	case "day":
+		return day
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+}
+
+func nextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case "day":
+		return start.AddDate(0, 0, 1)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
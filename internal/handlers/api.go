@@ -6,17 +6,24 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/metrics"
+	"aktis-collector-jira/internal/middleware"
 	"aktis-collector-jira/internal/models"
 
 	"github.com/ternarybob/arbor"
+	"golang.org/x/net/html"
 )
 
 // APIHandlers contains all API endpoint handlers
@@ -27,6 +34,45 @@ type APIHandlers struct {
 	startTime time.Time
 	assessor  interfaces.PageAssessor
 	wsHub     *WebSocketHub
+
+	// Component loggers for extension payloads and HTML parsing
+	receiverLogger arbor.ILogger
+	parserLogger   arbor.ILogger
+
+	// Recently received payload hashes for the receiver dedupe window
+	recentMu       sync.Mutex
+	recentPayloads map[string]time.Time
+
+	// Receiver, parser and storage failures for /errors, /status and /metrics
+	errorTracker *ErrorTracker
+
+	// Receiver payload sizes and durations by page type
+	metrics *metrics.Registry
+
+	// Latest /reprocess run, guarded so only one runs at a time
+	reprocessMu sync.Mutex
+	reprocess   *ReprocessStatus
+
+	// Latest /backfill run and the Jira client it fetches issues with
+	backfillMu   sync.Mutex
+	backfill     *BackfillStatus
+	issueFetcher interfaces.IssueFetcher
+
+	// Serializes extension uploads so version checks see the latest release
+	extensionMu sync.Mutex
+
+	// Posts collection events to the configured webhooks
+	notifier interfaces.Notifier
+
+	// Receiver requests by extension version for /extensions
+	extensions *ExtensionTracker
+
+	// Latest startup or on-demand self-check for /selfcheck, and the pages
+	// directory and UI state it checks
+	selfCheckMu sync.Mutex
+	selfCheck   *models.SelfCheckReport
+	pagesDir    string
+	uiLoaded    bool
 }
 
 // HealthResponse represents the health check response
@@ -37,9 +83,16 @@ type HealthResponse struct {
 	Build     string    `json:"build"`
 	Uptime    float64   `json:"uptime_seconds"`
 	Services  struct {
-		Database bool `json:"database"`
-		Jira     bool `json:"jira"`
+		Database       bool   `json:"database"`
+		DatabaseStatus string `json:"database_status"` // ok, locked, unavailable or corrupt
+		Jira           bool   `json:"jira"`
 	} `json:"services"`
+	// StaleProjects is set when storage.stale_degrades_health is enabled
+	StaleProjects []string `json:"stale_projects,omitempty"`
+	// Storage compares the database and its disk with the storage limits;
+	// a reached limit degrades health
+	Storage *models.StorageCapacity `json:"storage,omitempty"`
+	Error   string                  `json:"error,omitempty"`
 }
 
 // VersionResponse represents version information for both server and extension
@@ -53,28 +106,39 @@ type VersionResponse struct {
 		Version        string `json:"version"`
 		LatestVersion  string `json:"latest_version"`
 		UpdateRequired bool   `json:"update_required"`
+		MinVersion     string `json:"min_version,omitempty"`
+		Blocked        bool   `json:"blocked"` // the receiver rejects this version
+		DownloadURL    string `json:"download_url,omitempty"`
+		SHA256         string `json:"sha256,omitempty"`
 	} `json:"extension"`
 }
 
 // StatusResponse represents the collector status response
 type StatusResponse struct {
 	Collector struct {
-		Running    bool      `json:"running"`
-		Uptime     float64   `json:"uptime"`
-		ErrorCount int       `json:"error_count"`
-		LastRun    time.Time `json:"last_run,omitempty"`
+		Running     bool                        `json:"running"`
+		Uptime      float64                     `json:"uptime"`
+		ErrorCount  int                         `json:"error_count"`
+		ErrorCounts map[common.ErrorType]uint64 `json:"error_counts"`
+		LastRun     time.Time                   `json:"last_run,omitempty"`
+		ReadOnly    bool                        `json:"read_only"`
 	} `json:"collector"`
-	Projects []ProjectStatus `json:"projects"`
-	Stats    CollectorStats  `json:"stats"`
+	Projects  []ProjectStatus `json:"projects"`
+	Stats     CollectorStats  `json:"stats"`
+	WebSocket *HubStats       `json:"websocket,omitempty"`
 }
 
 // ProjectStatus represents the status of a single project
 type ProjectStatus struct {
-	Key         string    `json:"key"`
-	Name        string    `json:"name"`
-	TicketCount int       `json:"ticket_count"`
-	LastUpdate  time.Time `json:"last_update,omitempty"`
-	Status      string    `json:"status"`
+	Key              string                 `json:"key"`
+	Name             string                 `json:"name"`
+	TicketCount      int                    `json:"ticket_count"`
+	LastUpdate       *time.Time             `json:"last_update,omitempty"`
+	Status           string                 `json:"status"` // fresh, stale or never
+	StalenessSeconds float64                `json:"staleness_seconds"`
+	UpdatedAges      models.AgeDistribution `json:"updated_ages"`
+	NewestJiraUpdate *time.Time             `json:"newest_jira_update,omitempty"`
+	IncompleteSave   *models.SaveProgress   `json:"incomplete_save,omitempty"`
 }
 
 // CollectorStats represents overall collector statistics
@@ -87,8 +151,13 @@ type CollectorStats struct {
 // ConfigResponse represents the configuration display response
 type ConfigResponse struct {
 	Collector *common.CollectorConfig `json:"collector"`
+	Server    *common.ServerConfig    `json:"server"`
 	Storage   *common.StorageConfig   `json:"storage"`
 	Logging   *common.LoggingConfig   `json:"logging"`
+	Receiver  *common.ReceiverConfig  `json:"receiver"`
+	// Webhook URLs are left out; they often contain secret tokens
+	Notifications *common.NotificationsConfig `json:"notifications"`
+	Token         string                      `json:"token,omitempty"`
 }
 
 // DatabaseResponse represents database operation responses
@@ -99,21 +168,44 @@ type DatabaseResponse struct {
 }
 
 // NewAPIHandlers creates a new API handlers instance
-func NewAPIHandlers(config *common.Config, storage interfaces.Storage, logger arbor.ILogger, assessor interfaces.PageAssessor, wsHub *WebSocketHub) *APIHandlers {
+func NewAPIHandlers(config *common.Config, storage interfaces.Storage, logger arbor.ILogger, assessor interfaces.PageAssessor, wsHub *WebSocketHub, registry *metrics.Registry, notifier interfaces.Notifier) *APIHandlers {
+	apiLogger := common.WithComponent(logger, "api")
+	stored, err := storage.LoadExtensionStats()
+	if err != nil {
+		apiLogger.Warn().Err(err).Msg("Failed to load extension stats")
+	}
+
 	return &APIHandlers{
-		config:    config,
-		storage:   storage,
-		logger:    logger,
-		startTime: time.Now(),
-		assessor:  assessor,
-		wsHub:     wsHub,
+		config:         config,
+		storage:        storage,
+		logger:         apiLogger,
+		startTime:      time.Now(),
+		assessor:       assessor,
+		wsHub:          wsHub,
+		receiverLogger: common.WithComponent(logger, "receiver"),
+		parserLogger:   common.WithComponent(logger, "parser"),
+		errorTracker:   NewErrorTracker(),
+		metrics:        registry,
+		notifier:       notifier,
+		extensions:     NewExtensionTracker(stored),
 	}
 }
 
+// Errors returns the tracker that receiver, parser and storage failures are
+// reported to
+func (h *APIHandlers) Errors() *ErrorTracker {
+	return h.errorTracker
+}
+
 // HealthHandler returns system health status
 func (h *APIHandlers) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
 	health := HealthResponse{
 		Status:    "healthy",
 		Timestamp: time.Now(),
@@ -123,17 +215,50 @@ func (h *APIHandlers) HealthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Test database connection
-	health.Services.Database = h.testDatabaseConnection()
+	health.Services.DatabaseStatus = "ok"
+	if err := h.storage.Ping(); err != nil {
+		health.Status = "degraded"
+		health.Services.DatabaseStatus = "unavailable"
+		health.Error = err.Error()
+		var collectorErr *common.CollectorError
+		if errors.As(err, &collectorErr) {
+			health.Services.DatabaseStatus = strings.TrimPrefix(collectorErr.Code, "database_")
+			health.Error = collectorErr.Message
+		}
+		h.logger.Warn().Err(err).Msg("Database health check failed")
+	}
+	health.Services.Database = health.Services.DatabaseStatus == "ok"
 	health.Services.Jira = true // No external Jira connection needed (extension-based)
 
-	// If database is down, mark as degraded
-	if !health.Services.Database {
-		health.Status = "degraded"
+	if health.Services.Database {
+		if capacity, err := h.storage.Capacity(); err != nil {
+			h.logger.Warn().Err(err).Msg("Failed to measure storage capacity")
+		} else {
+			health.Storage = capacity
+			if capacity.Exceeded {
+				health.Status = "degraded"
+				health.Error = "Storage limit reached: " + capacity.Reason
+			}
+		}
+	}
+
+	if h.config.Storage.StaleDegradesHealth && health.Services.Database {
+		if freshness, err := h.storage.GetProjectFreshness(); err != nil {
+			h.logger.Warn().Err(err).Msg("Failed to check project freshness")
+		} else {
+			for _, project := range freshness {
+				if project.Status == models.FreshnessStale {
+					health.StaleProjects = append(health.StaleProjects, project.Key)
+				}
+			}
+			if len(health.StaleProjects) > 0 {
+				health.Status = "degraded"
+			}
+		}
 	}
 
-	if err := json.NewEncoder(w).Encode(health); err != nil {
+	if err := respondJSON(w, http.StatusOK, health); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode health response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -141,13 +266,19 @@ func (h *APIHandlers) HealthHandler(w http.ResponseWriter, r *http.Request) {
 func (h *APIHandlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
 	// Get client's extension version from query parameter
 	clientExtVersion := r.URL.Query().Get("extension_version")
 
-	// Read latest extension version from .version file
-	latestExtVersion := common.GetExtensionVersion()
+	// Latest uploaded extension release, or the .version file
+	latestExtVersion, release := h.latestExtensionVersion()
 
 	versionResp := VersionResponse{}
+	versionResp.Extension.MinVersion = h.config.Receiver.MinExtensionVersion
 
 	// Server version info
 	versionResp.Server.Version = common.GetVersion()
@@ -156,17 +287,28 @@ func (h *APIHandlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Extension version info
 	versionResp.Extension.LatestVersion = latestExtVersion
+	if release != nil {
+		versionResp.Extension.DownloadURL = "/extension/download"
+		versionResp.Extension.SHA256 = release.SHA256
+	}
 	if clientExtVersion != "" {
 		versionResp.Extension.Version = clientExtVersion
-		versionResp.Extension.UpdateRequired = clientExtVersion != latestExtVersion
+		if cmp, err := common.CompareVersions(clientExtVersion, latestExtVersion); err == nil {
+			versionResp.Extension.UpdateRequired = cmp < 0
+		} else {
+			versionResp.Extension.UpdateRequired = clientExtVersion != latestExtVersion
+		}
+		if h.extensionBlocked(clientExtVersion) {
+			versionResp.Extension.Blocked = true
+			versionResp.Extension.UpdateRequired = true
+		}
 	} else {
 		versionResp.Extension.Version = "unknown"
 		versionResp.Extension.UpdateRequired = false
 	}
 
-	if err := json.NewEncoder(w).Encode(versionResp); err != nil {
+	if err := respondJSON(w, http.StatusOK, versionResp); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode version response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -174,6 +316,11 @@ func (h *APIHandlers) VersionHandler(w http.ResponseWriter, r *http.Request) {
 func (h *APIHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
 	status := StatusResponse{
 		Projects: make([]ProjectStatus, 0),
 		Stats: CollectorStats{
@@ -183,8 +330,11 @@ func (h *APIHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Collector status
 	status.Collector.Running = true // Assume running if we can respond
+	status.Collector.ReadOnly = h.config.Storage.ReadOnly
 	status.Collector.Uptime = time.Since(h.startTime).Seconds()
-	status.Collector.ErrorCount = 0
+	errorSummary := h.errorTracker.Summary()
+	status.Collector.ErrorCount = int(errorSummary.Total)
+	status.Collector.ErrorCounts = errorSummary.Counts
 
 	// Load all tickets to calculate stats
 	allTickets, err := h.storage.LoadAllTickets()
@@ -212,9 +362,37 @@ func (h *APIHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 		status.Stats.LastCollection = "Never"
 	}
 
-	if err := json.NewEncoder(w).Encode(status); err != nil {
+	if freshness, err := h.storage.GetProjectFreshness(); err != nil {
+		h.logger.Warn().Err(err).Msg("Failed to load project freshness for status")
+	} else {
+		names := make(map[string]string)
+		if projects, err := h.storage.LoadProjects(); err == nil {
+			for _, project := range projects {
+				names[project.Key] = project.Name
+			}
+		}
+		for _, project := range freshness {
+			status.Projects = append(status.Projects, ProjectStatus{
+				Key:              project.Key,
+				Name:             names[project.Key],
+				TicketCount:      project.Tickets,
+				LastUpdate:       project.LastCollection,
+				Status:           project.Status,
+				StalenessSeconds: project.StalenessSeconds,
+				UpdatedAges:      project.UpdatedAges,
+				NewestJiraUpdate: project.NewestJiraUpdate,
+				IncompleteSave:   project.IncompleteSave,
+			})
+		}
+	}
+
+	if h.wsHub != nil {
+		hubStats := h.wsHub.Stats()
+		status.WebSocket = &hubStats
+	}
+
+	if err := respondJSON(w, http.StatusOK, status); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode status response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -222,16 +400,31 @@ func (h *APIHandlers) StatusHandler(w http.ResponseWriter, r *http.Request) {
 func (h *APIHandlers) ConfigHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Create sanitized config
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPut)
+		return
+	}
+
+	// Create sanitized config. Storage and logging hold runtime settings, so
+	// they are copied under the runtime lock.
+	storage, logging := h.config.RuntimeSnapshot()
 	config := ConfigResponse{
 		Collector: &h.config.Collector,
-		Storage:   &h.config.Storage,
-		Logging:   &h.config.Logging,
+		Server:    &h.config.Server,
+		Storage:   &storage,
+		Logging:   &logging,
+		Receiver:  &h.config.Receiver,
+
+		Notifications: &h.config.Notifications,
 	}
 
-	if err := json.NewEncoder(w).Encode(config); err != nil {
+	// Only callers that already hold the API key get it back for WebSocket use
+	if apiKey := h.config.Collector.APIKey; apiKey != "" && middleware.ValidAPIKey(r, apiKey) {
+		config.Token = apiKey
+	}
+
+	if err := respondJSON(w, http.StatusOK, config); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode config response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -240,7 +433,7 @@ func (h *APIHandlers) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet)
 		return
 	}
 
@@ -248,7 +441,7 @@ func (h *APIHandlers) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
 	projects, err := h.storage.LoadProjects()
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to load projects")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_projects", "Failed to load projects"), h.config.IsDevelopment())
 		return
 	}
 
@@ -269,6 +462,7 @@ func (h *APIHandlers) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
 			"url":          project.URL,
 			"description":  project.Description,
 			"updated":      project.Updated,
+			"disabled":     project.Disabled,
 			"ticket_count": ticketCount,
 		})
 	}
@@ -279,7 +473,7 @@ func (h *APIHandlers) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
 		"count":    len(projectsResponse),
 	}
 
-	json.NewEncoder(w).Encode(response)
+	respondJSON(w, http.StatusOK, response)
 }
 
 // DatabaseHandler handles database operations
@@ -292,7 +486,7 @@ func (h *APIHandlers) DatabaseHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodDelete:
 		h.handleClearDatabase(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet, http.MethodDelete)
 	}
 }
 
@@ -301,7 +495,7 @@ func (h *APIHandlers) handleGetDatabase(w http.ResponseWriter, r *http.Request)
 	allTickets, err := h.storage.LoadAllTickets()
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to load tickets")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
 		return
 	}
 
@@ -311,9 +505,8 @@ func (h *APIHandlers) handleGetDatabase(w http.ResponseWriter, r *http.Request)
 		Count:   len(allTickets),
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode database response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
@@ -327,8 +520,7 @@ func (h *APIHandlers) handleClearDatabase(w http.ResponseWriter, r *http.Request
 			Success: false,
 			Message: "Failed to clear projects",
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -339,8 +531,7 @@ func (h *APIHandlers) handleClearDatabase(w http.ResponseWriter, r *http.Request
 			Success: false,
 			Message: "Failed to clear tickets",
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, http.StatusInternalServerError, response)
 		return
 	}
 
@@ -352,25 +543,187 @@ func (h *APIHandlers) handleClearDatabase(w http.ResponseWriter, r *http.Request
 		Count:   0,
 	}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to encode database response")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
-func (h *APIHandlers) testDatabaseConnection() bool {
-	// Test by trying to load all tickets
-	_, err := h.storage.LoadAllTickets()
-	return err == nil
+// ProjectEnableHandler resumes collection for a project
+func (h *APIHandlers) ProjectEnableHandler(w http.ResponseWriter, r *http.Request) {
+	h.setProjectDisabled(w, r, false)
+}
+
+// ProjectDisableHandler pauses collection for a project. Its stored tickets
+// and settings are kept.
+func (h *APIHandlers) ProjectDisableHandler(w http.ResponseWriter, r *http.Request) {
+	h.setProjectDisabled(w, r, true)
+}
+
+func (h *APIHandlers) setProjectDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	projectKey := strings.ToUpper(r.PathValue("key"))
+	if !common.ValidProjectKey(projectKey) {
+		respondError(w, r, http.StatusBadRequest, "Invalid project key: "+r.PathValue("key"))
+		return
+	}
+
+	// Only stored or configured projects can be toggled, so a mistyped key
+	// does not leave an empty project record behind
+	if !h.config.Projects.IsConfigured(projectKey) {
+		projects, err := h.storage.LoadProjects()
+		if err != nil {
+			respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_projects", "Failed to load projects"), h.config.IsDevelopment())
+			return
+		}
+		if !slices.ContainsFunc(projects, func(p *models.ProjectData) bool { return p.Key == projectKey }) {
+			respondError(w, r, http.StatusNotFound, "Project not found: "+projectKey)
+			return
+		}
+	}
+
+	if err := h.storage.SetProjectDisabled(projectKey, disabled); err != nil {
+		h.logger.Error().Err(err).Str("project", projectKey).Msg("Failed to update project state")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "save_project", "Failed to update project state"), h.config.IsDevelopment())
+		return
+	}
+
+	state := "enabled"
+	if disabled {
+		state = models.FreshnessDisabled
+	}
+	h.logger.Info().Str("project", projectKey).Str("state", state).Msg("Project collection state changed")
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Project %s %s", projectKey, state),
+		"project": projectKey,
+		"state":   state,
+	})
+}
+
+// ProjectTicketsHandler deletes all stored tickets for a single project
+func (h *APIHandlers) ProjectTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		methodNotAllowed(w, r, http.MethodDelete)
+		return
+	}
+
+	projectKey := strings.ToUpper(r.PathValue("key"))
+	h.logger.Info().Str("project", projectKey).Msg("Clearing stored tickets for project")
+
+	removed, err := h.storage.ClearProjectTickets(projectKey)
+	if err != nil {
+		h.logger.Error().Err(err).Str("project", projectKey).Msg("Failed to clear project tickets")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "clear_tickets", "Failed to clear project tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	h.logger.Info().Str("project", projectKey).Int("removed", removed).Msg("Cleared project tickets")
+
+	if h.wsHub != nil {
+		h.wsHub.SendCollectionUpdate("tickets_cleared", map[string]interface{}{
+			"project": projectKey,
+			"removed": removed,
+		})
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Removed %d tickets from %s", removed, projectKey),
+		"project": projectKey,
+		"removed": removed,
+	}
+
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode clear response")
+	}
+}
+
+// ticketFromIssue converts a parsed or pre-extracted issue to TicketData
+func ticketFromIssue(issueData map[string]interface{}, key, projectKey, timestamp string) *models.TicketData {
+	ticket := &models.TicketData{
+		Key:       key,
+		ProjectID: projectKey,
+		Updated:   timestamp,
+		Source:    "extension",
+	}
+
+	if projectID, ok := issueData["project_id"].(string); ok && projectID != "" {
+		ticket.ProjectID = projectID
+	}
+	if url, ok := issueData["url"].(string); ok {
+		ticket.URL = url
+	}
+	if summary, ok := issueData["summary"].(string); ok {
+		ticket.Summary = summary
+	}
+	if description, ok := issueData["description"].(string); ok {
+		ticket.Description = description
+	}
+	if issueType, ok := issueData["issue_type"].(string); ok {
+		ticket.IssueType = issueType
+	}
+	if status, ok := issueData["status"].(string); ok {
+		ticket.Status = status
+	}
+	if priority, ok := issueData["priority"].(string); ok {
+		ticket.Priority = priority
+	}
+	if reporter, ok := issueData["reporter"].(string); ok {
+		ticket.Reporter = reporter
+	}
+	if assignee, ok := issueData["assignee"].(string); ok {
+		ticket.Assignee = assignee
+	}
+	if source, ok := issueData["source"].(string); ok && source != "" {
+		ticket.Source = source
+	}
+	if updated, ok := parseTime(issueData["jira_updated"]); ok {
+		ticket.JiraUpdated = updated.UTC().Format(time.RFC3339)
+	}
+	ticket.Labels = models.NormalizeList(stringList(issueData["labels"]))
+	ticket.Components = models.NormalizeList(stringList(issueData["components"]))
+	return ticket
+}
+
+// stringList reads a list of strings from a parsed issue ([]string) or a
+// pre-extracted one decoded from JSON ([]interface{}). Other values are
+// ignored.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+		return items
+	}
+	return nil
 }
 
-// storeIssuesArray stores multiple issues from an array
-func (h *APIHandlers) storeIssuesArray(issuesArray []interface{}, timestamp string) error {
+// storeIssuesArray stores multiple issues from an array and returns how many
+// tickets were new. rawHTML is kept on the ticket when the page yielded a
+// single issue. Tickets of projects cleared after pageTime are ignored, so a
+// page loaded before a clear cannot bring them back; a zero pageTime stores
+// them anyway. When a project's tickets cannot be saved the other projects are
+// still stored and the error names the projects that failed.
+func (h *APIHandlers) storeIssuesArray(issuesArray []interface{}, timestamp string, pageTime time.Time, rawHTML string, transactionID string) (int, error) {
+	logger := common.WithTransaction(h.receiverLogger, transactionID)
 	storedCount := 0
 	errorCount := 0
+	addedCount := 0
 
 	// Group issues by project
 	projectTickets := make(map[string]map[string]*models.TicketData)
+	disabled := h.disabledProjects(logger)
+	skipped := make(map[string]int)
+	cleared := make(map[string]bool)
+	tombstoned := make(map[string]int)
 
 	for _, issueInterface := range issuesArray {
 		issueData, ok := issueInterface.(map[string]interface{})
@@ -383,98 +736,97 @@ func (h *APIHandlers) storeIssuesArray(issuesArray []interface{}, timestamp stri
 			continue
 		}
 
-		// Extract project key from issue key
-		projectKey := ""
-		for i, c := range key {
-			if c == '-' {
-				projectKey = key[:i]
-				break
+		// Malformed keys from a bad parse never reach storage
+		projectKey, valid := common.IssueKeyProject(key)
+		if !valid {
+			logger.Warn().Str("key", key).Msg("Rejected malformed issue key")
+			if h.metrics != nil {
+				h.metrics.AddCounter("receiver_invalid_keys_total", "Issue keys rejected by the receiver as malformed", 1)
 			}
-		}
-
-		if projectKey == "" {
-			h.logger.Warn().Str("key", key).Msg("Could not extract project key from issue key")
 			errorCount++
 			continue
 		}
-
-		// Initialize project map if needed
-		if projectTickets[projectKey] == nil {
-			// Load existing tickets for this project
-			existing, err := h.storage.LoadTickets(projectKey)
-			if err != nil {
-				// Create new map if project doesn't exist
-				projectTickets[projectKey] = make(map[string]*models.TicketData)
-			} else {
-				projectTickets[projectKey] = existing
-			}
+		if disabled[projectKey] {
+			skipped[projectKey]++
+			continue
 		}
-
-		// Convert to TicketData
-		ticket := &models.TicketData{
-			Key:       key,
-			ProjectID: projectKey,
-			Updated:   timestamp,
+		if h.clearedSince(cleared, projectKey, pageTime, logger) {
+			tombstoned[projectKey]++
+			continue
 		}
 
-		if projectID, ok := issueData["project_id"].(string); ok && projectID != "" {
-			ticket.ProjectID = projectID
-		}
-		if url, ok := issueData["url"].(string); ok {
-			ticket.URL = url
-		}
-		if summary, ok := issueData["summary"].(string); ok {
-			ticket.Summary = summary
-		}
-		if description, ok := issueData["description"].(string); ok {
-			ticket.Description = description
-		}
-		if issueType, ok := issueData["issue_type"].(string); ok {
-			ticket.IssueType = issueType
-		}
-		if status, ok := issueData["status"].(string); ok {
-			ticket.Status = status
-		}
-		if priority, ok := issueData["priority"].(string); ok {
-			ticket.Priority = priority
-		}
-		if reporter, ok := issueData["reporter"].(string); ok {
-			ticket.Reporter = reporter
+		// Initialize project map if needed. Storage merges each ticket into
+		// its stored record, so only the received tickets are saved.
+		if projectTickets[projectKey] == nil {
+			projectTickets[projectKey] = make(map[string]*models.TicketData)
 		}
-		if assignee, ok := issueData["assignee"].(string); ok {
-			ticket.Assignee = assignee
+
+		ticket := ticketFromIssue(issueData, key, projectKey, timestamp)
+		if len(issuesArray) == 1 {
+			ticket.RawHTML = rawHTML
 		}
 
 		projectTickets[projectKey][key] = ticket
-		storedCount++
+	}
+
+	for projectKey, count := range skipped {
+		logger.Info().
+			Str("project", projectKey).
+			Int("count", count).
+			Msg("Skipped tickets for disabled project")
+	}
+	for projectKey, count := range tombstoned {
+		logger.Info().
+			Str("project", projectKey).
+			Int("count", count).
+			Str("page_time", pageTime.Format(time.RFC3339)).
+			Msg("Ignored tickets cleared after the page was loaded")
+		if h.metrics != nil {
+			h.metrics.AddCounter("receiver_tombstoned_tickets_total", "Tickets ignored because their project was cleared after the page was loaded", float64(count))
+		}
 	}
 
 	// Save all projects
+	var failedProjects []string
+	var saveErr error
 	for projectKey, tickets := range projectTickets {
-		if err := h.storage.SaveTickets(projectKey, tickets); err != nil {
-			h.logger.Error().
+		added, err := h.storage.SaveTickets(projectKey, tickets)
+		if err != nil {
+			logger.Error().
 				Err(err).
 				Str("project", projectKey).
 				Msg("Failed to save tickets for project")
+			h.errorTracker.Record("storage", common.WrapError(err, common.ErrorTypeStorage, "save_tickets", "Failed to save tickets for "+projectKey))
 			errorCount++
+			failedProjects = append(failedProjects, projectKey)
+			if saveErr == nil {
+				saveErr = err
+			}
 		} else {
-			h.logger.Info().
+			storedCount += len(tickets)
+			addedCount += added
+			logger.Info().
 				Str("project", projectKey).
 				Int("count", len(tickets)).
+				Int("added", added).
 				Msg("Stored tickets for project")
 		}
 	}
 
-	h.logger.Info().
+	logger.Info().
 		Int("stored", storedCount).
 		Int("errors", errorCount).
 		Msg("Completed storing issues array")
 
+	if len(failedProjects) > 0 {
+		slices.Sort(failedProjects)
+		return addedCount, fmt.Errorf("failed to save tickets for %s (%d other tickets stored): %w", strings.Join(failedProjects, ", "), storedCount, saveErr)
+	}
 	if errorCount > 0 && storedCount == 0 {
-		return fmt.Errorf("failed to store any issues (%d errors)", errorCount)
+		return 0, fmt.Errorf("failed to store any issues (%d errors)", errorCount)
 	}
 
-	return nil
+	return addedCount, nil
 }
 
 // ExtensionDataPayload represents data received from Chrome extension
@@ -487,6 +839,22 @@ type ExtensionDataPayload struct {
 		Name    string `json:"name"`
 		Version string `json:"version"`
 	} `json:"collector"`
+
+	// pageTime is when the page was loaded in the browser, compared with
+	// tombstones left by clears; zero when ?force=true skips the check
+	pageTime time.Time
+}
+
+// payloadPageTime returns when the payload's page was loaded: data.page_loaded_at
+// from the extension, else the payload timestamp, else now
+func payloadPageTime(payload ExtensionDataPayload) time.Time {
+	if loaded, ok := parseTime(payload.Data["page_loaded_at"]); ok {
+		return loaded
+	}
+	if sent, ok := parseTime(payload.Timestamp); ok {
+		return sent
+	}
+	return time.Now()
 }
 
 // ReceiverResponse represents the response to extension data
@@ -529,58 +897,81 @@ func (h *APIHandlers) AssessHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	if !h.receiverOriginAllowed(r) {
+		h.receiverLogger.Warn().Str("origin", r.Header.Get("Origin")).Msg("Assessment origin not allowed")
+		respondError(w, r, http.StatusForbidden, "Origin not allowed")
 		return
 	}
 
+	h.limitPayload(w, r)
+
 	var payload AssessPagePayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		h.logger.Error().Err(err).Msg("Failed to decode assessment payload")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Invalid payload format",
-		})
+		if payloadTooLarge(err) {
+			respondError(w, r, http.StatusRequestEntityTooLarge, "Payload too large")
+			return
+		}
+		h.receiverLogger.Error().Err(err).Msg("Failed to decode assessment payload")
+		respondError(w, r, http.StatusBadRequest, "Invalid payload format")
 		return
 	}
 
-	h.logger.Info().
+	h.receiverLogger.Info().
 		Str("url", payload.URL).
 		Msg("Assessing page type")
 
 	// Use assessor service to analyze page
-	assessment, err := h.assessor.AssessPage(payload.HTML, payload.URL)
+	ctx, cancel := h.parseContext(r.Context())
+	defer cancel()
+	assessment, err := h.assessor.AssessPage(ctx, payload.HTML, payload.URL)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			h.receiverLogger.Warn().Str("url", payload.URL).Msg("Parse timeout reached, assessment stopped")
+			respondError(w, r, http.StatusRequestTimeout, fmt.Sprintf("Assessment stopped after %ds", h.config.Receiver.ParseTimeoutSeconds))
+		}
+		return
+	}
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to assess page")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to assess page",
-		})
+		h.receiverLogger.Error().Err(err).Msg("Failed to assess page")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeService, "assess_page", "Failed to assess page"), h.config.IsDevelopment())
 		return
 	}
 
-	h.logger.Info().
+	h.receiverLogger.Info().
 		Str("page_type", assessment.PageType).
 		Str("confidence", assessment.Confidence).
 		Str("collectable", fmt.Sprintf("%v", assessment.Collectable)).
 		Msg("Page assessment completed")
 
+	h.recordAssessment("assess", outcomeAssessed, payload.URL, assessment)
+
 	response := map[string]interface{}{
 		"success":    true,
 		"assessment": assessment,
 		"timestamp":  time.Now(),
 	}
 
-	json.NewEncoder(w).Encode(response)
+	respondJSON(w, http.StatusOK, response)
 }
 
+// errParsedEmpty is returned by storeExtensionData when a collectable page
+// yields no projects or issues, including when the payload has no HTML
+var errParsedEmpty = errors.New("no data found in HTML")
+
 // ReceiverHandler accepts data from Chrome extension
 func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if origin := r.Header.Get("Origin"); origin != "" && len(h.config.Receiver.AllowedOrigins) > 0 {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
@@ -588,29 +979,105 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	start := time.Now()
+
+	if !h.receiverOriginAllowed(r) {
+		h.receiverLogger.Warn().Str("origin", r.Header.Get("Origin")).Msg("Receiver origin not allowed")
+		respondError(w, r, http.StatusForbidden, "Origin not allowed")
 		return
 	}
 
+	h.limitPayload(w, r)
+
 	var payload ExtensionDataPayload
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		h.logger.Error().Err(err).Msg("Failed to decode extension data")
+		status, message := http.StatusBadRequest, "Invalid payload format"
+		if payloadTooLarge(err) {
+			status, message = http.StatusRequestEntityTooLarge, "Payload too large"
+		}
+		h.receiverLogger.Error().Err(err).Msg("Failed to decode extension data")
+		h.errorTracker.Record("receiver", common.WrapError(err, common.ErrorTypeValidation, "invalid_payload", message))
 		response := ReceiverResponse{
 			Success:   false,
-			Message:   "Invalid payload format",
+			Message:   message,
 			Error:     err.Error(),
 			Timestamp: time.Now(),
 		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, status, response)
+		return
+	}
+
+	if r.URL.Query().Get("force") != "true" {
+		payload.pageTime = payloadPageTime(payload)
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	w = rec
+	defer h.recordExtensionRequest(payload.Collector.Version, r, rec)
+
+	if h.extensionBlocked(payload.Collector.Version) {
+		minimum := h.config.Receiver.MinExtensionVersion
+		h.receiverLogger.Warn().
+			Str("version", payload.Collector.Version).
+			Str("min_version", minimum).
+			Str("url", payload.URL).
+			Msg("Rejected payload from outdated extension")
+		respondJSON(w, http.StatusUpgradeRequired, ReceiverResponse{
+			Success:   false,
+			Message:   fmt.Sprintf("Extension version %s is no longer supported, please update the extension to %s or later", payload.Collector.Version, minimum),
+			Error:     "extension_outdated",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	// Refuse the payload before parsing it while a storage limit is reached
+	if capacity, err := h.storage.Capacity(); err == nil && capacity.Exceeded {
+		h.receiverLogger.Warn().
+			Str("reason", capacity.Reason).
+			Str("url", payload.URL).
+			Msg("Rejected payload, storage limit reached")
+		respondJSON(w, http.StatusInsufficientStorage, ReceiverResponse{
+			Success:   false,
+			Message:   "Storage limit reached: " + capacity.Reason,
+			Error:     "storage_full",
+			Timestamp: time.Now(),
+		})
 		return
 	}
 
-	// Generate transaction ID for tracking
+	// Generate transaction ID for tracking. Every log entry for this request,
+	// including the parser's, carries it.
 	transactionID := fmt.Sprintf("txn-%d", time.Now().UnixNano())
+	logger := common.WithTransaction(h.receiverLogger, transactionID)
+	ctx := common.ContextWithTransaction(r.Context(), transactionID)
+
+	// Use page assessor to intelligently determine page type and processability
+	htmlContent := ""
+	if content, ok := payload.Data["html"].(string); ok {
+		htmlContent = content
+	}
+	measurements := &receiverMeasurements{htmlBytes: len(htmlContent)}
 
-	h.logger.Info().
-		Str("transaction_id", transactionID).
+	payloadHash := h.payloadHash(payload.URL, htmlContent)
+	if h.isDuplicatePayload(payloadHash) {
+		logger.Info().
+			Str("url", payload.URL).
+			Msg("Duplicate payload ignored")
+		respondJSON(w, http.StatusOK, ReceiverResponse{
+			Success:       true,
+			Message:       "Duplicate payload ignored",
+			Timestamp:     time.Now(),
+			TransactionID: transactionID,
+		})
+		return
+	}
+
+	logger.Info().
 		Str("url", payload.URL).
 		Str("collector", payload.Collector.Name).
 		Str("version", payload.Collector.Version).
@@ -626,15 +1093,34 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Use page assessor to intelligently determine page type and processability
-	htmlContent := ""
-	if html, ok := payload.Data["html"].(string); ok {
-		htmlContent = html
+	// Assessment and parsing stop once the client disconnects or
+	// receiver.parse_timeout_seconds passes
+	parseCtx, cancel := h.parseContext(ctx)
+	defer cancel()
+
+	// Build the HTML tree once; the assessor and the parser share it
+	parseStart := time.Now()
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	measurements.parseDuration = time.Since(parseStart)
+	if ctxErr := parseCtx.Err(); ctxErr != nil {
+		h.recordReceiverMeasurements(logger, "unknown", measurements, time.Since(start))
+		h.respondParseStopped(w, logger, payload, &parseStopped{phase: phaseHTMLParse, err: ctxErr}, "unknown", transactionID, len(htmlContent))
+		return
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to parse HTML, assessing by URL only")
+		doc = nil
 	}
 
-	assessment, err := h.assessor.AssessPage(htmlContent, payload.URL)
+	assessment, err := h.assessor.AssessDocument(parseCtx, doc, payload.URL)
+	if ctxErr := parseCtx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		h.recordReceiverMeasurements(logger, "unknown", measurements, time.Since(start))
+		h.respondParseStopped(w, logger, payload, &parseStopped{phase: phaseAssess, err: ctxErr}, "unknown", transactionID, len(htmlContent))
+		return
+	}
 	if err != nil {
-		h.logger.Warn().Err(err).Msg("Failed to assess page, will attempt processing anyway")
+		logger.Warn().Err(err).Msg("Failed to assess page, will attempt processing anyway")
+		h.errorTracker.Record("parser", common.WrapError(err, common.ErrorTypeCollection, "assess_page", "Failed to assess page"))
 		assessment = &models.PageAssessment{
 			PageType:    "unknown",
 			Confidence:  "low",
@@ -642,7 +1128,11 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	h.logger.Info().
+	assessedEvent := logger.Info()
+	if !assessment.Collectable && !h.config.Receiver.LogNonCollectable {
+		assessedEvent = logger.Debug()
+	}
+	assessedEvent.
 		Str("page_type", assessment.PageType).
 		Str("confidence", assessment.Confidence).
 		Str("collectable", fmt.Sprintf("%v", assessment.Collectable)).
@@ -651,6 +1141,9 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 
 	// If not collectable, return early with info
 	if !assessment.Collectable {
+		h.recordReceiverMeasurements(logger, assessment.PageType, measurements, time.Since(start))
+		h.recordAssessment("receiver", outcomeSkipped, payload.URL, assessment)
+
 		// Broadcast non-collectable status
 		if h.wsHub != nil {
 			h.wsHub.SendCollectionUpdate("collection_skipped", map[string]interface{}{
@@ -673,15 +1166,28 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 				"assessment": assessment,
 			},
 		}
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, http.StatusOK, response)
 		return
 	}
 
 	// Store the received data and get response data with stats
-	responseData, stats, err := h.storeExtensionDataWithStats(payload, assessment.PageType, transactionID)
+	responseData, stats, err := h.storeExtensionDataWithStats(parseCtx, payload, doc, assessment.PageType, transactionID, measurements)
+	h.recordReceiverMeasurements(logger, assessment.PageType, measurements, time.Since(start))
+	var stopped *parseStopped
+	if errors.As(err, &stopped) {
+		h.recordAssessment("receiver", outcomeFailed, payload.URL, assessment)
+		h.respondParseStopped(w, logger, payload, stopped, assessment.PageType, transactionID, len(htmlContent))
+		return
+	}
+	if errors.Is(err, errParsedEmpty) {
+		h.recordAssessment("receiver", outcomeEmpty, payload.URL, assessment)
+		h.respondParsedEmpty(w, payload, assessment.PageType, transactionID, len(htmlContent))
+		return
+	}
 	if err != nil {
-		h.logger.Error().
-			Str("transaction_id", transactionID).
+		h.recordAssessment("receiver", outcomeFailed, payload.URL, assessment)
+		h.notifyCollection(models.EventRunFailed, payload.URL, assessment.PageType, transactionID, nil, err)
+		logger.Error().
 			Err(err).
 			Msg("Failed to store extension data")
 
@@ -695,6 +1201,10 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 			})
 		}
 
+		status := http.StatusInternalServerError
+		if errors.Is(err, common.ErrStorageFull) {
+			status = http.StatusInsufficientStorage
+		}
 		response := ReceiverResponse{
 			Success:       false,
 			Message:       "Failed to store data",
@@ -703,11 +1213,17 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 			PageType:      assessment.PageType,
 			TransactionID: transactionID,
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
+		respondJSON(w, status, response)
 		return
 	}
 
+	h.recordPayload(payloadHash)
+	h.recordAssessment("receiver", outcomeCollected, payload.URL, assessment)
+	h.notifyCollection(models.EventRunCompleted, payload.URL, assessment.PageType, transactionID, stats, nil)
+	if stats != nil && stats.TicketsAdded > 0 {
+		h.notifyCollection(models.EventTicketsAddedThreshold, payload.URL, assessment.PageType, transactionID, stats, nil)
+	}
+
 	// Build success message with stats
 	successMsg := fmt.Sprintf("Successfully processed %s page", assessment.PageType)
 	if stats != nil {
@@ -729,8 +1245,7 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 		Stats:         stats,
 	}
 
-	h.logger.Info().
-		Str("transaction_id", transactionID).
+	logger.Info().
 		Str("page_type", assessment.PageType).
 		Int("projects_added", stats.ProjectsAdded).
 		Int("tickets_added", stats.TicketsAdded).
@@ -747,45 +1262,23 @@ func (h *APIHandlers) ReceiverHandler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	json.NewEncoder(w).Encode(response)
+	respondJSON(w, http.StatusOK, response)
 }
 
-// makeAbsoluteURL converts a relative URL to an absolute URL using the base page URL
-func (h *APIHandlers) makeAbsoluteURL(relativeURL, baseURL string) string {
-	// If already absolute, return as-is
-	if strings.HasPrefix(relativeURL, "http://") || strings.HasPrefix(relativeURL, "https://") {
-		return relativeURL
-	}
-
-	// Parse base URL to get scheme and host
-	// Extract scheme and host from baseURL (e.g., https://company.atlassian.net)
-	if strings.HasPrefix(baseURL, "http://") || strings.HasPrefix(baseURL, "https://") {
-		// Find the end of scheme://host
-		schemeEnd := strings.Index(baseURL, "://")
-		if schemeEnd == -1 {
-			return relativeURL // Fallback
-		}
-		hostStart := schemeEnd + 3
-		pathStart := strings.Index(baseURL[hostStart:], "/")
-		if pathStart == -1 {
-			// No path, entire remaining is host
-			return baseURL + relativeURL
-		}
-		baseHost := baseURL[:hostStart+pathStart]
-		return baseHost + relativeURL
-	}
-
-	return relativeURL
-}
+// storeExtensionData stores data received from the extension and returns
+// response data. doc is the page's parsed HTML when the caller has it. The
+// projects and tickets that were new are added to stats. Parsing stops with
+// a *parseStopped error once ctx is done; nothing is stored then.
+func (h *APIHandlers) storeExtensionData(ctx context.Context, payload ExtensionDataPayload, doc *html.Node, assessedPageType string, transactionID string, measurements *receiverMeasurements, stats *CollectionStats) (interface{}, error) {
+	logger := common.WithTransaction(h.receiverLogger, transactionID)
+	parserLogger := common.WithTransaction(h.parserLogger, transactionID)
 
-// storeExtensionData stores data received from the extension and returns response data
-func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedPageType string) (interface{}, error) {
 	pageType := assessedPageType
 	if pageType == "" {
 		pageType = "unknown"
 	}
 
-	h.logger.Debug().
+	parserLogger.Debug().
 		Str("page_type", pageType).
 		Str("url", payload.URL).
 		Msg("Processing extension data with server-side HTML parsing")
@@ -793,20 +1286,42 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 	// Get HTML content
 	htmlContent, ok := payload.Data["html"].(string)
 	if !ok || htmlContent == "" {
-		h.logger.Warn().Msg("No HTML content in payload")
-		return nil, nil
+		parserLogger.Warn().
+			Str("page_type", pageType).
+			Str("url", payload.URL).
+			Msg("No HTML content in payload")
+		h.errorTracker.Record("parser", common.NewCollectionError("parsed_empty", "No HTML in "+pageType+" page payload"))
+		return nil, errParsedEmpty
+	}
+
+	rawHTML := ""
+	if h.config.Receiver.StoreRawHTML {
+		rawHTML = htmlContent
 	}
 
 	// Parse HTML on server side
 	parser := NewJiraParser()
-	results, err := parser.ParseHTML(htmlContent, pageType, payload.URL)
+	parseStart := time.Now()
+	var results []map[string]interface{}
+	var err error
+	if doc != nil {
+		results, err = parser.ParseDocument(ctx, doc, pageType, payload.URL)
+	} else {
+		results, err = parser.ParseHTML(ctx, htmlContent, pageType, payload.URL)
+	}
+	measurements.parseDuration += time.Since(parseStart)
+	measurements.entities = len(results)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil, &parseStopped{phase: phaseParse, err: err}
+	}
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to parse HTML")
+		parserLogger.Error().Err(err).Msg("Failed to parse HTML")
+		h.errorTracker.Record("parser", common.WrapError(err, common.ErrorTypeCollection, "parse_html", "Failed to parse HTML"))
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
 	if len(results) == 0 {
-		h.logger.Warn().
+		parserLogger.Warn().
 			Str("page_type", pageType).
 			Str("url", payload.URL).
 			Int("html_size", len(htmlContent)).
@@ -817,11 +1332,12 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 		if len(snippet) > 1000 {
 			snippet = snippet[:1000]
 		}
-		h.logger.Debug().
+		parserLogger.Debug().
 			Str("html_snippet", snippet).
 			Msg("HTML content preview for debugging")
 
-		return nil, nil
+		h.errorTracker.Record("parser", common.NewCollectionError("parsed_empty", "No data found in "+pageType+" page"))
+		return nil, errParsedEmpty
 	}
 
 	// Handle based on page type
@@ -851,7 +1367,7 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 			}
 			if url, ok := projectMap["url"].(string); ok {
 				// Convert relative URL to absolute URL
-				project.URL = h.makeAbsoluteURL(url, payload.URL)
+				project.URL = common.ResolveURL(payload.URL, url)
 			}
 			if desc, ok := projectMap["description"].(string); ok {
 				project.Description = desc
@@ -861,8 +1377,13 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 			}
 		}
 		if len(projects) > 0 {
-			h.logger.Info().Int("project_count", len(projects)).Msg("Storing projects")
-			if err := h.storage.SaveProjects(projects); err != nil {
+			logger.Info().Int("project_count", len(projects)).Msg("Storing projects")
+			storageStart := time.Now()
+			added, err := h.storage.SaveProjects(projects)
+			measurements.storageDuration = time.Since(storageStart)
+			stats.ProjectsAdded += added
+			if err != nil {
+				h.errorTracker.Record("storage", common.WrapError(err, common.ErrorTypeStorage, "save_projects", "Failed to save projects"))
 				return nil, fmt.Errorf("failed to save projects: %w", err)
 			}
 		}
@@ -881,30 +1402,39 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 		return projectResponses, nil
 	}
 
-	// Check if extension already extracted tickets (from DOM)
+	// Check if extension already extracted tickets (from DOM). Those replace
+	// the parsed issues, with bare keys marked and capped the same way.
 	if ticketsData, ok := payload.Data["tickets"].([]interface{}); ok && len(ticketsData) > 0 {
-		h.logger.Info().Int("ticket_count", len(ticketsData)).Msg("Using pre-extracted tickets from extension")
+		logger.Info().Int("ticket_count", len(ticketsData)).Msg("Using pre-extracted tickets from extension")
+		measurements.entities = len(ticketsData)
+		results = preExtractedIssues(ticketsData)
+	} else {
+		// For issue pages, store as tickets
+		logger.Info().Int("issue_count", len(results)).Msg("Extracted issues from HTML")
+	}
 
-		err = h.storeIssuesArray(ticketsData, payload.Timestamp)
-		if err != nil {
-			return nil, err
+	if limit := h.config.Receiver.MaxReferenceTickets; limit > 0 {
+		var dropped int
+		results, dropped = capReferenceIssues(results, limit)
+		if dropped > 0 {
+			logger.Warn().
+				Str("page_type", pageType).
+				Int("limit", limit).
+				Int("dropped", dropped).
+				Msg("Dropped reference tickets over receiver.max_reference_tickets")
 		}
-
-		return map[string]interface{}{
-			"tickets_collected": len(ticketsData),
-		}, nil
 	}
 
-	// For issue pages, store as tickets
-	h.logger.Info().Int("issue_count", len(results)).Msg("Extracted issues from HTML")
-
 	// Convert parsed issues to interface array and store
 	issuesArray := make([]interface{}, len(results))
 	for i, issue := range results {
 		issuesArray[i] = issue
 	}
 
-	err = h.storeIssuesArray(issuesArray, payload.Timestamp)
+	storageStart := time.Now()
+	added, err := h.storeIssuesArray(issuesArray, payload.Timestamp, payload.pageTime, rawHTML, transactionID)
+	measurements.storageDuration = time.Since(storageStart)
+	stats.TicketsAdded += added
 	if err != nil {
 		return nil, err
 	}
@@ -915,32 +1445,74 @@ func (h *APIHandlers) storeExtensionData(payload ExtensionDataPayload, assessedP
 	}, nil
 }
 
-// storeExtensionDataWithStats wraps storeExtensionData and calculates collection statistics
-func (h *APIHandlers) storeExtensionDataWithStats(payload ExtensionDataPayload, assessedPageType string, transactionID string) (interface{}, *CollectionStats, error) {
-	// Get counts before processing
-	projectsBefore, _ := h.storage.LoadProjects()
-	ticketsBefore, _ := h.storage.LoadAllTickets()
+// preExtractedIssues returns the issue objects of a tickets array posted by
+// the extension. An issue carrying nothing but its key and location was only
+// seen in passing, so it is marked as a reference like the key-only issues
+// of board and generic pages.
+func preExtractedIssues(ticketsData []interface{}) []map[string]interface{} {
+	issues := make([]map[string]interface{}, 0, len(ticketsData))
+	for _, item := range ticketsData {
+		issue, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if keyOnlyIssue(issue) {
+			issue["source"] = models.SourceReference
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// keyOnlyIssue reports whether an issue has no fields beyond its key, project,
+// URL and source
+func keyOnlyIssue(issue map[string]interface{}) bool {
+	for field, value := range issue {
+		switch field {
+		case "key", "project_id", "url", "source":
+			continue
+		}
+		if value != nil && value != "" {
+			return false
+		}
+	}
+	return true
+}
 
-	// Store the data
-	responseData, err := h.storeExtensionData(payload, assessedPageType)
+// capReferenceIssues keeps at most limit issues marked as references and
+// returns how many were dropped. Other issues are always kept.
+func capReferenceIssues(issues []map[string]interface{}, limit int) ([]map[string]interface{}, int) {
+	kept := make([]map[string]interface{}, 0, len(issues))
+	references, dropped := 0, 0
+	for _, issue := range issues {
+		if source, _ := issue["source"].(string); source == models.SourceReference {
+			if references >= limit {
+				dropped++
+				continue
+			}
+			references++
+		}
+		kept = append(kept, issue)
+	}
+	return kept, dropped
+}
+
+// storeExtensionDataWithStats wraps storeExtensionData and calculates collection statistics
+func (h *APIHandlers) storeExtensionDataWithStats(ctx context.Context, payload ExtensionDataPayload, doc *html.Node, assessedPageType string, transactionID string, measurements *receiverMeasurements) (interface{}, *CollectionStats, error) {
+	// Storage counts the new projects and tickets inside its write
+	// transactions, so the added counts are exact under concurrent requests
+	stats := &CollectionStats{}
+	responseData, err := h.storeExtensionData(ctx, payload, doc, assessedPageType, transactionID, measurements, stats)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Get counts after processing
-	projectsAfter, _ := h.storage.LoadProjects()
-	ticketsAfter, _ := h.storage.LoadAllTickets()
-
-	// Calculate statistics
-	stats := &CollectionStats{
-		ProjectsAdded: len(projectsAfter) - len(projectsBefore),
-		ProjectsTotal: len(projectsAfter),
-		TicketsAdded:  len(ticketsAfter) - len(ticketsBefore),
-		TicketsTotal:  len(ticketsAfter),
+	if projectsTotal, ticketsTotal, err := h.storage.CountStored(); err == nil {
+		stats.ProjectsTotal = projectsTotal
+		stats.TicketsTotal = ticketsTotal
 	}
 
-	h.logger.Debug().
-		Str("transaction_id", transactionID).
+	common.WithTransaction(h.receiverLogger, transactionID).Debug().
 		Int("projects_added", stats.ProjectsAdded).
 		Int("projects_total", stats.ProjectsTotal).
 		Int("tickets_added", stats.TicketsAdded).
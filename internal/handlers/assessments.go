@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+const (
+	// defaultAssessmentLimit is the number of entries GET /assessments returns
+	// without a limit parameter
+	defaultAssessmentLimit = 100
+	// Assessment outcomes recorded in the history
+	outcomeAssessed  = "assessed"
+	outcomeCollected = "collected"
+	outcomeSkipped   = "skipped"
+	outcomeEmpty     = "empty"
+	outcomeFailed    = "failed"
+)
+
+var assessmentOutcomes = []string{outcomeAssessed, outcomeCollected, outcomeSkipped, outcomeEmpty, outcomeFailed}
+
+// recordAssessment adds an assessment to the history. Failures are logged
+// and otherwise ignored so diagnostics never fail a request.
+func (h *APIHandlers) recordAssessment(source, outcome, pageURL string, assessment *models.PageAssessment) {
+	if h.config.Storage.ReadOnly {
+		return
+	}
+
+	record := &models.AssessmentRecord{
+		Timestamp:   time.Now(),
+		Source:      source,
+		URL:         common.URLHostPath(pageURL),
+		PageType:    assessment.PageType,
+		Confidence:  assessment.Confidence,
+		Indicators:  assessment.Indicators,
+		Collectable: assessment.Collectable,
+		Outcome:     outcome,
+	}
+	if err := h.storage.AppendAssessment(record); err != nil {
+		h.logger.Warn().Err(err).Str("url", record.URL).Msg("Failed to record page assessment")
+	}
+}
+
+// AssessmentsHandler returns the page assessment history, newest first. The
+// page_type and outcome query parameters filter it and limit caps the
+// number of entries returned.
+func (h *APIHandlers) AssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	params := r.URL.Query()
+	query := models.AssessmentQuery{
+		PageType: params.Get("page_type"),
+		Outcome:  params.Get("outcome"),
+		Limit:    defaultAssessmentLimit,
+	}
+
+	if query.Outcome != "" && !slices.Contains(assessmentOutcomes, query.Outcome) {
+		respondError(w, r, http.StatusBadRequest, "Invalid outcome: must be one of assessed, collected, skipped, empty or failed")
+		return
+	}
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			respondError(w, r, http.StatusBadRequest, "Invalid limit: must be a positive integer")
+			return
+		}
+		query.Limit = limit
+	}
+
+	records, err := h.storage.LoadAssessments(query)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load page assessments")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_assessments", "Failed to load page assessments"), h.config.IsDevelopment())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"assessments": records,
+		"count":       len(records),
+		"history":     h.config.Storage.AssessmentHistory,
+	})
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+)
+
+const (
+	// backfillSource marks tickets filled in by /backfill, so a ticket Jira
+	// has no description for is not fetched again by every run
+	backfillSource = "backfill"
+	// backfillProgressEvery is how many tickets are processed between
+	// backfill_progress WebSocket events
+	backfillProgressEvery = 25
+	// maxBackfillFailures bounds the failed keys kept in BackfillStatus
+	maxBackfillFailures = 100
+)
+
+// Backfill outcomes for one ticket, sent in backfill_ticket events
+const (
+	backfillFilled   = "filled"
+	backfillNotFound = "not_found"
+	backfillFailed   = "failed"
+)
+
+// BackfillStatus reports the progress of a run that fetches missing ticket
+// details from the Jira API. Error is set when the run stopped early because
+// Jira rejected the credentials or could not be reached.
+type BackfillStatus struct {
+	Running    bool       `json:"running"`
+	Project    string     `json:"project,omitempty"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Filled     int        `json:"filled"`
+	NotFound   int        `json:"not_found"`
+	Failed     int        `json:"failed"`
+	FailedKeys []string   `json:"failed_keys,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// backfillTicketEvent is the backfill_ticket WebSocket event for one ticket
+type backfillTicketEvent struct {
+	Key     string `json:"key"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// SetIssueFetcher sets the Jira client /backfill fetches issues with
+func (h *APIHandlers) SetIssueFetcher(fetcher interfaces.IssueFetcher) {
+	h.backfillMu.Lock()
+	defer h.backfillMu.Unlock()
+	h.issueFetcher = fetcher
+}
+
+// BackfillHandler fills in tickets stored without a summary or description,
+// such as the key-only tickets of list, board and generic pages, from the
+// Jira API. POST starts a run in the background, optionally limited by the
+// project query parameter, and GET returns the progress of the latest run.
+// Each ticket's outcome is broadcast as a backfill_ticket WebSocket event,
+// with backfill_progress and backfill_complete events for the run.
+// Requests are spaced by jira.requests_per_second. A run only picks up
+// tickets still missing details, so one stopped part way resumes where it
+// left off when started again.
+func (h *APIHandlers) BackfillHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.backfillMu.Lock()
+		var status *BackfillStatus
+		if h.backfill != nil {
+			snapshot := *h.backfill
+			status = &snapshot
+		}
+		h.backfillMu.Unlock()
+
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"backfill": status,
+		})
+	case http.MethodPost:
+		h.startBackfill(w, r)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *APIHandlers) startBackfill(w http.ResponseWriter, r *http.Request) {
+	project := strings.ToUpper(r.URL.Query().Get("project"))
+
+	h.backfillMu.Lock()
+	fetcher := h.issueFetcher
+	running := h.backfill != nil && h.backfill.Running
+	h.backfillMu.Unlock()
+	if fetcher == nil || !h.config.Jira.HasCredentials() {
+		respondError(w, r, http.StatusServiceUnavailable, "Backfill needs jira base_url, api.username and api.api_token to be configured")
+		return
+	}
+	if running {
+		respondError(w, r, http.StatusConflict, "Backfill is already running")
+		return
+	}
+
+	tickets, err := h.backfillCandidates(project)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load tickets for backfill")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	status := &BackfillStatus{
+		Running:   true,
+		Project:   project,
+		Total:     len(tickets),
+		StartedAt: time.Now(),
+	}
+
+	h.backfillMu.Lock()
+	if h.backfill != nil && h.backfill.Running {
+		h.backfillMu.Unlock()
+		respondError(w, r, http.StatusConflict, "Backfill is already running")
+		return
+	}
+	h.backfill = status
+	snapshot := *status
+	h.backfillMu.Unlock()
+
+	h.logger.Info().
+		Str("project", project).
+		Int("tickets", len(tickets)).
+		Msg("Backfilling ticket details from Jira")
+
+	go h.runBackfill(fetcher, tickets)
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success":  true,
+		"message":  fmt.Sprintf("Backfilling %d ticket(s)", len(tickets)),
+		"backfill": snapshot,
+	})
+}
+
+// backfillCandidates returns the stored tickets missing a summary or
+// description, in key order, leaving out disabled projects and tickets a
+// backfill already fetched
+func (h *APIHandlers) backfillCandidates(project string) ([]*models.TicketData, error) {
+	var stored map[string]*models.TicketData
+	var err error
+	if project != "" {
+		stored, err = h.storage.LoadTickets(project)
+	} else {
+		stored, err = h.storage.LoadAllTickets()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := h.storage.LoadProjects()
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[string]bool)
+	for _, p := range projects {
+		if p.Disabled {
+			disabled[p.Key] = true
+		}
+	}
+
+	tickets := make([]*models.TicketData, 0)
+	for _, ticket := range stored {
+		if ticket.Source == backfillSource || (ticket.Summary != "" && ticket.Description != "") {
+			continue
+		}
+		if projectKey, ok := common.IssueKeyProject(ticket.Key); !ok || disabled[projectKey] {
+			continue
+		}
+		tickets = append(tickets, ticket)
+	}
+	slices.SortFunc(tickets, func(a, b *models.TicketData) int {
+		return common.CompareIssueKeys(a.Key, b.Key)
+	})
+	return tickets, nil
+}
+
+func (h *APIHandlers) runBackfill(fetcher interfaces.IssueFetcher, tickets []*models.TicketData) {
+	var interval time.Duration
+	if rate := h.config.Jira.RequestsPerSecond; rate > 0 {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	var stopErr error
+	var lastRequest time.Time
+	for i, ticket := range tickets {
+		if wait := time.Until(lastRequest.Add(interval)); wait > 0 {
+			time.Sleep(wait)
+		}
+		lastRequest = time.Now()
+
+		outcome, err := h.backfillTicket(fetcher, ticket)
+
+		h.backfillMu.Lock()
+		status := h.backfill
+		status.Processed++
+		switch outcome {
+		case backfillFilled:
+			status.Filled++
+		case backfillNotFound:
+			status.NotFound++
+		default:
+			status.Failed++
+			if len(status.FailedKeys) < maxBackfillFailures {
+				status.FailedKeys = append(status.FailedKeys, ticket.Key)
+			}
+		}
+		snapshot := *status
+		h.backfillMu.Unlock()
+
+		event := backfillTicketEvent{Key: ticket.Key, Outcome: outcome}
+		if err != nil {
+			event.Error = err.Error()
+			h.logger.Warn().Err(err).Str("key", ticket.Key).Msg("Failed to backfill ticket")
+		}
+		if h.wsHub != nil {
+			h.wsHub.SendCollectionUpdate("backfill_ticket", event)
+			if (i+1)%backfillProgressEvery == 0 {
+				h.wsHub.SendCollectionUpdate("backfill_progress", snapshot)
+			}
+		}
+
+		// Every later request would fail the same way
+		if backfillStops(err) {
+			stopErr = err
+			break
+		}
+	}
+
+	finished := time.Now()
+	h.backfillMu.Lock()
+	h.backfill.Running = false
+	h.backfill.FinishedAt = &finished
+	if stopErr != nil {
+		h.backfill.Error = stopErr.Error()
+	}
+	snapshot := *h.backfill
+	h.backfillMu.Unlock()
+
+	logEvent := h.logger.Info()
+	if stopErr != nil {
+		logEvent = h.logger.Warn().Err(stopErr)
+	}
+	logEvent.
+		Int("filled", snapshot.Filled).
+		Int("not_found", snapshot.NotFound).
+		Int("failed", snapshot.Failed).
+		Int("remaining", snapshot.Total-snapshot.Processed).
+		Dur("duration", finished.Sub(snapshot.StartedAt)).
+		Msg("Backfill completed")
+
+	if h.wsHub != nil {
+		h.wsHub.SendCollectionUpdate("backfill_complete", snapshot)
+	}
+}
+
+// backfillStops reports whether err ends the run: Jira rejected the
+// credentials, could not be reached, or access is not configured
+func backfillStops(err error) bool {
+	var collectorErr *common.CollectorError
+	if !errors.As(err, &collectorErr) {
+		return false
+	}
+	switch collectorErr.Type {
+	case common.ErrorTypeAuth, common.ErrorTypeNetwork, common.ErrorTypeConfiguration:
+		return true
+	}
+	return false
+}
+
+// backfillTicket fetches one ticket from Jira and merges it into the stored
+// ticket, returning the outcome
+func (h *APIHandlers) backfillTicket(fetcher interfaces.IssueFetcher, ticket *models.TicketData) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(h.config.Jira.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	fetched, err := fetcher.FetchIssue(ctx, ticket.Key)
+	if err != nil {
+		return backfillFailed, err
+	}
+	if fetched == nil {
+		return backfillNotFound, nil
+	}
+
+	projectKey, _ := common.IssueKeyProject(ticket.Key)
+	fetched.Key = ticket.Key
+	fetched.ProjectID = projectKey
+	fetched.Source = backfillSource
+	if _, err := h.storage.SaveTickets(projectKey, map[string]*models.TicketData{ticket.Key: fetched}); err != nil {
+		h.errorTracker.Record("storage", common.WrapError(err, common.ErrorTypeStorage, "save_tickets", "Failed to save backfilled ticket "+ticket.Key))
+		return backfillFailed, err
+	}
+	return backfillFilled, nil
+}
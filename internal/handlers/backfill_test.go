@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/models"
+)
+
+// fakeIssueFetcher serves issues from a map, recording the keys requested
+type fakeIssueFetcher struct {
+	mu        sync.Mutex
+	issues    map[string]models.TicketData
+	err       error
+	requested []string
+}
+
+func (f *fakeIssueFetcher) FetchIssue(ctx context.Context, key string) (*models.TicketData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requested = append(f.requested, key)
+	if f.err != nil {
+		return nil, f.err
+	}
+	issue, ok := f.issues[key]
+	if !ok {
+		return nil, nil
+	}
+	return &issue, nil
+}
+
+func withJiraCredentials(config *common.Config) {
+	config.Jira.BaseURL = "https://example.atlassian.net"
+	config.Jira.API.Username = "me@example.com"
+	config.Jira.API.APIToken = "secret"
+	config.Jira.RequestsPerSecond = 0
+}
+
+// runBackfill starts a backfill and waits for it to finish
+func runBackfill(t *testing.T, c *testCollector, query string) handlers.BackfillStatus {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.api.BackfillHandler(rec, httptest.NewRequest(http.MethodPost, "/backfill"+query, nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /backfill status = %d: %s", rec.Code, rec.Body)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		c.api.BackfillHandler(rec, httptest.NewRequest(http.MethodGet, "/backfill", nil))
+		var response struct {
+			Backfill handlers.BackfillStatus `json:"backfill"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		if !response.Backfill.Running {
+			return response.Backfill
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backfill still running: %+v", response.Backfill)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBackfillFillsSkeletonTickets(t *testing.T) {
+	c := newTestCollector(t, withJiraCredentials)
+
+	stored := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Status: "Open"},
+		"ABC-2": {Key: "ABC-2", ProjectID: "ABC", Summary: "Deleted in Jira"},
+		"ABC-3": {Key: "ABC-3", ProjectID: "ABC", Summary: "Complete", Description: "Already collected"},
+	}
+	if _, err := c.storage.SaveTickets("ABC", stored); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	fetcher := &fakeIssueFetcher{issues: map[string]models.TicketData{
+		"ABC-1": {Key: "ABC-1", Summary: "Login fails", Description: "Steps to reproduce", Priority: "High"},
+	}}
+	c.api.SetIssueFetcher(fetcher)
+
+	status := runBackfill(t, c, "")
+	if status.Total != 2 || status.Filled != 1 || status.NotFound != 1 || status.Failed != 0 || status.Error != "" {
+		t.Errorf("status = %+v", status)
+	}
+
+	tickets, err := c.storage.LoadTickets("ABC")
+	if err != nil {
+		t.Fatalf("LoadTickets: %v", err)
+	}
+	filled := tickets["ABC-1"]
+	if filled.Summary != "Login fails" || filled.Description != "Steps to reproduce" || filled.Priority != "High" {
+		t.Errorf("ABC-1 = %+v, want the fetched details", filled)
+	}
+	if filled.Status != "Open" {
+		t.Errorf("ABC-1 status = %q, want the stored status kept", filled.Status)
+	}
+
+	// Filled tickets are not fetched again; the missing one is retried
+	fetcher.requested = nil
+	if status := runBackfill(t, c, "?project=abc"); status.Total != 1 || status.NotFound != 1 {
+		t.Errorf("second run status = %+v", status)
+	}
+	if len(fetcher.requested) != 1 || fetcher.requested[0] != "ABC-2" {
+		t.Errorf("second run requested %v, want ABC-2", fetcher.requested)
+	}
+}
+
+func TestBackfillStopsOnRejectedCredentials(t *testing.T) {
+	c := newTestCollector(t, withJiraCredentials)
+
+	stored := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC"},
+		"ABC-2": {Key: "ABC-2", ProjectID: "ABC"},
+	}
+	if _, err := c.storage.SaveTickets("ABC", stored); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	c.api.SetIssueFetcher(&fakeIssueFetcher{err: common.NewAuthError("jira_credentials_rejected", "jira rejected the credentials")})
+
+	status := runBackfill(t, c, "")
+	if status.Processed != 1 || status.Failed != 1 || status.Error == "" {
+		t.Errorf("status = %+v, want the run stopped after the first ticket", status)
+	}
+}
+
+func TestBackfillNeedsJiraCredentials(t *testing.T) {
+	c := newTestCollector(t, nil)
+	c.api.SetIssueFetcher(&fakeIssueFetcher{})
+
+	rec := httptest.NewRecorder()
+	c.api.BackfillHandler(rec, httptest.NewRequest(http.MethodPost, "/backfill", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("POST /backfill status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+)
+
+// maxRecentErrors is the number of errors kept for GET /errors
+const maxRecentErrors = 50
+
+// TrackedError is a single error reported to the ErrorTracker
+type TrackedError struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Component string           `json:"component"`
+	Type      common.ErrorType `json:"type"`
+	Code      string           `json:"code,omitempty"`
+	Message   string           `json:"message"`
+}
+
+// ErrorSummary is a snapshot of the tracked errors
+type ErrorSummary struct {
+	Total  uint64                      `json:"total"`
+	Counts map[common.ErrorType]uint64 `json:"counts"`
+	Recent []TrackedError              `json:"recent"` // newest first
+}
+
+// ErrorTracker counts errors by type and keeps the most recent ones so the
+// status endpoints and the UI have an aggregate view. A nil tracker ignores
+// reports.
+type ErrorTracker struct {
+	mutex    sync.Mutex
+	total    uint64
+	counts   map[common.ErrorType]uint64
+	recent   []TrackedError // ring buffer, next is the oldest entry once full
+	next     int
+	onRecord func(TrackedError)
+}
+
+// NewErrorTracker creates an empty error tracker
+func NewErrorTracker() *ErrorTracker {
+	return &ErrorTracker{
+		counts: make(map[common.ErrorType]uint64),
+		recent: make([]TrackedError, 0, maxRecentErrors),
+	}
+}
+
+// OnRecord registers fn to be called with every new error, outside the
+// tracker's lock
+func (t *ErrorTracker) OnRecord(fn func(TrackedError)) {
+	t.mutex.Lock()
+	t.onRecord = fn
+	t.mutex.Unlock()
+}
+
+// Record reports an error from component. The type and code come from a
+// wrapped CollectorError; other errors are counted as internal.
+func (t *ErrorTracker) Record(component string, err error) {
+	if t == nil || err == nil {
+		return
+	}
+
+	tracked := TrackedError{
+		Timestamp: time.Now(),
+		Component: component,
+		Type:      common.ErrorTypeInternal,
+		Message:   err.Error(),
+	}
+	var collectorErr *common.CollectorError
+	if errors.As(err, &collectorErr) {
+		tracked.Type = collectorErr.Type
+		tracked.Code = collectorErr.Code
+		tracked.Message = collectorErr.Message
+		if collectorErr.Cause != nil {
+			tracked.Message += ": " + collectorErr.Cause.Error()
+		}
+	}
+
+	t.mutex.Lock()
+	t.total++
+	t.counts[tracked.Type]++
+	if len(t.recent) < maxRecentErrors {
+		t.recent = append(t.recent, tracked)
+	} else {
+		t.recent[t.next] = tracked
+		t.next = (t.next + 1) % maxRecentErrors
+	}
+	onRecord := t.onRecord
+	t.mutex.Unlock()
+
+	if onRecord != nil {
+		onRecord(tracked)
+	}
+}
+
+// Summary returns a snapshot of the counters and recent errors
+func (t *ErrorTracker) Summary() ErrorSummary {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	counts := make(map[common.ErrorType]uint64, len(t.counts))
+	for errorType, count := range t.counts {
+		counts[errorType] = count
+	}
+
+	recent := make([]TrackedError, 0, len(t.recent))
+	for i := len(t.recent) - 1; i >= 0; i-- {
+		recent = append(recent, t.recent[(t.next+i)%len(t.recent)])
+	}
+
+	return ErrorSummary{
+		Total:  t.total,
+		Counts: counts,
+		Recent: recent,
+	}
+}
+
+// Reset clears the counters and recent errors. Types already seen stay in
+// the counts at zero so their metrics drop rather than go stale.
+func (t *ErrorTracker) Reset() {
+	t.mutex.Lock()
+	t.total = 0
+	for errorType := range t.counts {
+		t.counts[errorType] = 0
+	}
+	t.recent = t.recent[:0]
+	t.next = 0
+	t.mutex.Unlock()
+}
+
+// ErrorsHandler returns the tracked errors on GET and clears them on DELETE
+func (h *APIHandlers) ErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		response := map[string]interface{}{
+			"success": true,
+			"errors":  h.errorTracker.Summary(),
+		}
+		if err := respondJSON(w, http.StatusOK, response); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to encode errors response")
+		}
+	case http.MethodDelete:
+		h.errorTracker.Reset()
+		h.logger.Info().Msg("Error tracker reset")
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Error tracker reset",
+		})
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodDelete)
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+// maxExportColumns bounds the columns parameter
+const maxExportColumns = 100
+
+// defaultExportColumns are the CSV columns written when none are requested
+var defaultExportColumns = []string{
+	"key", "project", "type", "status", "priority", "assignee",
+	"reporter", "created", "updated", "summary",
+}
+
+// exportColumnValues reads each built-in CSV column from a ticket. Any other
+// column name is looked up in the ticket's custom fields.
+var exportColumnValues = map[string]func(*models.TicketData) string{
+	"key":          func(t *models.TicketData) string { return t.Key },
+	"project":      ticketProject,
+	"type":         func(t *models.TicketData) string { return t.IssueType },
+	"issue_type":   func(t *models.TicketData) string { return t.IssueType },
+	"status":       func(t *models.TicketData) string { return t.Status },
+	"priority":     func(t *models.TicketData) string { return t.Priority },
+	"assignee":     func(t *models.TicketData) string { return t.Assignee },
+	"reporter":     func(t *models.TicketData) string { return t.Reporter },
+	"created":      func(t *models.TicketData) string { return t.Created },
+	"updated":      func(t *models.TicketData) string { return t.Updated },
+	"jira_updated": func(t *models.TicketData) string { return t.JiraUpdated },
+	"summary":      func(t *models.TicketData) string { return t.Summary },
+	"description":  func(t *models.TicketData) string { return t.Description },
+	"labels":       func(t *models.TicketData) string { return strings.Join(t.Labels, ";") },
+	"components":   func(t *models.TicketData) string { return strings.Join(t.Components, ";") },
+	"url":          func(t *models.TicketData) string { return t.URL },
+	"source":       func(t *models.TicketData) string { return t.Source },
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ExportHandler downloads stored tickets as CSV or NDJSON, optionally
+// filtered by project and status. Tickets are streamed in storage order.
+// For CSV, columns chooses and orders the columns; names other than the
+// built-in ones are read from custom fields.
+func (h *APIHandlers) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		respondError(w, r, http.StatusBadRequest, "Unsupported export format: use csv or ndjson")
+		return
+	}
+
+	columns := parseExportColumns(query.Get("columns"))
+	if len(columns) > maxExportColumns {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("Too many export columns: at most %d", maxExportColumns))
+		return
+	}
+
+	ticketQuery := models.TicketQuery{
+		Project: strings.ToUpper(query.Get("project")),
+		Status:  query.Get("status"),
+
+		IncludeReferences: includeReferences(r),
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFilename(ticketQuery, format)))
+
+	var count int
+	var err error
+	switch format {
+	case "ndjson":
+		count, err = h.writeNDJSON(w, ticketQuery)
+	default:
+		count, err = h.writeCSV(w, ticketQuery, columns)
+	}
+	if err != nil {
+		// Rows may already have been sent, so the error can only be logged
+		h.logger.Error().Err(err).Int("tickets", count).Msg("Failed to write export")
+		return
+	}
+
+	h.logger.Info().
+		Str("format", format).
+		Str("project", ticketQuery.Project).
+		Str("status", ticketQuery.Status).
+		Int("tickets", count).
+		Msg("Exported tickets")
+}
+
+// parseExportColumns splits the comma-separated columns parameter. Built-in
+// names are matched case-insensitively; other names are kept as custom field
+// names. An empty parameter selects defaultExportColumns.
+func parseExportColumns(raw string) []string {
+	var columns []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, builtin := exportColumnValues[strings.ToLower(name)]; builtin {
+			name = strings.ToLower(name)
+		}
+		columns = append(columns, name)
+	}
+
+	if len(columns) == 0 {
+		return defaultExportColumns
+	}
+	return columns
+}
+
+// ticketProject returns the ticket's stored project key, falling back to the
+// project of its issue key. It is empty when neither is usable.
+func ticketProject(t *models.TicketData) string {
+	if t.ProjectID != "" {
+		return t.ProjectID
+	}
+	project, _ := common.IssueKeyProject(t.Key)
+	return project
+}
+
+// exportColumnValue reads one CSV column. Custom field values that are not
+// strings are written as JSON.
+func exportColumnValue(t *models.TicketData, column string) string {
+	if value, builtin := exportColumnValues[column]; builtin {
+		return value(t)
+	}
+
+	switch v := t.CustomFields[column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// exportFilename builds a download name such as tickets-PROJ-open-20250101.csv
+func exportFilename(query models.TicketQuery, format string) string {
+	parts := []string{"tickets"}
+	if query.Project != "" {
+		parts = append(parts, query.Project)
+	}
+	if query.Status != "" {
+		parts = append(parts, strings.ToLower(query.Status))
+	}
+	parts = append(parts, time.Now().Format("20060102"))
+
+	name := unsafeFilenameChars.ReplaceAllString(strings.Join(parts, "-"), "_")
+	return name + "." + format
+}
+
+// writeCSV streams a header row and one row per ticket, returning how many
+// tickets were written
+func (h *APIHandlers) writeCSV(w http.ResponseWriter, query models.TicketQuery, columns []string) (int, error) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	record := make([]string, len(columns))
+	err := h.storage.ScanTickets(query, func(ticket *models.TicketData) error {
+		for i, column := range columns {
+			record[i] = exportColumnValue(ticket, column)
+		}
+		count++
+		return writer.Write(record)
+	})
+	if err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// writeNDJSON streams one JSON ticket per line, returning how many tickets
+// were written
+func (h *APIHandlers) writeNDJSON(w http.ResponseWriter, query models.TicketQuery) (int, error) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+
+	count := 0
+	encoder := json.NewEncoder(w)
+	err := h.storage.ScanTickets(query, func(ticket *models.TicketData) error {
+		count++
+		return encoder.Encode(ticket)
+	})
+	return count, err
+}
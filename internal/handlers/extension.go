@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+)
+
+const (
+	// maxExtensionUploadBytes bounds the size of an uploaded extension zip
+	maxExtensionUploadBytes = 64 << 20
+	// extensionReleaseFile records the latest upload in the extension directory
+	extensionReleaseFile = "release.json"
+)
+
+// ExtensionRelease describes the extension build published with
+// POST /extension/upload
+type ExtensionRelease struct {
+	Version    string    `json:"version"`
+	File       string    `json:"file"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// loadExtensionRelease returns the latest uploaded release, or nil when
+// nothing has been uploaded to dir
+func loadExtensionRelease(dir string) (*ExtensionRelease, error) {
+	data, err := os.ReadFile(filepath.Join(dir, extensionReleaseFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var release ExtensionRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", extensionReleaseFile, err)
+	}
+	return &release, nil
+}
+
+// latestExtensionVersion returns the uploaded release's version, falling
+// back to the .version file when nothing has been uploaded
+func (h *APIHandlers) latestExtensionVersion() (string, *ExtensionRelease) {
+	release, err := loadExtensionRelease(h.config.Collector.ExtensionDir)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("dir", h.config.Collector.ExtensionDir).Msg("Failed to read extension release")
+	}
+	if release != nil {
+		return release.Version, release
+	}
+	return common.GetExtensionVersion(), nil
+}
+
+// ExtensionUploadHandler publishes a new extension build. The body is the
+// extension zip, either raw or as the "file" field of a multipart form. The
+// version in its manifest.json becomes the latest extension version and must
+// not be older than the current one.
+func (h *APIHandlers) ExtensionUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxExtensionUploadBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, "Missing file field in multipart upload")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Extension upload exceeds %d bytes", maxBytesErr.Limit))
+			return
+		}
+		respondError(w, r, http.StatusBadRequest, "Failed to read upload")
+		return
+	}
+
+	version, err := extensionManifestVersion(data)
+	if err != nil {
+		respondFailure(w, r, common.NewValidationError("invalid_extension", err.Error()), h.config.IsDevelopment())
+		return
+	}
+
+	h.extensionMu.Lock()
+	defer h.extensionMu.Unlock()
+
+	current, _ := h.latestExtensionVersion()
+	if cmp, err := common.CompareVersions(version, current); err == nil && cmp < 0 {
+		respondError(w, r, http.StatusConflict, fmt.Sprintf("Extension version %s is older than the current version %s", version, current))
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	release := &ExtensionRelease{
+		Version:    version,
+		File:       fmt.Sprintf("aktis-chrome-extension-%s.zip", version),
+		Size:       int64(len(data)),
+		SHA256:     hex.EncodeToString(sum[:]),
+		UploadedAt: time.Now().UTC(),
+	}
+
+	if err := saveExtensionRelease(h.config.Collector.ExtensionDir, release, data); err != nil {
+		h.logger.Error().Err(err).Str("version", version).Msg("Failed to save extension upload")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "save_extension", "Failed to save extension upload"), h.config.IsDevelopment())
+		return
+	}
+
+	h.logger.Info().
+		Str("version", release.Version).
+		Str("previous_version", current).
+		Int64("size", release.Size).
+		Str("sha256", release.SHA256).
+		Msg("Extension release uploaded")
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "Extension " + release.Version + " uploaded",
+		"release": release,
+	})
+}
+
+// ExtensionDownloadHandler serves the latest uploaded extension zip with its
+// SHA-256 checksum in the X-Checksum-SHA256 header and ETag
+func (h *APIHandlers) ExtensionDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	release, err := loadExtensionRelease(h.config.Collector.ExtensionDir)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to read extension release")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_extension", "Failed to read extension release"), h.config.IsDevelopment())
+		return
+	}
+	if release == nil {
+		respondError(w, r, http.StatusNotFound, "No extension has been uploaded")
+		return
+	}
+
+	file, err := os.Open(filepath.Join(h.config.Collector.ExtensionDir, release.File))
+	if err != nil {
+		h.logger.Error().Err(err).Str("file", release.File).Msg("Failed to open extension release")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_extension", "Failed to open extension release"), h.config.IsDevelopment())
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", release.File))
+	w.Header().Set("X-Checksum-SHA256", release.SHA256)
+	w.Header().Set("X-Extension-Version", release.Version)
+	w.Header().Set("ETag", `"`+release.SHA256+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, release.File, release.UploadedAt, file)
+}
+
+// extensionManifestVersion returns the version from the manifest.json of an
+// extension zip. The manifest may be at the root or inside a single
+// top-level directory.
+func extensionManifestVersion(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("upload is not a zip file: %w", err)
+	}
+
+	var manifest *zip.File
+	for _, file := range archive.File {
+		name := path.Clean(file.Name)
+		if name == "manifest.json" {
+			manifest = file
+			break
+		}
+		if path.Base(name) == "manifest.json" && !strings.Contains(path.Dir(name), "/") && manifest == nil {
+			manifest = file
+		}
+	}
+	if manifest == nil {
+		return "", fmt.Errorf("zip does not contain manifest.json")
+	}
+
+	reader, err := manifest.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest.json: %w", err)
+	}
+	defer reader.Close()
+
+	var fields struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(io.LimitReader(reader, 1<<20)).Decode(&fields); err != nil {
+		return "", fmt.Errorf("malformed manifest.json: %w", err)
+	}
+	if err := common.ValidateExtensionVersion(fields.Version); err != nil {
+		return "", fmt.Errorf("manifest.json: %w", err)
+	}
+	return fields.Version, nil
+}
+
+// saveExtensionRelease writes the zip and then the release record, each via a
+// temporary file so a failed upload leaves the previous release in place
+func saveExtensionRelease(dir string, release *ExtensionRelease, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, release.File), data); err != nil {
+		return err
+	}
+
+	record, err := json.MarshalIndent(release, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, extensionReleaseFile), record)
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
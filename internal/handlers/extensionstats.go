@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+const (
+	// maxExtensionVersions bounds the versions tracked; requests from further
+	// new versions are counted under "other"
+	maxExtensionVersions = 100
+	// maxExtensionSourceIPs bounds the distinct addresses kept per version
+	maxExtensionSourceIPs = 100
+)
+
+// ExtensionTracker counts receiver requests by the extension version that
+// sent them. A nil tracker ignores reports.
+type ExtensionTracker struct {
+	mutex    sync.Mutex
+	versions map[string]*models.ExtensionVersionStats
+}
+
+// NewExtensionTracker creates a tracker holding the given stored statistics
+func NewExtensionTracker(stored []*models.ExtensionVersionStats) *ExtensionTracker {
+	t := &ExtensionTracker{versions: make(map[string]*models.ExtensionVersionStats, len(stored))}
+	for _, stats := range stored {
+		t.versions[stats.Version] = stats
+	}
+	return t
+}
+
+// Record counts one request from version sent from sourceIP and returns a
+// copy of the version's updated statistics
+func (t *ExtensionTracker) Record(version, sourceIP string, failed bool) *models.ExtensionVersionStats {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats, ok := t.versions[version]
+	if !ok && len(t.versions) >= maxExtensionVersions {
+		version = "other"
+		stats, ok = t.versions[version]
+	}
+	now := time.Now()
+	if !ok {
+		stats = &models.ExtensionVersionStats{Version: version, FirstSeen: now}
+		t.versions[version] = stats
+	}
+
+	stats.LastSeen = now
+	stats.Requests++
+	if failed {
+		stats.Errors++
+	}
+	if sourceIP != "" && len(stats.SourceIPs) < maxExtensionSourceIPs && !slices.Contains(stats.SourceIPs, sourceIP) {
+		stats.SourceIPs = append(stats.SourceIPs, sourceIP)
+	}
+
+	snapshot := *stats
+	snapshot.SourceIPs = append([]string(nil), stats.SourceIPs...)
+	return &snapshot
+}
+
+// Summary returns a copy of every version's statistics, most recently seen
+// first
+func (t *ExtensionTracker) Summary() []models.ExtensionVersionStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	summary := make([]models.ExtensionVersionStats, 0, len(t.versions))
+	for _, stats := range t.versions {
+		snapshot := *stats
+		snapshot.SourceIPs = append([]string(nil), stats.SourceIPs...)
+		summary = append(summary, snapshot)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].LastSeen.After(summary[j].LastSeen)
+	})
+	return summary
+}
+
+// extensionVersionLabel names the version a payload was sent by. Missing and
+// malformed versions are grouped so a bad client cannot add unbounded labels.
+func extensionVersionLabel(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	if common.ValidateExtensionVersion(version) != nil {
+		return "invalid"
+	}
+	return version
+}
+
+// extensionBlocked reports whether version is below
+// receiver.min_extension_version. Missing or malformed versions are not
+// blocked, since they cannot be compared.
+func (h *APIHandlers) extensionBlocked(version string) bool {
+	minimum := h.config.Receiver.MinExtensionVersion
+	if minimum == "" || version == "" {
+		return false
+	}
+	cmp, err := common.CompareVersions(version, minimum)
+	return err == nil && cmp < 0
+}
+
+// recordExtensionRequest counts a finished receiver request against the
+// extension version that sent it and stores the updated statistics
+func (h *APIHandlers) recordExtensionRequest(version string, r *http.Request, rec *statusRecorder) {
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		sourceIP = r.RemoteAddr
+	}
+
+	stats := h.extensions.Record(extensionVersionLabel(version), sourceIP, rec.status >= http.StatusBadRequest)
+	if stats == nil || h.config.Storage.ReadOnly {
+		return
+	}
+	if err := h.storage.SaveExtensionStats(stats); err != nil {
+		h.receiverLogger.Warn().Err(err).Str("version", stats.Version).Msg("Failed to store extension stats")
+	}
+}
+
+// ExtensionVersionSummary is one entry of GET /extensions
+type ExtensionVersionSummary struct {
+	models.ExtensionVersionStats
+	Blocked bool `json:"blocked"` // below receiver.min_extension_version
+}
+
+// ExtensionsHandler summarizes receiver traffic by extension version
+func (h *APIHandlers) ExtensionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	latest, _ := h.latestExtensionVersion()
+	versions := make([]ExtensionVersionSummary, 0)
+	for _, stats := range h.extensions.Summary() {
+		versions = append(versions, ExtensionVersionSummary{
+			ExtensionVersionStats: stats,
+			Blocked:               h.extensionBlocked(stats.Version),
+		})
+	}
+
+	response := map[string]interface{}{
+		"success":        true,
+		"latest_version": latest,
+		"min_version":    h.config.Receiver.MinExtensionVersion,
+		"versions":       versions,
+	}
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode extensions response")
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
@@ -1,16 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"regexp"
+	"sort"
 	"strings"
 
+	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/models"
 
 	"golang.org/x/net/html"
 )
 
-// JiraParser handles parsing of Jira HTML pages
-type JiraParser struct{}
+// JiraParser handles parsing of Jira HTML pages. Each parse runs on its own
+// copy, whose walk check stops the tree walks once the parse's context is
+// done.
+type JiraParser struct {
+	walk *common.WalkCheck
+}
+
+// parseStrategies names the extraction strategies ParseHTML tries for each
+// page type, so a page that parses to nothing can report what was attempted
+var parseStrategies = map[string][]string{
+	"projectsList": {"project_rows", "script_tags", "project_links"},
+	"issue":        {"browse_url_key", "selected_issue_param"},
+	"issueList":    {"issue_rows", "project_issue_keys"},
+	"search":       {"issue_rows", "project_issue_keys"},
+	"board":        {"issue_keys"},
+	"generic":      {"issue_keys"},
+}
 
 // NewJiraParser creates a new Jira HTML parser
 func NewJiraParser() *JiraParser {
@@ -18,12 +36,30 @@ func NewJiraParser() *JiraParser {
 }
 
 // ParseHTML parses Jira HTML and extracts issue data based on page type
-func (p *JiraParser) ParseHTML(htmlContent, pageType, url string) ([]map[string]interface{}, error) {
+func (p *JiraParser) ParseHTML(ctx context.Context, htmlContent, pageType, url string) ([]map[string]interface{}, error) {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.ParseDocument(ctx, doc, pageType, url)
+}
+
+// ParseDocument extracts issue data from an already parsed page. It stops
+// with the context's error once ctx is done.
+func (p *JiraParser) ParseDocument(ctx context.Context, doc *html.Node, pageType, url string) ([]map[string]interface{}, error) {
+	run := &JiraParser{walk: common.NewWalkCheck(ctx)}
+	results, err := run.parseDocument(doc, pageType, url)
+	if err := run.walk.Err(); err != nil {
+		return nil, err
+	}
+	return results, err
+}
 
+func (p *JiraParser) parseDocument(doc *html.Node, pageType, url string) ([]map[string]interface{}, error) {
 	switch pageType {
 	case "projectsList":
 		return p.parseProjectsListPage(doc, url)
@@ -136,12 +172,8 @@ func (p *JiraParser) parseIssueListPage(doc *html.Node, url string) ([]map[strin
 	// Extract project key from URL to filter only relevant issues
 	projectKey := p.extractProjectKeyFromURL(url)
 
-	// Extract base URL for building issue URLs
-	baseURLRegex := regexp.MustCompile(`(https?://[^/]+)`)
-	var baseURL string
-	if matches := baseURLRegex.FindStringSubmatch(url); len(matches) > 1 {
-		baseURL = matches[1]
-	}
+	// Issue URLs are resolved against the page URL when it is absolute
+	resolveIssueURLs := common.IsAbsoluteURL(url)
 
 	// Find issue rows in the table (more precise than scanning entire HTML)
 	issueRows := p.findIssueRows(doc)
@@ -155,8 +187,8 @@ func (p *JiraParser) parseIssueListPage(doc *html.Node, url string) ([]map[strin
 				if matches := projectKeyRegex.FindStringSubmatch(issueKey); len(matches) > 1 {
 					issue["project_id"] = matches[1]
 				}
-				if baseURL != "" {
-					issue["url"] = baseURL + "/browse/" + issueKey
+				if resolveIssueURLs {
+					issue["url"] = common.ResolveURL(url, "/browse/"+issueKey)
 				}
 			}
 			issues = append(issues, issue)
@@ -172,8 +204,8 @@ func (p *JiraParser) parseIssueListPage(doc *html.Node, url string) ([]map[strin
 				issue := make(map[string]interface{})
 				issue["key"] = key
 				issue["project_id"] = projectKey
-				if baseURL != "" {
-					issue["url"] = baseURL + "/browse/" + key
+				if resolveIssueURLs {
+					issue["url"] = common.ResolveURL(url, "/browse/"+key)
 				}
 				issues = append(issues, issue)
 			}
@@ -185,18 +217,7 @@ func (p *JiraParser) parseIssueListPage(doc *html.Node, url string) ([]map[strin
 
 // parseBoardPage extracts issues from a board/kanban view
 func (p *JiraParser) parseBoardPage(doc *html.Node, url string) ([]map[string]interface{}, error) {
-	issues := []map[string]interface{}{}
-
-	// Extract issue keys from board
-	issueKeys := p.extractIssueKeys(doc)
-
-	for key := range issueKeys {
-		issue := make(map[string]interface{})
-		issue["key"] = key
-		issues = append(issues, issue)
-	}
-
-	return issues, nil
+	return referenceIssues(p.extractIssueKeys(doc), false), nil
 }
 
 // parseSearchPage extracts issues from search results
@@ -204,33 +225,50 @@ func (p *JiraParser) parseSearchPage(doc *html.Node, url string) ([]map[string]i
 	return p.parseIssueListPage(doc, url)
 }
 
-// parseGenericPage tries to extract any issue data from unknown page types
+// parseGenericPage tries to extract any issue data from unknown page types.
+// Keys only mentioned in text are ignored; a key needs a /browse/ link.
 func (p *JiraParser) parseGenericPage(doc *html.Node, url string) ([]map[string]interface{}, error) {
-	issues := []map[string]interface{}{}
-
-	// Try to extract any issue keys we can find
-	issueKeys := p.extractIssueKeys(doc)
+	return referenceIssues(p.extractIssueKeys(doc), true), nil
+}
 
-	for key := range issueKeys {
-		issue := make(map[string]interface{})
-		issue["key"] = key
-		issues = append(issues, issue)
+// referenceIssues builds key-only issues marked as references, sorted by key.
+// With linkedOnly set, keys not found in a /browse/ link are skipped.
+func referenceIssues(keys map[string]bool, linkedOnly bool) []map[string]interface{} {
+	sorted := make([]string, 0, len(keys))
+	for key, linked := range keys {
+		if linked || !linkedOnly {
+			sorted = append(sorted, key)
+		}
 	}
+	sort.Strings(sorted)
 
-	return issues, nil
+	issues := make([]map[string]interface{}, 0, len(sorted))
+	for _, key := range sorted {
+		issues = append(issues, map[string]interface{}{
+			"key":    key,
+			"source": models.SourceReference,
+		})
+	}
+	return issues
 }
 
-// extractIssueKeys finds all Jira issue keys in the HTML
+// extractIssueKeys finds all Jira issue keys in the HTML. The value is true
+// when the key appears in a /browse/ link rather than only in text.
 func (p *JiraParser) extractIssueKeys(node *html.Node) map[string]bool {
 	keys := make(map[string]bool)
 	keyRegex := regexp.MustCompile(`\b([A-Z]+-\d+)\b`)
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.TextNode {
 			matches := keyRegex.FindAllString(n.Data, -1)
 			for _, match := range matches {
-				keys[match] = true
+				if _, seen := keys[match]; !seen {
+					keys[match] = false
+				}
 			}
 		}
 
@@ -298,6 +336,9 @@ func (p *JiraParser) extractText(node *html.Node) string {
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.TextNode {
 			text.WriteString(n.Data)
 		}
@@ -316,12 +357,18 @@ func (p *JiraParser) findProjectRows(node *html.Node) []*html.Node {
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode && n.Data == "tr" {
 			// Check if this row contains project data
 			// Look for cells with project key
 			hasProjectKey := false
 			var checkCells func(*html.Node)
 			checkCells = func(cell *html.Node) {
+				if p.walk.Stop() {
+					return
+				}
 				if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
 					text := p.extractText(cell)
 					// Project keys are typically 2-10 uppercase letters/numbers
@@ -357,6 +404,9 @@ func (p *JiraParser) extractProjectFromRow(row *html.Node) map[string]interface{
 	cells := []*html.Node{}
 	var getCells func(*html.Node)
 	getCells = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode && n.Data == "td" {
 			cells = append(cells, n)
 		}
@@ -414,6 +464,9 @@ func (p *JiraParser) findLinks(node *html.Node) []string {
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode && n.Data == "a" {
 			for _, attr := range n.Attr {
 				if attr.Key == "href" {
@@ -455,6 +508,9 @@ func (p *JiraParser) findIssueRows(node *html.Node) []*html.Node {
 
 	var traverse func(*html.Node, int)
 	traverse = func(n *html.Node, depth int) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			isCandidate := false
 			hasIssueKey := false
@@ -497,6 +553,9 @@ func (p *JiraParser) findIssueRows(node *html.Node) []*html.Node {
 				hasLink := false
 				var checkForIssueLink func(*html.Node, int) bool
 				checkForIssueLink = func(child *html.Node, childDepth int) bool {
+					if p.walk.Stop() {
+						return false
+					}
 					if childDepth > 5 { // Only look 5 levels deep
 						return false
 					}
@@ -603,6 +662,9 @@ func (p *JiraParser) extractIssueFromRow(row *html.Node, projectFilter string) m
 	if issue["key"] == nil {
 		var findKeyFromLink func(*html.Node) string
 		findKeyFromLink = func(n *html.Node) string {
+			if p.walk.Stop() {
+				return ""
+			}
 			if n.Type == html.ElementNode && n.Data == "a" {
 				for _, attr := range n.Attr {
 					if attr.Key == "href" && strings.Contains(attr.Val, "/browse/") {
@@ -668,6 +730,9 @@ func (p *JiraParser) extractAdditionalFieldsFromRow(row *html.Node, issue map[st
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			// Collect all attributes
 			attrs := make(map[string]string)
@@ -769,6 +834,9 @@ func (p *JiraParser) extractSummaryFromRow(row *html.Node, issueKey string) stri
 
 	var findSummary func(*html.Node)
 	findSummary = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			// Look for elements with summary-related attributes
 			for _, attr := range n.Attr {
@@ -864,6 +932,9 @@ func (p *JiraParser) extractProjectsFromLinks(doc *html.Node, baseURL string) []
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode && n.Data == "a" {
 			var href, text string
 			for _, attr := range n.Attr {
@@ -895,17 +966,7 @@ func (p *JiraParser) extractProjectsFromLinks(doc *html.Node, baseURL string) []
 					}
 
 					// Build absolute URL
-					if strings.HasPrefix(href, "http") {
-						project["url"] = href
-					} else if strings.HasPrefix(href, "/") {
-						project["url"] = baseURL + href
-					} else {
-						// Relative URL
-						baseURLRegex := regexp.MustCompile(`(https?://[^/]+)`)
-						if matches := baseURLRegex.FindStringSubmatch(baseURL); len(matches) > 1 {
-							project["url"] = matches[1] + "/" + href
-						}
-					}
+					project["url"] = common.ResolveURL(baseURL, href)
 
 					projectMap[projectKey] = project
 				}
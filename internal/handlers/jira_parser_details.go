@@ -41,6 +41,62 @@ func (p *JiraParser) extractIssueDetails(doc *html.Node, issue map[string]interf
 	if len(components) > 0 {
 		issue["components"] = components
 	}
+
+	if updated := p.extractUpdatedTime(doc); updated != "" {
+		issue["jira_updated"] = updated
+	}
+}
+
+// extractUpdatedTime returns the datetime attribute of the <time> element in
+// the issue's updated date field, or "" when the page does not show one
+func (p *JiraParser) extractUpdatedTime(doc *html.Node) string {
+	var field *html.Node
+	var findField func(*html.Node)
+	findField = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
+		if field != nil {
+			return
+		}
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if (attr.Key == "id" && attr.Val == "updated-date") ||
+					(attr.Key == "data-testid" && strings.Contains(attr.Val, "updated-date")) {
+					field = n
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil && field == nil; c = c.NextSibling {
+			findField(c)
+		}
+	}
+	findField(doc)
+	if field == nil {
+		return ""
+	}
+
+	var datetime string
+	var findTime func(*html.Node)
+	findTime = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "time" {
+			for _, attr := range n.Attr {
+				if attr.Key == "datetime" {
+					datetime = strings.TrimSpace(attr.Val)
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil && datetime == ""; c = c.NextSibling {
+			findTime(c)
+		}
+	}
+	findTime(field)
+	return datetime
 }
 
 // extractComments extracts all comments from the issue page
@@ -49,6 +105,9 @@ func (p *JiraParser) extractComments(doc *html.Node, issue map[string]interface{
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			// Look for comment containers
 			for _, attr := range n.Attr {
@@ -96,6 +155,9 @@ func (p *JiraParser) extractSingleComment(n *html.Node) map[string]interface{} {
 	// Try to extract author and timestamps
 	var extractMeta func(*html.Node)
 	extractMeta = func(node *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if node.Type == html.ElementNode {
 			for _, attr := range node.Attr {
 				val := strings.ToLower(attr.Val)
@@ -129,6 +191,9 @@ func (p *JiraParser) extractSubtasks(doc *html.Node, issue map[string]interface{
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			for _, attr := range n.Attr {
 				if (attr.Key == "data-testid" && strings.Contains(attr.Val, "subtask")) ||
@@ -162,6 +227,9 @@ func (p *JiraParser) extractSingleSubtask(n *html.Node) map[string]interface{} {
 	// Look for issue key in links
 	var findKey func(*html.Node)
 	findKey = func(node *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if node.Type == html.ElementNode && node.Data == "a" {
 			for _, attr := range node.Attr {
 				if attr.Key == "href" && strings.Contains(attr.Val, "/browse/") {
@@ -195,6 +263,9 @@ func (p *JiraParser) extractAttachments(doc *html.Node, issue map[string]interfa
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			for _, attr := range n.Attr {
 				if (attr.Key == "data-testid" && strings.Contains(attr.Val, "attachment")) ||
@@ -228,6 +299,9 @@ func (p *JiraParser) extractSingleAttachment(n *html.Node, baseURL string) map[s
 	// Extract filename and URL from links
 	var findFile func(*html.Node)
 	findFile = func(node *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if node.Type == html.ElementNode && node.Data == "a" {
 			for _, attr := range node.Attr {
 				if attr.Key == "href" {
@@ -260,6 +334,9 @@ func (p *JiraParser) extractLinks(doc *html.Node, issue map[string]interface{},
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			for _, attr := range n.Attr {
 				if (attr.Key == "data-testid" && strings.Contains(attr.Val, "issue-link")) ||
@@ -293,6 +370,9 @@ func (p *JiraParser) extractSingleLink(n *html.Node) map[string]interface{} {
 	// Look for linked issue key
 	var findLinked func(*html.Node)
 	findLinked = func(node *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if node.Type == html.ElementNode && node.Data == "a" {
 			for _, attr := range node.Attr {
 				if attr.Key == "href" && strings.Contains(attr.Val, "/browse/") {
@@ -332,6 +412,9 @@ func (p *JiraParser) extractSingleLink(n *html.Node) map[string]interface{} {
 func (p *JiraParser) findAndExtractField(doc *html.Node, fieldName string, issue map[string]interface{}, testIDs []string) {
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			for _, attr := range n.Attr {
 				for _, testID := range testIDs {
@@ -355,19 +438,27 @@ func (p *JiraParser) findAndExtractField(doc *html.Node, fieldName string, issue
 	traverse(doc)
 }
 
-// extractListField extracts a list of values from a field
+// extractListField extracts a list of values from a field. Each selector is
+// "attribute=value" and matches elements whose attribute contains the value.
 func (p *JiraParser) extractListField(doc *html.Node, testIDs []string) []string {
 	items := []string{}
 
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
+		if p.walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			for _, attr := range n.Attr {
 				for _, testID := range testIDs {
-					if strings.Contains(attr.Val, testID) {
+					key, value, _ := strings.Cut(testID, "=")
+					if attr.Key == key && strings.Contains(attr.Val, value) {
 						// Extract all text items from child spans or divs
 						var extractItems func(*html.Node)
 						extractItems = func(node *html.Node) {
+							if p.walk.Stop() {
+								return
+							}
 							if node.Type == html.ElementNode && (node.Data == "span" || node.Data == "a") {
 								text := strings.TrimSpace(p.extractText(node))
 								if text != "" && len(text) < 100 {
@@ -0,0 +1,159 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/services"
+
+	"github.com/ternarybob/arbor"
+	"golang.org/x/net/html"
+)
+
+// issueListPage builds an issue list page for project ABC with the given
+// number of rows, in the same markup as testdata/issue_list.html
+func issueListPage(rows int) string {
+	var b strings.Builder
+	b.WriteString("<html><head><title>ABC issues - Jira</title></head><body><table id=\"issuetable\">\n")
+	for i := 1; i <= rows; i++ {
+		fmt.Fprintf(&b, "<tr data-issue-key=\"ABC-%d\"><td class=\"issuekey\"><a href=\"/browse/ABC-%d\">ABC-%d</a></td>"+
+			"<td data-testid=\"issue-table.summary\">Issue number %d</td><td class=\"status\">Open</td></tr>\n", i, i, i, i)
+	}
+	b.WriteString("</table></body></html>\n")
+	return b.String()
+}
+
+// benchmarkPages are the page sizes the parser and receiver benchmarks run over
+func benchmarkPages(b *testing.B) []struct {
+	name string
+	html string
+} {
+	return []struct {
+		name string
+		html string
+	}{
+		{"small", readFixture(b, "issue_list.html")},
+		{"medium", issueListPage(200)},
+		{"huge", issueListPage(10000)},
+	}
+}
+
+func BenchmarkParseDocument(b *testing.B) {
+	const pageURL = testSiteURL + "/projects/ABC/issues"
+	for _, page := range benchmarkPages(b) {
+		b.Run(page.name, func(b *testing.B) {
+			doc, err := html.Parse(strings.NewReader(page.html))
+			if err != nil {
+				b.Fatalf("html.Parse: %v", err)
+			}
+			parser := handlers.NewJiraParser()
+			b.SetBytes(int64(len(page.html)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseDocument(context.Background(), doc, "issueList", pageURL); err != nil {
+					b.Fatalf("ParseDocument: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkParseHTML includes building the tree, which the receiver does once
+// per request and shares with the assessor
+func BenchmarkParseHTML(b *testing.B) {
+	const pageURL = testSiteURL + "/projects/ABC/issues"
+	for _, page := range benchmarkPages(b) {
+		b.Run(page.name, func(b *testing.B) {
+			parser := handlers.NewJiraParser()
+			b.SetBytes(int64(len(page.html)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseHTML(context.Background(), page.html, "issueList", pageURL); err != nil {
+					b.Fatalf("ParseHTML: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestParseDocumentMatchesParseHTML checks the receiver's shared-tree path
+// extracts the same issues as parsing the page string
+func TestParseDocumentMatchesParseHTML(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		pageType string
+		url      string
+	}{
+		{"issue.html", "issue", testSiteURL + "/browse/ABC-7"},
+		{"issue_list.html", "issueList", testSiteURL + "/projects/ABC/issues"},
+		{"projects.html", "projectsList", testSiteURL + "/jira/projects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			page := readFixture(t, tt.fixture)
+			fromString, err := handlers.NewJiraParser().ParseHTML(context.Background(), page, tt.pageType, tt.url)
+			if err != nil {
+				t.Fatalf("ParseHTML: %v", err)
+			}
+			doc, err := html.Parse(strings.NewReader(page))
+			if err != nil {
+				t.Fatalf("html.Parse: %v", err)
+			}
+			fromDoc, err := handlers.NewJiraParser().ParseDocument(context.Background(), doc, tt.pageType, tt.url)
+			if err != nil {
+				t.Fatalf("ParseDocument: %v", err)
+			}
+			if len(fromDoc) == 0 || !reflect.DeepEqual(fromDoc, fromString) {
+				t.Errorf("ParseDocument = %v\nParseHTML     = %v", fromDoc, fromString)
+			}
+		})
+	}
+}
+
+// BenchmarkAssessAndParse compares the receiver's single html.Parse shared by
+// the assessor and the parser with assessing and parsing the page string
+// separately, which builds the tree twice
+func BenchmarkAssessAndParse(b *testing.B) {
+	const pageURL = testSiteURL + "/projects/ABC/issues"
+	assessor := services.NewPageAssessor(arbor.NewLogger())
+	parser := handlers.NewJiraParser()
+	ctx := context.Background()
+
+	for _, page := range benchmarkPages(b) {
+		b.Run(page.name+"/shared", func(b *testing.B) {
+			b.SetBytes(int64(len(page.html)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				doc, err := html.Parse(strings.NewReader(page.html))
+				if err != nil {
+					b.Fatalf("html.Parse: %v", err)
+				}
+				assessment, err := assessor.AssessDocument(ctx, doc, pageURL)
+				if err != nil {
+					b.Fatalf("AssessDocument: %v", err)
+				}
+				if _, err := parser.ParseDocument(ctx, doc, assessment.PageType, pageURL); err != nil {
+					b.Fatalf("ParseDocument: %v", err)
+				}
+			}
+		})
+		b.Run(page.name+"/separate", func(b *testing.B) {
+			b.SetBytes(int64(len(page.html)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				assessment, err := assessor.AssessPage(ctx, page.html, pageURL)
+				if err != nil {
+					b.Fatalf("AssessPage: %v", err)
+				}
+				if _, err := parser.ParseHTML(ctx, page.html, assessment.PageType, pageURL); err != nil {
+					b.Fatalf("ParseHTML: %v", err)
+				}
+			}
+		})
+	}
+}
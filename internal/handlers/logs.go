@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+
+	"github.com/ternarybob/arbor"
+	arbormodels "github.com/ternarybob/arbor/models"
+)
+
+const (
+	defaultLogLimit = 200
+	maxLogLimit     = 1000
+)
+
+// logLevelRank orders log levels for minimum-level filtering
+var logLevelRank = map[string]int{
+	"trace": 0,
+	"debug": 1,
+	"info":  2,
+	"warn":  3,
+	"error": 4,
+	"fatal": 5,
+	"panic": 6,
+}
+
+// LogEntry represents a single log line sent to the UI
+type LogEntry struct {
+	Index    uint64                 `json:"index"`
+	Time     time.Time              `json:"time"`
+	Level    string                 `json:"level"`
+	Message  string                 `json:"message"`
+	Function string                 `json:"function,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
+func newLogEntry(event arbormodels.LogEvent) LogEntry {
+	return LogEntry{
+		Index:    event.Index,
+		Time:     event.Timestamp,
+		Level:    event.Level.String(),
+		Message:  event.Message,
+		Function: event.Function,
+		Error:    event.Error,
+		Fields:   event.Fields,
+	}
+}
+
+// meetsLevel reports whether level is at or above minLevel. An empty or
+// unknown minimum accepts everything.
+func meetsLevel(level, minLevel string) bool {
+	minRank, ok := logLevelRank[strings.ToLower(minLevel)]
+	if !ok {
+		return true
+	}
+	return logLevelRank[strings.ToLower(level)] >= minRank
+}
+
+// recentLogs returns up to limit of the most recent in-memory log entries at
+// or above minLevel, oldest first
+func recentLogs(limit int, minLevel string) ([]LogEntry, error) {
+	memoryWriter := arbor.GetRegisteredMemoryWriter(arbor.WRITER_MEMORY)
+	if memoryWriter == nil {
+		return []LogEntry{}, nil
+	}
+
+	// Fetch extra entries so level filtering still fills the limit
+	events, err := memoryWriter.GetStore().GetRecent(maxLogLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LogEntry, 0, limit)
+	for _, event := range events {
+		entry := newLogEntry(event)
+		if !meetsLevel(entry.Level, minLevel) {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) == limit {
+			break
+		}
+	}
+
+	// GetRecent returns newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
+
+// LogsHandler returns the recent log backlog, optionally filtered by minimum level
+func (h *APIHandlers) LogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	limit := defaultLogLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, maxLogLimit)
+	}
+
+	entries, err := recentLogs(limit, r.URL.Query().Get("level"))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to read log backlog")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "read_logs", "Failed to read logs"), h.config.IsDevelopment())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"logs":    entries,
+		"count":   len(entries),
+	}
+
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode logs response")
+	}
+}
+
+// LogsPageHandler serves the live log viewer page
+func (h *UIHandlers) LogsPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	if err := h.executeTemplate(w, "logs.html", h.templateData("Logs")); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to execute logs template")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
+	}
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/metrics"
+	"aktis-collector-jira/internal/models"
+
+	"github.com/ternarybob/arbor"
+)
+
+// receiverMeasurements are the sizes and durations of one receiver request.
+// Parse and storage durations stay zero when those steps did not run.
+type receiverMeasurements struct {
+	htmlBytes       int
+	entities        int
+	parseDuration   time.Duration
+	storageDuration time.Duration
+}
+
+// limitPayload caps the request body at the configured receiver size
+func (h *APIHandlers) limitPayload(w http.ResponseWriter, r *http.Request) {
+	if limit := h.config.Receiver.MaxPayloadBytes; limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+}
+
+// payloadTooLarge reports whether a decode error was caused by limitPayload
+func payloadTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// receiverOriginAllowed reports whether the request Origin may post to the
+// receiver. Requests without an Origin header are always allowed.
+func (h *APIHandlers) receiverOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	allowed := h.config.Receiver.AllowedOrigins
+	if origin == "" || len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// payloadHash identifies a payload's page content for the dedupe window. It
+// is empty when deduplication is disabled.
+func (h *APIHandlers) payloadHash(url, html string) string {
+	if h.config.Receiver.DedupeWindowSeconds <= 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url + "\x00" + html))
+	return hex.EncodeToString(sum[:])
+}
+
+// isDuplicatePayload reports whether a payload with the same hash was stored
+// within the configured dedupe window
+func (h *APIHandlers) isDuplicatePayload(hash string) bool {
+	if hash == "" {
+		return false
+	}
+	window := time.Duration(h.config.Receiver.DedupeWindowSeconds) * time.Second
+	now := time.Now()
+
+	h.recentMu.Lock()
+	defer h.recentMu.Unlock()
+
+	for key, received := range h.recentPayloads {
+		if now.Sub(received) > window {
+			delete(h.recentPayloads, key)
+		}
+	}
+
+	_, seen := h.recentPayloads[hash]
+	return seen
+}
+
+// recordPayload starts the dedupe window for a payload. It is only called
+// once the payload's data has been stored, so a payload that failed can be
+// retried.
+func (h *APIHandlers) recordPayload(hash string) {
+	if hash == "" {
+		return
+	}
+
+	h.recentMu.Lock()
+	defer h.recentMu.Unlock()
+
+	if h.recentPayloads == nil {
+		h.recentPayloads = make(map[string]time.Time)
+	}
+	h.recentPayloads[hash] = time.Now()
+}
+
+// recordReceiverMeasurements logs the measurements of a receiver request,
+// feeds them into the metrics registry by page type and warns when the
+// request took longer than receiver.slow_request_ms
+func (h *APIHandlers) recordReceiverMeasurements(logger arbor.ILogger, pageType string, m *receiverMeasurements, total time.Duration) {
+	event := logger.Info()
+	if limit := h.config.Receiver.SlowRequestMs; limit > 0 && total > time.Duration(limit)*time.Millisecond {
+		event = logger.Warn()
+	}
+	event.
+		Str("page_type", pageType).
+		Int("html_bytes", m.htmlBytes).
+		Int("entities", m.entities).
+		Dur("parse_duration", m.parseDuration).
+		Dur("storage_duration", m.storageDuration).
+		Dur("duration", total).
+		Msg("Receiver request measured")
+
+	if h.metrics == nil {
+		return
+	}
+	h.metrics.AddCounter("receiver_payloads_total", "Payloads received by page type", 1, "page_type", pageType)
+	h.metrics.AddCounter("receiver_entities_total", "Projects and tickets extracted by page type", float64(m.entities), "page_type", pageType)
+	h.metrics.ObserveHistogram("receiver_payload_bytes", "Page HTML size received by page type", metrics.SizeBuckets, float64(m.htmlBytes), "page_type", pageType)
+	h.metrics.ObserveHistogram("receiver_duration_seconds", "Time to handle a payload by page type", metrics.DefaultBuckets, total.Seconds(), "page_type", pageType)
+	if m.parseDuration > 0 {
+		h.metrics.ObserveHistogram("receiver_parse_duration_seconds", "HTML parse time by page type", metrics.DefaultBuckets, m.parseDuration.Seconds(), "page_type", pageType)
+	}
+	if m.storageDuration > 0 {
+		h.metrics.ObserveHistogram("receiver_storage_duration_seconds", "Storage write time by page type", metrics.DefaultBuckets, m.storageDuration.Seconds(), "page_type", pageType)
+	}
+}
+
+// notifyCollection sends a receiver collection event to the notifier. The
+// notifier decides which webhooks, if any, receive it.
+func (h *APIHandlers) notifyCollection(event, pageURL, pageType, transactionID string, stats *CollectionStats, err error) {
+	if h.notifier == nil {
+		return
+	}
+
+	notification := &models.NotificationEvent{
+		Event:         event,
+		Timestamp:     time.Now(),
+		Collector:     h.config.Collector.Name,
+		Source:        "receiver",
+		Project:       pageProject(pageURL),
+		PageType:      pageType,
+		URL:           common.URLHostPath(pageURL),
+		TransactionID: transactionID,
+	}
+	if stats != nil {
+		notification.ProjectsAdded = stats.ProjectsAdded
+		notification.TicketsAdded = stats.TicketsAdded
+	}
+	if err != nil {
+		notification.Error = err.Error()
+	}
+	h.notifier.Notify(notification)
+}
+
+// pageProject finds the project key in a Jira page URL, from an issue key in
+// /browse/ or a project path or JQL filter. It returns "" when there is none.
+func pageProject(pageURL string) string {
+	if _, rest, found := strings.Cut(pageURL, "/browse/"); found {
+		if project, _, found := strings.Cut(rest, "-"); found && project != "" {
+			return project
+		}
+	}
+	return NewJiraParser().extractProjectKeyFromURL(pageURL)
+}
+
+// disabledProjects returns the keys of disabled projects when
+// receiver.reject_disabled_projects is set, and nil otherwise
+func (h *APIHandlers) disabledProjects(logger arbor.ILogger) map[string]bool {
+	if !h.config.Receiver.RejectDisabledProjects {
+		return nil
+	}
+
+	projects, err := h.storage.LoadProjects()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to load projects, accepting tickets for all projects")
+		return nil
+	}
+	disabled := make(map[string]bool)
+	for _, project := range projects {
+		if project.Disabled {
+			disabled[project.Key] = true
+		}
+	}
+	return disabled
+}
+
+// clearedSince reports whether projectKey's tickets were cleared after
+// pageTime, caching the answer per project in cleared. A zero pageTime is
+// never cleared.
+func (h *APIHandlers) clearedSince(cleared map[string]bool, projectKey string, pageTime time.Time, logger arbor.ILogger) bool {
+	if pageTime.IsZero() {
+		return false
+	}
+	if result, ok := cleared[projectKey]; ok {
+		return result
+	}
+
+	clearedAt, err := h.storage.ClearedAt(projectKey)
+	if err != nil {
+		logger.Warn().Err(err).Str("project", projectKey).Msg("Failed to read tombstone, storing tickets")
+	}
+	cleared[projectKey] = clearedAt != nil && clearedAt.After(pageTime)
+	return cleared[projectKey]
+}
+
+// Receiver phases that stop when the parse context is done
+const (
+	phaseHTMLParse = "html_parse"
+	phaseAssess    = "assess"
+	phaseParse     = "parse"
+)
+
+// parseContext returns a copy of ctx that is done after
+// receiver.parse_timeout_seconds, for assessing and parsing one payload
+func (h *APIHandlers) parseContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := h.config.Receiver.ParseTimeoutSeconds; timeout > 0 {
+		return context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// parseStopped is returned when a receiver phase stopped because the client
+// disconnected or receiver.parse_timeout_seconds passed
+type parseStopped struct {
+	phase string
+	err   error
+}
+
+func (e *parseStopped) Error() string {
+	return fmt.Sprintf("%s stopped: %v", e.phase, e.err)
+}
+
+func (e *parseStopped) Unwrap() error {
+	return e.err
+}
+
+// respondParseStopped answers a payload whose parsing stopped. A timeout is
+// reported as a 408 naming the phase; after a client disconnect nobody is
+// listening, so the request is only logged.
+func (h *APIHandlers) respondParseStopped(w http.ResponseWriter, logger arbor.ILogger, payload ExtensionDataPayload, stopped *parseStopped, pageType, transactionID string, htmlBytes int) {
+	if errors.Is(stopped.err, context.Canceled) {
+		logger.Info().
+			Str("phase", stopped.phase).
+			Str("url", payload.URL).
+			Msg("Client disconnected, parsing abandoned")
+		if h.metrics != nil {
+			h.metrics.AddCounter("receiver_parse_abandoned_total", "Payloads whose parsing stopped because the client disconnected", 1, "phase", stopped.phase)
+		}
+		return
+	}
+
+	timeout := time.Duration(h.config.Receiver.ParseTimeoutSeconds) * time.Second
+	message := fmt.Sprintf("Parsing stopped in the %s phase after %s", stopped.phase, timeout)
+	logger.Warn().
+		Str("phase", stopped.phase).
+		Str("url", payload.URL).
+		Int("html_bytes", htmlBytes).
+		Dur("timeout", timeout).
+		Msg("Parse timeout reached, payload not stored")
+	h.errorTracker.Record("parser", common.WrapError(stopped, common.ErrorTypeCollection, "parse_timeout", message))
+	if h.metrics != nil {
+		h.metrics.AddCounter("receiver_parse_timeouts_total", "Payloads whose parsing stopped at receiver.parse_timeout_seconds", 1, "phase", stopped.phase)
+	}
+	h.notifyCollection(models.EventRunFailed, payload.URL, pageType, transactionID, nil, stopped)
+
+	if h.wsHub != nil {
+		h.wsHub.SendCollectionUpdate("collection_failed", map[string]interface{}{
+			"transaction_id": transactionID,
+			"url":            payload.URL,
+			"page_type":      pageType,
+			"error":          message,
+		})
+	}
+
+	respondJSON(w, http.StatusRequestTimeout, ReceiverResponse{
+		Success:       false,
+		Message:       message,
+		Error:         "parse_timeout",
+		Timestamp:     time.Now(),
+		PageType:      pageType,
+		TransactionID: transactionID,
+		Data: map[string]interface{}{
+			"phase":           stopped.phase,
+			"timeout_seconds": h.config.Receiver.ParseTimeoutSeconds,
+			"html_bytes":      htmlBytes,
+		},
+	})
+}
+
+// respondParsedEmpty reports a collectable page that parsed to nothing as a
+// 422, so the extension does not show a successful collection
+func (h *APIHandlers) respondParsedEmpty(w http.ResponseWriter, payload ExtensionDataPayload, pageType, transactionID string, htmlBytes int) {
+	details := map[string]interface{}{
+		"status":     "parsed_empty",
+		"page_type":  pageType,
+		"html_bytes": htmlBytes,
+		"strategies": parseStrategies[pageType],
+	}
+
+	if h.wsHub != nil {
+		h.wsHub.SendCollectionUpdate("collection_empty", map[string]interface{}{
+			"transaction_id": transactionID,
+			"url":            payload.URL,
+			"page_type":      pageType,
+			"html_bytes":     htmlBytes,
+		})
+	}
+
+	respondJSON(w, http.StatusUnprocessableEntity, ReceiverResponse{
+		Success:       false,
+		Message:       fmt.Sprintf("No data found in %s page", pageType),
+		Error:         errParsedEmpty.Error(),
+		Timestamp:     time.Now(),
+		PageType:      pageType,
+		TransactionID: transactionID,
+		Data:          details,
+	})
+}
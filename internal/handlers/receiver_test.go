@@ -0,0 +1,1100 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/metrics"
+	"aktis-collector-jira/internal/models"
+	"aktis-collector-jira/internal/services"
+
+	"github.com/gorilla/websocket"
+	"github.com/ternarybob/arbor"
+)
+
+const testSiteURL = "https://example.atlassian.net"
+
+// testCollector is the receiver endpoints and WebSocket hub behind an
+// httptest server, storing into a database in a temporary directory
+type testCollector struct {
+	url     string
+	wsURL   string
+	storage interfaces.Storage
+	hub     *handlers.WebSocketHub
+	api     *handlers.APIHandlers
+	metrics *metrics.Registry
+}
+
+// newTestCollector starts a collector with the default configuration, changed
+// by configure when it is not nil
+func newTestCollector(t testing.TB, configure func(*common.Config)) *testCollector {
+	t.Helper()
+	config := common.DefaultConfig()
+	dir := t.TempDir()
+	config.Storage.DatabasePath = filepath.Join(dir, "test.db")
+	config.Storage.BackupDir = filepath.Join(dir, "backups")
+	config.Collector.ExtensionDir = filepath.Join(dir, "extension")
+	if configure != nil {
+		configure(config)
+	}
+
+	storage, err := services.NewStorage(&config.Storage)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	logger := arbor.NewLogger()
+	hub := handlers.NewWebSocketHub(config, logger)
+	registry := metrics.NewRegistry()
+	api := handlers.NewAPIHandlers(config, storage, logger, services.NewPageAssessor(logger), hub, registry, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/assess", api.AssessHandler)
+	mux.HandleFunc("/receiver", api.ReceiverHandler)
+	mux.HandleFunc("/ws", hub.WebSocketHandler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx)
+		server.Close()
+		storage.Close()
+	})
+
+	return &testCollector{
+		url:     server.URL,
+		wsURL:   "ws" + strings.TrimPrefix(server.URL, "http") + "/ws",
+		storage: storage,
+		hub:     hub,
+		api:     api,
+		metrics: registry,
+	}
+}
+
+// startWebServer runs the full web server, with its routes and middleware, on
+// a free local port and returns its base URL. The database must not be open
+// elsewhere.
+func startWebServer(t testing.TB, config *common.Config) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	config.Collector.BindAddress = "127.0.0.1"
+	config.Collector.Port = listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	storage, err := services.OpenStorage(&config.Storage, arbor.NewLogger())
+	if err != nil {
+		t.Fatalf("OpenStorage: %v", err)
+	}
+	server, err := services.NewWebServer(config, storage, arbor.NewLogger())
+	if err != nil {
+		t.Fatalf("NewWebServer: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Stop()
+		storage.Close()
+	})
+	return config.ServerURL()
+}
+
+// readFixture returns a page from testdata
+func readFixture(t testing.TB, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return string(data)
+}
+
+// receiverPayload builds the body the extension posts for a page
+func receiverPayload(pageURL, pageHTML string) map[string]interface{} {
+	return map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"url":       pageURL,
+		"title":     "Jira",
+		"data":      map[string]interface{}{"html": pageHTML},
+		"collector": map[string]string{"name": "aktis-chrome-extension", "version": "0.1.200"},
+	}
+}
+
+// post sends body as JSON and decodes the JSON response into out
+func (c *testCollector) post(t testing.TB, path string, body interface{}, out interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+	resp, err := http.Post(c.url+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decoding %s response: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+// receive posts a page to /receiver and checks it was answered with 200
+func (c *testCollector) receive(t testing.TB, pageURL, pageHTML string) handlers.ReceiverResponse {
+	t.Helper()
+	var response handlers.ReceiverResponse
+	if status := c.post(t, "/receiver", receiverPayload(pageURL, pageHTML), &response); status != http.StatusOK {
+		t.Fatalf("POST /receiver status = %d, response %+v", status, response)
+	}
+	return response
+}
+
+// metricsText returns the collector's metrics in the exposition format
+func (c *testCollector) metricsText() string {
+	var b strings.Builder
+	c.metrics.WriteTo(&b)
+	return b.String()
+}
+
+// dial connects a WebSocket client and waits until the hub has registered it
+func (c *testCollector) dial(t *testing.T) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(c.wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	waitForClients(t, c.hub, 1)
+	return conn
+}
+
+func TestAssessClassifiesPages(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	tests := []struct {
+		name        string
+		url         string
+		fixture     string
+		pageType    string
+		collectable bool
+	}{
+		{"issue", testSiteURL + "/browse/ABC-7", "issue.html", "issue", true},
+		{"issue list", testSiteURL + "/projects/ABC/issues", "issue_list.html", "issueList", true},
+		{"projects list", testSiteURL + "/jira/projects", "projects.html", "projectsList", true},
+		{"board", testSiteURL + "/secure/RapidBoard.jspa?rapidView=1", "board.html", "board", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response struct {
+				Success    bool `json:"success"`
+				Assessment struct {
+					PageType    string `json:"page_type"`
+					Collectable bool   `json:"collectable"`
+				} `json:"assessment"`
+			}
+			body := map[string]string{"url": tt.url, "html": readFixture(t, tt.fixture)}
+			if status := c.post(t, "/assess", body, &response); status != http.StatusOK {
+				t.Fatalf("status = %d", status)
+			}
+			if response.Assessment.PageType != tt.pageType || response.Assessment.Collectable != tt.collectable {
+				t.Errorf("assessment = %+v, want page type %s, collectable %v", response.Assessment, tt.pageType, tt.collectable)
+			}
+		})
+	}
+
+	// Assessing never stores anything
+	if tickets, err := c.storage.LoadAllTickets(); err != nil || len(tickets) != 0 {
+		t.Errorf("stored tickets after /assess = %d, %v", len(tickets), err)
+	}
+}
+
+func TestReceiverStoresIssuePage(t *testing.T) {
+	c := newTestCollector(t, nil)
+	conn := c.dial(t)
+
+	response := c.receive(t, testSiteURL+"/browse/ABC-7", readFixture(t, "issue.html"))
+	if !response.Success || response.PageType != "issue" {
+		t.Fatalf("response = %+v", response)
+	}
+	if response.Stats == nil || response.Stats.TicketsAdded != 1 || response.Stats.TicketsTotal != 1 {
+		t.Errorf("stats = %+v, want 1 ticket added of 1", response.Stats)
+	}
+
+	ticket, err := c.storage.GetTicket("ABC", "ABC-7")
+	if err != nil || ticket == nil {
+		t.Fatalf("GetTicket(ABC-7) = %v, %v", ticket, err)
+	}
+	if ticket.Summary != "Fix login" || ticket.Status != "Open" || ticket.Priority != "High" {
+		t.Errorf("stored ticket = %+v", ticket)
+	}
+
+	started := readEvent(t, conn, "collection_started")
+	success := readEvent(t, conn, "collection_success")
+	startedData, _ := started["data"].(map[string]interface{})
+	successData, _ := success["data"].(map[string]interface{})
+	if startedData["transaction_id"] != response.TransactionID || successData["transaction_id"] != response.TransactionID {
+		t.Errorf("event transaction IDs = %v, %v, want %s", startedData["transaction_id"], successData["transaction_id"], response.TransactionID)
+	}
+	if stats, _ := successData["stats"].(map[string]interface{}); stats["tickets_added"] != float64(1) {
+		t.Errorf("collection_success stats = %v", successData["stats"])
+	}
+}
+
+// issuePageUpdated is the issue fixture with the updated date field set
+func issuePageUpdated(t *testing.T, updated string) string {
+	t.Helper()
+	field := `<div data-testid="issue.views.field.date-inline-edit.updated-date"><time datetime="` + updated + `">Updated</time></div>`
+	return strings.Replace(readFixture(t, "issue.html"), "</body>", field+"\n</body>", 1)
+}
+
+// TestReceiverAdvancesJiraUpdated posts an issue page and then newer and
+// older copies of it. The project's newest Jira update, like the ticket's,
+// follows the newest page and never moves back.
+func TestReceiverAdvancesJiraUpdated(t *testing.T) {
+	c := newTestCollector(t, nil)
+	pageURL := testSiteURL + "/browse/ABC-7"
+
+	steps := []struct {
+		updated string
+		want    string
+	}{
+		{"2026-03-01T10:00:00Z", "2026-03-01T10:00:00Z"},
+		{"2026-03-05T09:30:00+02:00", "2026-03-05T07:30:00Z"},
+		{"2026-02-01T00:00:00Z", "2026-03-05T07:30:00Z"},
+	}
+	for _, step := range steps {
+		response := c.receive(t, pageURL, issuePageUpdated(t, step.updated))
+		if !response.Success {
+			t.Fatalf("posting updated %s: response = %+v", step.updated, response)
+		}
+
+		freshness, err := c.storage.GetProjectFreshness()
+		if err != nil {
+			t.Fatalf("GetProjectFreshness: %v", err)
+		}
+		var newest *time.Time
+		for _, project := range freshness {
+			if project.Key == "ABC" {
+				newest = project.NewestJiraUpdate
+			}
+		}
+		if newest == nil || newest.UTC().Format(time.RFC3339) != step.want {
+			t.Errorf("after updated %s: newest Jira update = %v, want %s", step.updated, newest, step.want)
+		}
+	}
+
+	ticket, err := c.storage.GetTicket("ABC", "ABC-7")
+	if err != nil || ticket == nil {
+		t.Fatalf("GetTicket(ABC-7) = %v, %v", ticket, err)
+	}
+	if ticket.JiraUpdated != "2026-03-05T07:30:00Z" {
+		t.Errorf("ticket jira_updated = %q, want the newest page's value", ticket.JiraUpdated)
+	}
+}
+
+func TestReceiverStoresIssueList(t *testing.T) {
+	c := newTestCollector(t, nil)
+	pageURL := testSiteURL + "/projects/ABC/issues"
+	page := readFixture(t, "issue_list.html")
+
+	response := c.receive(t, pageURL, page)
+	if response.PageType != "issueList" || response.Stats == nil || response.Stats.TicketsAdded != 3 || response.Stats.TicketsTotal != 3 {
+		t.Fatalf("first post: page type %s, stats %+v", response.PageType, response.Stats)
+	}
+
+	tickets, err := c.storage.LoadTickets("ABC")
+	if err != nil {
+		t.Fatalf("LoadTickets: %v", err)
+	}
+	for _, key := range []string{"ABC-1", "ABC-2", "ABC-10"} {
+		if tickets[key] == nil {
+			t.Errorf("%s not stored; stored %d tickets", key, len(tickets))
+		}
+	}
+	if ticket := tickets["ABC-2"]; ticket != nil && ticket.Summary != "Second issue" {
+		t.Errorf("ABC-2 summary = %q", ticket.Summary)
+	}
+
+	// The same page again updates the tickets without adding any
+	response = c.receive(t, pageURL, page)
+	if response.Stats == nil || response.Stats.TicketsAdded != 0 || response.Stats.TicketsTotal != 3 {
+		t.Errorf("second post: stats %+v, want 0 added of 3", response.Stats)
+	}
+}
+
+// TestReceiverIgnoresIncidentalKeys posts pages that mention other issues in
+// descriptions, comments, summaries and links. Only the page's own issues
+// are stored; the mentions never become tickets of their own.
+func TestReceiverIgnoresIncidentalKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		pageURL string
+		fixture string
+		want    []string
+	}{
+		{"issue page", testSiteURL + "/browse/ABC-7", "issue_mentions.html", []string{"ABC-7"}},
+		{"issue list", testSiteURL + "/projects/ABC/issues", "issue_list_mentions.html", []string{"ABC-1", "ABC-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCollector(t, nil)
+
+			response := c.receive(t, tt.pageURL, readFixture(t, tt.fixture))
+			if !response.Success || response.Stats == nil || response.Stats.TicketsAdded != len(tt.want) {
+				t.Fatalf("response = %+v, stats = %+v, want %d added", response, response.Stats, len(tt.want))
+			}
+
+			stored, err := c.storage.LoadAllTickets()
+			if err != nil {
+				t.Fatalf("LoadAllTickets: %v", err)
+			}
+			keys := make([]string, 0, len(stored))
+			for key := range stored {
+				keys = append(keys, key)
+			}
+			slices.SortFunc(keys, common.CompareIssueKeys)
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("stored %v, want %v", keys, tt.want)
+			}
+		})
+	}
+
+	// The mentions stay in the text of the issue that made them
+	c := newTestCollector(t, nil)
+	c.receive(t, testSiteURL+"/browse/ABC-7", readFixture(t, "issue_mentions.html"))
+	ticket, err := c.storage.GetTicket("ABC", "ABC-7")
+	if err != nil || ticket == nil {
+		t.Fatalf("GetTicket(ABC-7) = %v, %v", ticket, err)
+	}
+	if ticket.Description != "Broken since XYZ-9 shipped. Same cause as ABC-8." {
+		t.Errorf("description = %q", ticket.Description)
+	}
+}
+
+// TestReceiverCapsPreExtractedReferences posts a tickets array from the
+// extension. Entries with nothing but a key are marked as references and
+// capped by receiver.max_reference_tickets like parsed key-only issues.
+func TestReceiverCapsPreExtractedReferences(t *testing.T) {
+	c := newTestCollector(t, func(config *common.Config) {
+		config.Receiver.MaxReferenceTickets = 2
+	})
+
+	payload := receiverPayload(testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+	payload["data"].(map[string]interface{})["tickets"] = []interface{}{
+		map[string]interface{}{"key": "ABC-1", "summary": "First issue", "status": "Open"},
+		map[string]interface{}{"key": "ABC-20"},
+		map[string]interface{}{"key": "ABC-21", "project_id": "ABC"},
+		map[string]interface{}{"key": "ABC-22"},
+		map[string]interface{}{"key": "ABC-23"},
+	}
+	var response handlers.ReceiverResponse
+	if status := c.post(t, "/receiver", payload, &response); status != http.StatusOK {
+		t.Fatalf("POST /receiver status = %d, response %+v", status, response)
+	}
+
+	stored, err := c.storage.LoadTickets("ABC")
+	if err != nil {
+		t.Fatalf("LoadTickets: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Errorf("stored %d tickets, want ABC-1 and two references", len(stored))
+	}
+	if ticket := stored["ABC-1"]; ticket == nil || ticket.Source == models.SourceReference {
+		t.Errorf("ABC-1 = %+v, want a collected ticket", ticket)
+	}
+	for _, key := range []string{"ABC-20", "ABC-21"} {
+		if ticket := stored[key]; ticket == nil || ticket.Source != models.SourceReference {
+			t.Errorf("%s = %+v, want a reference", key, ticket)
+		}
+	}
+}
+
+func TestReceiverStoresProjectsList(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	response := c.receive(t, testSiteURL+"/jira/projects", readFixture(t, "projects.html"))
+	if response.PageType != "projectsList" || response.Stats == nil || response.Stats.ProjectsAdded != 2 {
+		t.Fatalf("page type %s, stats %+v, want 2 projects added", response.PageType, response.Stats)
+	}
+
+	projects, err := c.storage.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects: %v", err)
+	}
+	names := map[string]string{}
+	for _, project := range projects {
+		names[project.Key] = project.Name
+	}
+	if names["ABC"] != "Alpha" || names["XYZ"] != "Xylophone" {
+		t.Errorf("stored projects = %v", names)
+	}
+}
+
+func TestReceiverSkipsNonCollectablePage(t *testing.T) {
+	c := newTestCollector(t, nil)
+	conn := c.dial(t)
+
+	response := c.receive(t, "https://example.com/about", "<html><body><p>Nothing to see here</p></body></html>")
+	if !response.Success || response.Stats != nil {
+		t.Errorf("response = %+v, want success without stats", response)
+	}
+
+	readEvent(t, conn, "collection_skipped")
+	if tickets, err := c.storage.LoadAllTickets(); err != nil || len(tickets) != 0 {
+		t.Errorf("stored tickets = %d, %v, want none", len(tickets), err)
+	}
+}
+
+// TestReceiverListPageKeepsDetailFields stores a ticket as collected from its
+// detail page, then receives a list page with the same key. The sparse list
+// row must not erase anything the detail page provided.
+func TestReceiverListPageKeepsDetailFields(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	detail := &models.TicketData{
+		Key:          "ABC-7",
+		ProjectID:    "ABC",
+		URL:          testSiteURL + "/browse/ABC-7",
+		Summary:      "Fix login",
+		Description:  "Login is broken after the upgrade",
+		IssueType:    "Bug",
+		Status:       "In Progress",
+		Priority:     "High",
+		Created:      "2024-01-02T10:00:00Z",
+		Reporter:     "bob",
+		Assignee:     "alice",
+		Labels:       []string{"backend", "login"},
+		Components:   []string{"Auth"},
+		CustomFields: map[string]interface{}{"story_points": 3.0},
+		Comments:     []models.Comment{{ID: "c1", Author: "bob", Body: "Seen in production"}},
+		Subtasks:     []models.Subtask{{Key: "ABC-8", Summary: "Write test", Status: "Open"}},
+		Attachments:  []models.Attachment{{ID: "a1", Filename: "log.txt"}},
+		Source:       "extension",
+	}
+	if _, err := c.storage.SaveTickets("ABC", map[string]*models.TicketData{"ABC-7": detail}); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+
+	// Rows ABC-1 to ABC-10 with a summary and status each
+	response := c.receive(t, testSiteURL+"/projects/ABC/issues", issueListPage(10))
+	if response.Stats == nil || response.Stats.TicketsAdded != 9 || response.Stats.TicketsTotal != 10 {
+		t.Fatalf("stats = %+v, want 9 added of 10", response.Stats)
+	}
+
+	ticket, err := c.storage.GetTicket("ABC", "ABC-7")
+	if err != nil || ticket == nil {
+		t.Fatalf("GetTicket(ABC-7) = %v, %v", ticket, err)
+	}
+
+	// The list row's own values win
+	if ticket.Summary != "Issue number 7" || ticket.Status != "Open" {
+		t.Errorf("summary %q, status %q, want the list page values", ticket.Summary, ticket.Status)
+	}
+
+	kept := detail
+	kept.Summary, kept.Status = ticket.Summary, ticket.Status
+	checks := []struct {
+		field     string
+		got, want interface{}
+	}{
+		{"description", ticket.Description, kept.Description},
+		{"issue type", ticket.IssueType, kept.IssueType},
+		{"priority", ticket.Priority, kept.Priority},
+		{"created", ticket.Created, kept.Created},
+		{"reporter", ticket.Reporter, kept.Reporter},
+		{"assignee", ticket.Assignee, kept.Assignee},
+		{"labels", ticket.Labels, kept.Labels},
+		{"components", ticket.Components, kept.Components},
+		{"custom fields", ticket.CustomFields, kept.CustomFields},
+		{"comments", ticket.Comments, kept.Comments},
+		{"subtasks", ticket.Subtasks, kept.Subtasks},
+		{"attachments", ticket.Attachments, kept.Attachments},
+		{"source", ticket.Source, kept.Source},
+	}
+	for _, check := range checks {
+		if !reflect.DeepEqual(check.got, check.want) {
+			t.Errorf("%s = %v, want %v", check.field, check.got, check.want)
+		}
+	}
+}
+
+// TestReceiverIgnoresPagesLoadedBeforeClear covers a stale extension tab
+// re-posting a page after its project was cleared: the tickets must not
+// come back unless the page was loaded after the clear or ?force=true is
+// given
+func TestReceiverIgnoresPagesLoadedBeforeClear(t *testing.T) {
+	c := newTestCollector(t, nil)
+	pageURL := testSiteURL + "/projects/ABC/issues"
+	page := readFixture(t, "issue_list.html")
+
+	// receiveLoadedAt posts the list page as loaded in the browser at loaded
+	// and returns the number of tickets stored for ABC afterwards
+	receiveLoadedAt := func(t *testing.T, loaded time.Time, query string) int {
+		t.Helper()
+		payload := receiverPayload(pageURL, page)
+		payload["data"].(map[string]interface{})["page_loaded_at"] = loaded.UTC().Format(time.RFC3339Nano)
+		var response handlers.ReceiverResponse
+		if status := c.post(t, "/receiver"+query, payload, &response); status != http.StatusOK || !response.Success {
+			t.Fatalf("status = %d, response %+v", status, response)
+		}
+		tickets, err := c.storage.LoadTickets("ABC")
+		if err != nil {
+			t.Fatalf("LoadTickets: %v", err)
+		}
+		return len(tickets)
+	}
+
+	loadedBeforeClear := time.Now().Add(-time.Minute)
+	if n := receiveLoadedAt(t, loadedBeforeClear, ""); n != 3 {
+		t.Fatalf("stored %d tickets before the clear, want 3", n)
+	}
+	if _, err := c.storage.ClearProjectTickets("ABC"); err != nil {
+		t.Fatalf("ClearProjectTickets: %v", err)
+	}
+
+	if n := receiveLoadedAt(t, loadedBeforeClear, ""); n != 0 {
+		t.Errorf("stale page after project clear stored %d tickets, want 0", n)
+	}
+	if metricsText := c.metricsText(); !strings.Contains(metricsText, "receiver_tombstoned_tickets_total 3") {
+		t.Errorf("metrics do not count the 3 ignored tickets:\n%s", metricsText)
+	}
+
+	if n := receiveLoadedAt(t, loadedBeforeClear, "?force=true"); n != 3 {
+		t.Errorf("stale page with force=true stored %d tickets, want 3", n)
+	}
+
+	if err := c.storage.ClearAllTickets(); err != nil {
+		t.Fatalf("ClearAllTickets: %v", err)
+	}
+	if n := receiveLoadedAt(t, loadedBeforeClear, ""); n != 0 {
+		t.Errorf("stale page after clearing all tickets stored %d tickets, want 0", n)
+	}
+	if n := receiveLoadedAt(t, time.Now(), ""); n != 3 {
+		t.Errorf("page loaded after the clear stored %d tickets, want 3", n)
+	}
+}
+
+func TestReceiverTombstonesDisabled(t *testing.T) {
+	c := newTestCollector(t, func(config *common.Config) {
+		config.Storage.TombstoneHours = 0
+	})
+	if _, err := c.storage.ClearProjectTickets("ABC"); err != nil {
+		t.Fatalf("ClearProjectTickets: %v", err)
+	}
+
+	payload := receiverPayload(testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+	payload["data"].(map[string]interface{})["page_loaded_at"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	var response handlers.ReceiverResponse
+	if status := c.post(t, "/receiver", payload, &response); status != http.StatusOK {
+		t.Fatalf("status = %d, response %+v", status, response)
+	}
+	if response.Stats == nil || response.Stats.TicketsAdded != 3 {
+		t.Errorf("stats = %+v, want 3 tickets added with tombstone_hours = 0", response.Stats)
+	}
+}
+
+// TestReceiverRejectsMalformedKeys posts tickets the extension extracted
+// itself, so their keys have not been through the parser's key pattern
+func TestReceiverRejectsMalformedKeys(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	tickets := []interface{}{
+		map[string]interface{}{"key": "ABC-5", "summary": "Valid ticket"},
+	}
+	malformed := []string{"abc-1", "1ABC-2", "ABC_3", "ABC-", "_ABC-4", "ABC"}
+	for _, key := range malformed {
+		tickets = append(tickets, map[string]interface{}{"key": key, "summary": "Malformed " + key})
+	}
+	payload := receiverPayload(testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+	payload["data"].(map[string]interface{})["tickets"] = tickets
+
+	var response handlers.ReceiverResponse
+	if status := c.post(t, "/receiver", payload, &response); status != http.StatusOK {
+		t.Fatalf("status = %d, response %+v", status, response)
+	}
+
+	stored, err := c.storage.LoadAllTickets()
+	if err != nil {
+		t.Fatalf("LoadAllTickets: %v", err)
+	}
+	if len(stored) != 1 || stored["ABC-5"] == nil {
+		keys := make([]string, 0, len(stored))
+		for key := range stored {
+			keys = append(keys, key)
+		}
+		t.Errorf("stored keys = %v, want only ABC-5", keys)
+	}
+
+	if want, metricsText := fmt.Sprintf("receiver_invalid_keys_total %d", len(malformed)), c.metricsText(); !strings.Contains(metricsText, want) {
+		t.Errorf("metrics do not contain %q:\n%s", want, metricsText)
+	}
+}
+
+func TestReceiverEmptyParse(t *testing.T) {
+	c := newTestCollector(t, nil)
+	conn := c.dial(t)
+
+	// The projects directory is collectable by its URL; before its table has
+	// rendered the page yields nothing
+	page := `<html><head><title>Projects - Jira</title></head><body><div id="root"></div></body></html>`
+	var response handlers.ReceiverResponse
+	status := c.post(t, "/receiver", receiverPayload(testSiteURL+"/jira/projects", page), &response)
+	if status != http.StatusUnprocessableEntity || response.Success {
+		t.Fatalf("status = %d, response %+v; want 422", status, response)
+	}
+	if details, _ := response.Data.(map[string]interface{}); response.Error != "no data found in HTML" || details["status"] != "parsed_empty" || response.PageType != "projectsList" {
+		t.Errorf("response = %+v", response)
+	}
+
+	msg := readEvent(t, conn, "collection_empty")
+	if data, _ := msg["data"].(map[string]interface{}); data["transaction_id"] != response.TransactionID {
+		t.Errorf("collection_empty event = %v", msg["data"])
+	}
+	if tickets, err := c.storage.LoadAllTickets(); err != nil || len(tickets) != 0 {
+		t.Errorf("stored tickets = %d, %v", len(tickets), err)
+	}
+}
+
+func TestReceiverMissingHTML(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	// The projects directory is collectable by its URL alone, so a payload
+	// without its HTML is an empty parse rather than a success
+	for name, data := range map[string]map[string]interface{}{
+		"empty html":   {"html": ""},
+		"missing html": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload := receiverPayload(testSiteURL+"/jira/projects", "")
+			payload["data"] = data
+			var response handlers.ReceiverResponse
+			status := c.post(t, "/receiver", payload, &response)
+			if details, _ := response.Data.(map[string]interface{}); status != http.StatusUnprocessableEntity || response.Success || details["status"] != "parsed_empty" {
+				t.Errorf("status = %d, response %+v; want 422 parsed_empty", status, response)
+			}
+		})
+	}
+	if projects, err := c.storage.LoadProjects(); err != nil || len(projects) != 0 {
+		t.Errorf("stored projects = %v, %v", projects, err)
+	}
+}
+
+// serveReceiver calls the receiver handler directly with ctx as the request
+// context, which an httptest server cannot control
+func serveReceiver(t *testing.T, c *testCollector, ctx context.Context, pageURL, pageHTML string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(receiverPayload(pageURL, pageHTML))
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/receiver", bytes.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c.api.ReceiverHandler(rec, req)
+	return rec
+}
+
+func TestReceiverParseTimeout(t *testing.T) {
+	c := newTestCollector(t, nil)
+	conn := c.dial(t)
+
+	// A deadline that has already passed stops the receiver in its first phase
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	rec := serveReceiver(t, c, ctx, testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+
+	var response handlers.ReceiverResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if details, _ := response.Data.(map[string]interface{}); rec.Code != http.StatusRequestTimeout || response.Error != "parse_timeout" || details["phase"] != "html_parse" {
+		t.Fatalf("status = %d, response %+v; want 408 in phase html_parse", rec.Code, response)
+	}
+
+	msg := readEvent(t, conn, "collection_failed")
+	if data, _ := msg["data"].(map[string]interface{}); data["transaction_id"] != response.TransactionID {
+		t.Errorf("collection_failed event = %v", msg["data"])
+	}
+	if want, metricsText := `receiver_parse_timeouts_total{phase="html_parse"} 1`, c.metricsText(); !strings.Contains(metricsText, want) {
+		t.Errorf("metrics do not contain %q:\n%s", want, metricsText)
+	}
+	if tickets, err := c.storage.LoadAllTickets(); err != nil || len(tickets) != 0 {
+		t.Errorf("stored tickets = %d, %v", len(tickets), err)
+	}
+}
+
+func TestReceiverClientDisconnect(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	// Nobody is listening after a disconnect: the large page is dropped after
+	// building its tree, without a response, and only counted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := serveReceiver(t, c, ctx, testSiteURL+"/projects/ABC/issues", issueListPage(2000))
+
+	if rec.Body.Len() != 0 {
+		t.Errorf("response written after disconnect: %d %s", rec.Code, rec.Body.String())
+	}
+	if want, metricsText := `receiver_parse_abandoned_total{phase="html_parse"} 1`, c.metricsText(); !strings.Contains(metricsText, want) {
+		t.Errorf("metrics do not contain %q:\n%s", want, metricsText)
+	}
+	if metricsText := c.metricsText(); strings.Contains(metricsText, "receiver_parse_timeouts_total") {
+		t.Errorf("a disconnect was counted as a timeout:\n%s", metricsText)
+	}
+	if tickets, err := c.storage.LoadAllTickets(); err != nil || len(tickets) != 0 {
+		t.Errorf("stored tickets = %d, %v", len(tickets), err)
+	}
+}
+
+// TestReceiverConcurrentLoad fires concurrent /receiver posts of a 200 row
+// issue list and logs the p95 latency and allocations per request. Run with
+// -v to see the numbers.
+func TestReceiverConcurrentLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("load test skipped in short mode")
+	}
+	const (
+		requests = 64
+		workers  = 8
+		rows     = 200
+	)
+	c := newTestCollector(t, nil)
+	body, err := json.Marshal(receiverPayload(testSiteURL+"/projects/ABC/issues", issueListPage(rows)))
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+
+	var memBefore, memAfter runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&memBefore)
+
+	latencies := make([]time.Duration, requests)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				resp, err := http.Post(c.url+"/receiver", "application/json", bytes.NewReader(body))
+				latencies[i] = time.Since(start)
+				if err != nil {
+					t.Errorf("request %d: %v", i, err)
+					continue
+				}
+				var response handlers.ReceiverResponse
+				json.NewDecoder(resp.Body).Decode(&response)
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK || !response.Success {
+					t.Errorf("request %d: status %d, response %+v", i, resp.StatusCode, response)
+				}
+			}
+		}()
+	}
+	for i := 0; i < requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	runtime.ReadMemStats(&memAfter)
+	slices.Sort(latencies)
+	t.Logf("%d requests, %d concurrent, %d rows: p50 %v, p95 %v, max %v, %d allocs and %d KB per request",
+		requests, workers, rows,
+		latencies[requests/2], latencies[requests*95/100], latencies[requests-1],
+		(memAfter.Mallocs-memBefore.Mallocs)/requests, (memAfter.TotalAlloc-memBefore.TotalAlloc)/requests/1024)
+
+	tickets, err := c.storage.LoadTickets("ABC")
+	if err != nil {
+		t.Fatalf("LoadTickets: %v", err)
+	}
+	if len(tickets) != rows {
+		t.Errorf("stored %d tickets, want %d", len(tickets), rows)
+	}
+}
+
+// TestReceiverConcurrentOverlappingPages posts different pages for one
+// project at the same time. Each page has tickets of its own and shares a
+// few keys with the others, setting a different field on them, so a save
+// that lost another request's update would drop a field or a label.
+func TestReceiverConcurrentOverlappingPages(t *testing.T) {
+	c := newTestCollector(t, nil)
+	fields := []string{"summary", "description", "status", "priority", "reporter", "assignee", "issue_type", "components"}
+	shared := []string{"ABC-1", "ABC-2", "ABC-3", "ABC-4"}
+	const ownTickets = 5
+
+	added := make([]int, len(fields))
+	var wg sync.WaitGroup
+	for worker, field := range fields {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var tickets []interface{}
+			for _, key := range shared {
+				ticket := map[string]interface{}{"key": key, "labels": []string{fmt.Sprintf("worker-%d", worker)}}
+				if field == "components" {
+					ticket[field] = []string{"Backend"}
+				} else {
+					ticket[field] = fmt.Sprintf("%s from worker %d", field, worker)
+				}
+				tickets = append(tickets, ticket)
+			}
+			for i := 0; i < ownTickets; i++ {
+				key := fmt.Sprintf("ABC-%d", 100+worker*10+i)
+				tickets = append(tickets, map[string]interface{}{"key": key, "summary": "Own ticket " + key})
+			}
+			payload := receiverPayload(testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+			payload["data"].(map[string]interface{})["tickets"] = tickets
+
+			var response handlers.ReceiverResponse
+			if status := c.post(t, "/receiver", payload, &response); status != http.StatusOK || response.Stats == nil {
+				t.Errorf("worker %d: status %d, response %+v", worker, status, response)
+				return
+			}
+			added[worker] = response.Stats.TicketsAdded
+		}()
+	}
+	wg.Wait()
+
+	stored, err := c.storage.LoadTickets("ABC")
+	if err != nil {
+		t.Fatalf("LoadTickets: %v", err)
+	}
+	if want := len(shared) + len(fields)*ownTickets; len(stored) != want {
+		t.Errorf("stored %d tickets, want %d", len(stored), want)
+	}
+	for _, key := range shared {
+		ticket := stored[key]
+		if ticket == nil {
+			t.Errorf("%s was not stored", key)
+			continue
+		}
+		values := map[string]string{
+			"summary":     ticket.Summary,
+			"description": ticket.Description,
+			"status":      ticket.Status,
+			"priority":    ticket.Priority,
+			"reporter":    ticket.Reporter,
+			"assignee":    ticket.Assignee,
+			"issue_type":  ticket.IssueType,
+			"components":  strings.Join(ticket.Components, ","),
+		}
+		for worker, field := range fields {
+			want := fmt.Sprintf("%s from worker %d", field, worker)
+			if field == "components" {
+				want = "Backend"
+			}
+			if values[field] != want {
+				t.Errorf("%s %s = %q, want %q", key, field, values[field], want)
+			}
+			if label := fmt.Sprintf("worker-%d", worker); !slices.Contains(ticket.Labels, label) {
+				t.Errorf("%s labels = %v, missing %s", key, ticket.Labels, label)
+			}
+		}
+	}
+
+	// Every new ticket is counted by exactly one of the requests
+	total := 0
+	for _, n := range added {
+		total += n
+	}
+	if total != len(stored) {
+		t.Errorf("tickets_added across responses = %d (%v), stored %d", total, added, len(stored))
+	}
+}
+
+// failingStorage fails SaveTickets for one project
+type failingStorage struct {
+	interfaces.Storage
+	project string
+}
+
+func (s failingStorage) SaveTickets(projectKey string, tickets map[string]*models.TicketData) (int, error) {
+	if projectKey == s.project {
+		return 0, fmt.Errorf("write to %s refused", projectKey)
+	}
+	return s.Storage.SaveTickets(projectKey, tickets)
+}
+
+func TestReceiverReportsFailedProjectSave(t *testing.T) {
+	c := newTestCollector(t, nil)
+	api := handlers.NewAPIHandlers(common.DefaultConfig(), failingStorage{Storage: c.storage, project: "XYZ"}, arbor.NewLogger(), services.NewPageAssessor(arbor.NewLogger()), nil, nil, nil)
+
+	payload := receiverPayload(testSiteURL+"/projects/ABC/issues", readFixture(t, "issue_list.html"))
+	payload["data"].(map[string]interface{})["tickets"] = []interface{}{
+		map[string]interface{}{"key": "ABC-1", "summary": "Saved"},
+		map[string]interface{}{"key": "ABC-2", "summary": "Saved"},
+		map[string]interface{}{"key": "XYZ-1", "summary": "Refused"},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	api.ReceiverHandler(rec, httptest.NewRequest(http.MethodPost, "/receiver", bytes.NewReader(body)))
+
+	var response handlers.ReceiverResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError || response.Success || !strings.Contains(response.Error, "failed to save tickets for XYZ (2 other tickets stored)") {
+		t.Fatalf("status = %d, response %+v; want 500 naming XYZ", rec.Code, response)
+	}
+	if tickets, err := c.storage.LoadTickets("ABC"); err != nil || len(tickets) != 2 {
+		t.Errorf("stored ABC tickets = %d, %v; want 2", len(tickets), err)
+	}
+}
+
+// webServerConfig returns the default configuration with the database and
+// extension directory in a temporary directory
+func webServerConfig(t *testing.T) *common.Config {
+	t.Helper()
+	config := common.DefaultConfig()
+	dir := t.TempDir()
+	config.Storage.DatabasePath = filepath.Join(dir, "test.db")
+	config.Storage.BackupDir = filepath.Join(dir, "backups")
+	config.Collector.ExtensionDir = filepath.Join(dir, "extension")
+	return config
+}
+
+// sendJSON sends body as JSON with apiKey, when set, and decodes the JSON
+// response into out
+func sendJSON(t *testing.T, method, url, apiKey string, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encoding request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decoding %s %s response: %v", method, url, err)
+	}
+	return resp.StatusCode
+}
+
+// TestWebServerStoresBoardPage sends a board page through the server's own
+// routes, so the API key check and JSON errors apply as in production
+func TestWebServerStoresBoardPage(t *testing.T) {
+	const apiKey = "test-key"
+	config := webServerConfig(t)
+	config.Collector.APIKey = apiKey
+	baseURL := startWebServer(t, config)
+
+	pageURL := testSiteURL + "/secure/RapidBoard.jspa?rapidView=1"
+	page := readFixture(t, "board.html")
+
+	var failure struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+		Status  int    `json:"status"`
+	}
+	assessBody := map[string]string{"url": pageURL, "html": page}
+	if status := sendJSON(t, http.MethodPost, baseURL+"/assess", "", assessBody, &failure); status != http.StatusUnauthorized || failure.Status != status {
+		t.Errorf("POST /assess without the API key: status = %d, response %+v", status, failure)
+	}
+
+	var assessment struct {
+		Assessment struct {
+			PageType    string `json:"page_type"`
+			Collectable bool   `json:"collectable"`
+		} `json:"assessment"`
+	}
+	if status := sendJSON(t, http.MethodPost, baseURL+"/assess", apiKey, assessBody, &assessment); status != http.StatusOK {
+		t.Fatalf("POST /assess status = %d", status)
+	}
+	if assessment.Assessment.PageType != "board" || !assessment.Assessment.Collectable {
+		t.Fatalf("assessment = %+v, want a collectable board", assessment.Assessment)
+	}
+
+	var response handlers.ReceiverResponse
+	if status := sendJSON(t, http.MethodPost, baseURL+"/receiver", apiKey, receiverPayload(pageURL, page), &response); status != http.StatusOK {
+		t.Fatalf("POST /receiver status = %d, response %+v", status, response)
+	}
+	if response.PageType != "board" || response.Stats == nil || response.Stats.TicketsAdded != 3 {
+		t.Fatalf("receiver response: page type %s, stats %+v", response.PageType, response.Stats)
+	}
+
+	// Board cards give only keys, so the tickets are stored as references
+	var tickets struct {
+		Tickets []*models.TicketData `json:"tickets"`
+	}
+	if status := sendJSON(t, http.MethodGet, baseURL+"/tickets?project=ABC&include_references=true", apiKey, nil, &tickets); status != http.StatusOK {
+		t.Fatalf("GET /tickets status = %d", status)
+	}
+	var keys []string
+	for _, ticket := range tickets.Tickets {
+		keys = append(keys, ticket.Key)
+		if ticket.Source != models.SourceReference {
+			t.Errorf("%s source = %q, want %q", ticket.Key, ticket.Source, models.SourceReference)
+		}
+	}
+	if !slices.Equal(keys, []string{"ABC-3", "ABC-4", "ABC-5"}) {
+		t.Errorf("stored %v, want ABC-3, ABC-4 and ABC-5", keys)
+	}
+
+	if status := sendJSON(t, http.MethodPatch, baseURL+"/receiver", apiKey, nil, &failure); status != http.StatusMethodNotAllowed || failure.Status != status {
+		t.Errorf("PATCH /receiver: status = %d, response %+v", status, failure)
+	}
+	if status := sendJSON(t, http.MethodGet, baseURL+"/no/such/route", apiKey, nil, &failure); status != http.StatusNotFound || failure.Status != status {
+		t.Errorf("GET /no/such/route: status = %d, response %+v", status, failure)
+	}
+}
+
+// TestWebServerReadOnlyRejectsReceiver checks a read-only server refuses
+// pages at the route rather than storing them
+func TestWebServerReadOnlyRejectsReceiver(t *testing.T) {
+	config := webServerConfig(t)
+	storage, err := services.NewStorage(&config.Storage)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	storage.Close()
+	config.Storage.ReadOnly = true
+	baseURL := startWebServer(t, config)
+
+	var failure struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+		Code   string `json:"code"`
+	}
+	payload := receiverPayload(testSiteURL+"/secure/RapidBoard.jspa?rapidView=1", readFixture(t, "board.html"))
+	if status := sendJSON(t, http.MethodPost, baseURL+"/receiver", "", payload, &failure); status != http.StatusForbidden || failure.Code != "read_only" {
+		t.Errorf("POST /receiver: status = %d, response %+v", status, failure)
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+const (
+	// reprocessProgressEvery is how many tickets are processed between
+	// reprocess_progress WebSocket events
+	reprocessProgressEvery = 25
+	// maxReprocessFailures bounds the failed keys kept in ReprocessStatus
+	maxReprocessFailures = 100
+)
+
+// ReprocessStatus reports the progress of a run that re-parses stored raw HTML
+type ReprocessStatus struct {
+	Running    bool       `json:"running"`
+	Project    string     `json:"project,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Changed    int        `json:"changed"`
+	Unchanged  int        `json:"unchanged"`
+	Failed     int        `json:"failed"`
+	FailedKeys []string   `json:"failed_keys,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ReprocessHandler re-runs the parser over tickets stored with raw HTML.
+// POST starts a run in the background, optionally limited by the project or
+// key query parameters, and GET returns the progress of the latest run.
+// Progress is also broadcast as reprocess_progress and reprocess_complete
+// WebSocket events.
+func (h *APIHandlers) ReprocessHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.reprocessMu.Lock()
+		var status *ReprocessStatus
+		if h.reprocess != nil {
+			snapshot := *h.reprocess
+			status = &snapshot
+		}
+		h.reprocessMu.Unlock()
+
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"reprocess": status,
+		})
+	case http.MethodPost:
+		h.startReprocess(w, r)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *APIHandlers) startReprocess(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	project := strings.ToUpper(query.Get("project"))
+	key := strings.ToUpper(query.Get("key"))
+
+	h.reprocessMu.Lock()
+	if h.reprocess != nil && h.reprocess.Running {
+		h.reprocessMu.Unlock()
+		respondError(w, r, http.StatusConflict, "Reprocessing is already running")
+		return
+	}
+	h.reprocessMu.Unlock()
+
+	tickets, err := h.reprocessCandidates(project, key)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load tickets for reprocessing")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	status := &ReprocessStatus{
+		Running:   true,
+		Project:   project,
+		Key:       key,
+		Total:     len(tickets),
+		StartedAt: time.Now(),
+	}
+
+	h.reprocessMu.Lock()
+	if h.reprocess != nil && h.reprocess.Running {
+		h.reprocessMu.Unlock()
+		respondError(w, r, http.StatusConflict, "Reprocessing is already running")
+		return
+	}
+	h.reprocess = status
+	snapshot := *status
+	h.reprocessMu.Unlock()
+
+	h.logger.Info().
+		Str("project", project).
+		Str("key", key).
+		Int("tickets", len(tickets)).
+		Msg("Reprocessing stored raw HTML")
+
+	go h.runReprocess(tickets)
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success":   true,
+		"message":   fmt.Sprintf("Reprocessing %d ticket(s)", len(tickets)),
+		"reprocess": snapshot,
+	})
+}
+
+// reprocessCandidates returns the stored tickets that have raw HTML, limited
+// to one ticket or project when given
+func (h *APIHandlers) reprocessCandidates(project, key string) ([]*models.TicketData, error) {
+	var stored map[string]*models.TicketData
+	switch {
+	case key != "":
+		ticket, err := loadTicket(h.storage, key)
+		if err != nil {
+			return nil, err
+		}
+		stored = make(map[string]*models.TicketData)
+		if ticket != nil {
+			stored[ticket.Key] = ticket
+		}
+	case project != "":
+		var err error
+		if stored, err = h.storage.LoadTickets(project); err != nil {
+			return nil, err
+		}
+	default:
+		var err error
+		if stored, err = h.storage.LoadAllTickets(); err != nil {
+			return nil, err
+		}
+	}
+
+	tickets := make([]*models.TicketData, 0, len(stored))
+	for _, ticket := range stored {
+		if ticket.RawHTML != "" {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+func (h *APIHandlers) runReprocess(tickets []*models.TicketData) {
+	for i, ticket := range tickets {
+		changed, err := h.reprocessTicket(ticket)
+
+		h.reprocessMu.Lock()
+		status := h.reprocess
+		status.Processed++
+		switch {
+		case err != nil:
+			status.Failed++
+			if len(status.FailedKeys) < maxReprocessFailures {
+				status.FailedKeys = append(status.FailedKeys, ticket.Key)
+			}
+		case changed:
+			status.Changed++
+		default:
+			status.Unchanged++
+		}
+		snapshot := *status
+		h.reprocessMu.Unlock()
+
+		if err != nil {
+			h.parserLogger.Warn().Err(err).Str("key", ticket.Key).Msg("Failed to reprocess ticket")
+		}
+		if h.wsHub != nil && (i+1)%reprocessProgressEvery == 0 {
+			h.wsHub.SendCollectionUpdate("reprocess_progress", snapshot)
+		}
+	}
+
+	finished := time.Now()
+	h.reprocessMu.Lock()
+	h.reprocess.Running = false
+	h.reprocess.FinishedAt = &finished
+	snapshot := *h.reprocess
+	h.reprocessMu.Unlock()
+
+	h.logger.Info().
+		Int("changed", snapshot.Changed).
+		Int("unchanged", snapshot.Unchanged).
+		Int("failed", snapshot.Failed).
+		Dur("duration", finished.Sub(snapshot.StartedAt)).
+		Msg("Reprocessing completed")
+
+	if h.wsHub != nil {
+		h.wsHub.SendCollectionUpdate("reprocess_complete", snapshot)
+	}
+}
+
+// reprocessTicket parses a ticket's raw HTML with the current parser and
+// saves the result when it changes the stored ticket
+func (h *APIHandlers) reprocessTicket(ticket *models.TicketData) (bool, error) {
+	projectKey, _, found := strings.Cut(ticket.Key, "-")
+	if !found || projectKey == "" {
+		return false, fmt.Errorf("could not extract project key from %s", ticket.Key)
+	}
+
+	// The issue parser finds the key in the page URL
+	url := ticket.URL
+	synthesizedURL := !strings.Contains(url, "/browse/"+ticket.Key)
+	if synthesizedURL {
+		url = common.ResolveURL(url, "/browse/"+ticket.Key)
+	}
+
+	results, err := NewJiraParser().ParseHTML(context.Background(), ticket.RawHTML, "issue", url)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	if len(results) == 0 {
+		return false, errParsedEmpty
+	}
+
+	issue := results[0]
+	if synthesizedURL {
+		delete(issue, "url")
+	}
+	incoming := ticketFromIssue(issue, ticket.Key, projectKey, "")
+	incoming.Source = ""
+
+	if reflect.DeepEqual(models.MergeTicket(ticket, incoming), ticket) {
+		return false, nil
+	}
+
+	incoming.Source = "reprocess"
+	if _, err := h.storage.SaveTickets(projectKey, map[string]*models.TicketData{ticket.Key: incoming}); err != nil {
+		h.errorTracker.Record("storage", common.WrapError(err, common.ErrorTypeStorage, "save_tickets", "Failed to save reprocessed ticket "+ticket.Key))
+		return false, err
+	}
+	return true, nil
+}
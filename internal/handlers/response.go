@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/middleware"
+)
+
+// respondJSON writes data as a JSON response with the given status code
+func respondJSON(w http.ResponseWriter, status int, data interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(data)
+}
+
+// respondError writes a JSON error response
+func respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	middleware.WriteError(w, r, status, message)
+}
+
+// respondFailure writes err as a JSON error response. The status code and
+// message come from a wrapped CollectorError; other errors are reported as an
+// internal server error. Details are only included in development, so
+// production responses never expose storage paths or parser internals.
+func respondFailure(w http.ResponseWriter, r *http.Request, err error, development bool) {
+	resp := middleware.ErrorResponse{
+		Error:  "Internal server error",
+		Status: errorStatus(err),
+	}
+
+	var collectorErr *common.CollectorError
+	if errors.As(err, &collectorErr) {
+		resp.Error = collectorErr.Message
+		resp.Code = collectorErr.Code
+		if development {
+			resp.Details = collectorErr.Details
+			if collectorErr.Cause != nil {
+				resp.Details = collectorErr.Cause.Error()
+			}
+		}
+	} else if development {
+		resp.Details = err.Error()
+	}
+
+	middleware.WriteErrorResponse(w, r, resp)
+}
+
+// errorStatus maps the CollectorError type wrapped by err to an HTTP status
+func errorStatus(err error) int {
+	var collectorErr *common.CollectorError
+	if !errors.As(err, &collectorErr) {
+		return http.StatusInternalServerError
+	}
+
+	switch collectorErr.Type {
+	case common.ErrorTypeValidation:
+		return http.StatusBadRequest
+	case common.ErrorTypeAuth:
+		if collectorErr.Code == "forbidden" {
+			return http.StatusForbidden
+		}
+		return http.StatusUnauthorized
+	case common.ErrorTypeNotFound:
+		return http.StatusNotFound
+	case common.ErrorTypeStorage, common.ErrorTypeNetwork:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// NotFound responds to unknown routes with a JSON 404
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	respondError(w, r, http.StatusNotFound, "Not found: "+r.URL.Path)
+}
+
+// MethodNotAllowed responds to a route registered only for other methods
+// with a JSON 405 listing the methods it accepts
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	methodNotAllowed(w, r, allowed...)
+}
+
+// methodNotAllowed responds with a JSON 405 and the methods the route accepts
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	respondError(w, r, http.StatusMethodNotAllowed, "Method not allowed: "+r.Method)
+}
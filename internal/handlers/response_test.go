@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/middleware"
+)
+
+func TestRespondFailure(t *testing.T) {
+	cause := errors.New("open /data/tickets.db: permission denied")
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+		code    string
+	}{
+		{"validation", common.NewValidationError("invalid_key", "Invalid issue key"), http.StatusBadRequest, "Invalid issue key", "invalid_key"},
+		{"unauthorized", common.NewAuthError("missing_key", "API key required"), http.StatusUnauthorized, "API key required", "missing_key"},
+		{"forbidden", common.NewAuthError("forbidden", "Read-only mode"), http.StatusForbidden, "Read-only mode", "forbidden"},
+		{"not found", common.NewNotFoundError("ticket_not_found", "Ticket not found"), http.StatusNotFound, "Ticket not found", "ticket_not_found"},
+		{"storage", common.WrapError(cause, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), http.StatusServiceUnavailable, "Failed to load tickets", "load_tickets"},
+		{"network", common.NewNetworkError("jira_unreachable", "Jira is unreachable"), http.StatusServiceUnavailable, "Jira is unreachable", "jira_unreachable"},
+		{"other type", common.NewCollectionError("parsed_empty", "No data found"), http.StatusInternalServerError, "No data found", "parsed_empty"},
+		{"wrapped", fmt.Errorf("export: %w", common.NewNotFoundError("project_not_found", "Project not found")), http.StatusNotFound, "Project not found", "project_not_found"},
+		{"plain error", cause, http.StatusInternalServerError, "Internal server error", ""},
+	}
+	for _, tt := range tests {
+		for _, development := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/development=%v", tt.name, development), func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				respondFailure(rec, httptest.NewRequest(http.MethodGet, "/tickets", nil), tt.err, development)
+
+				if rec.Code != tt.status {
+					t.Errorf("status = %d, want %d", rec.Code, tt.status)
+				}
+				if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+					t.Errorf("Content-Type = %q", contentType)
+				}
+				var body middleware.ErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatalf("decoding response: %v", err)
+				}
+				if body.Success || body.Status != tt.status || body.Error != tt.message || body.Code != tt.code {
+					t.Errorf("body = %+v, want status %d, error %q, code %q", body, tt.status, tt.message, tt.code)
+				}
+
+				// Causes such as storage paths only reach development responses
+				if !development && body.Details != "" {
+					t.Errorf("production response has details %q", body.Details)
+				}
+				if development && errors.Is(tt.err, cause) && body.Details != cause.Error() {
+					t.Errorf("development details = %q, want the cause", body.Details)
+				}
+			})
+		}
+	}
+}
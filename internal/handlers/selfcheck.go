@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"aktis-collector-jira/internal/models"
+)
+
+// SetUIStatus records the pages directory and whether the UI handlers were
+// created from it, for the templates self-check
+func (h *APIHandlers) SetUIStatus(pagesDir string, loaded bool) {
+	h.selfCheckMu.Lock()
+	defer h.selfCheckMu.Unlock()
+	h.pagesDir = pagesDir
+	h.uiLoaded = loaded
+}
+
+// RunSelfCheck checks the templates, storage and extension release, logs
+// each problem and keeps the report for SelfCheck and /selfcheck
+func (h *APIHandlers) RunSelfCheck() *models.SelfCheckReport {
+	h.selfCheckMu.Lock()
+	defer h.selfCheckMu.Unlock()
+
+	report := &models.SelfCheckReport{Status: models.SelfCheckPass, CheckedAt: time.Now()}
+	report.Add(h.checkTemplates())
+	report.Add(h.checkStorage())
+	report.Add(h.checkExtensionRelease())
+
+	for _, check := range report.Checks {
+		if check.Status == models.SelfCheckPass {
+			continue
+		}
+		event := h.logger.Warn()
+		if check.Status == models.SelfCheckFail {
+			event = h.logger.Error()
+		}
+		event.Str("check", check.Name).Str("status", check.Status).Msg(check.Message)
+	}
+	h.logger.Info().Str("status", report.Status).Int("checks", len(report.Checks)).Msg("Self-check complete")
+
+	h.selfCheck = report
+	return report
+}
+
+// SelfCheck returns the latest self-check report, or nil before the first run
+func (h *APIHandlers) SelfCheck() *models.SelfCheckReport {
+	h.selfCheckMu.Lock()
+	defer h.selfCheckMu.Unlock()
+	return h.selfCheck
+}
+
+// checkTemplates parses the page templates again. Templates that parse now
+// but failed at startup need a restart before the UI is served.
+func (h *APIHandlers) checkTemplates() models.SelfCheckResult {
+	result := models.SelfCheckResult{Name: "templates"}
+	templates, err := parseTemplates(h.pagesDir)
+	switch {
+	case err != nil:
+		result.Status = models.SelfCheckFail
+		result.Message = fmt.Sprintf("Cannot load templates from %s, the UI is not available: %v", h.pagesDir, err)
+	case !h.uiLoaded:
+		result.Status = models.SelfCheckWarn
+		result.Message = fmt.Sprintf("Templates in %s load now; restart to serve the UI", h.pagesDir)
+	default:
+		result.Status = models.SelfCheckPass
+		result.Message = fmt.Sprintf("%d templates loaded from %s", len(templates.Templates()), h.pagesDir)
+	}
+	return result
+}
+
+// checkStorage reads the database and, unless storage is read-only, commits
+// a write probe
+func (h *APIHandlers) checkStorage() models.SelfCheckResult {
+	result := models.SelfCheckResult{Name: "storage", Status: models.SelfCheckFail}
+	if err := h.storage.Ping(); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	if h.config.Storage.ReadOnly {
+		result.Status = models.SelfCheckPass
+		result.Message = fmt.Sprintf("%s is readable; writes are disabled by storage.read_only", h.config.Storage.DatabasePath)
+		return result
+	}
+	if err := h.storage.CheckWritable(); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Status = models.SelfCheckPass
+	result.Message = fmt.Sprintf("%s is readable and writable", h.config.Storage.DatabasePath)
+	return result
+}
+
+// checkExtensionRelease confirms an extension build has been uploaded and
+// its zip is present for /extension/download
+func (h *APIHandlers) checkExtensionRelease() models.SelfCheckResult {
+	result := models.SelfCheckResult{Name: "extension", Status: models.SelfCheckFail}
+	dir := h.config.Collector.ExtensionDir
+	release, err := loadExtensionRelease(dir)
+	switch {
+	case err != nil:
+		result.Message = fmt.Sprintf("Cannot read the extension release in %s: %v", dir, err)
+	case release == nil:
+		result.Status = models.SelfCheckWarn
+		result.Message = fmt.Sprintf("No extension uploaded to %s; /extension/download returns 404 until one is posted to /extension/upload", dir)
+	default:
+		if _, err := os.Stat(filepath.Join(dir, release.File)); err != nil {
+			result.Message = fmt.Sprintf("Extension %s is recorded but its zip is missing: %v", release.Version, err)
+			break
+		}
+		result.Status = models.SelfCheckPass
+		result.Message = fmt.Sprintf("Extension %s available for download", release.Version)
+	}
+	return result
+}
+
+// SelfCheckHandler returns the latest self-check report on GET and runs the
+// checks again on POST. The status code is 200 even when checks fail, so
+// clients read the report's status.
+func (h *APIHandlers) SelfCheckHandler(w http.ResponseWriter, r *http.Request) {
+	var report *models.SelfCheckReport
+	switch r.Method {
+	case http.MethodGet:
+		if report = h.SelfCheck(); report == nil {
+			report = h.RunSelfCheck()
+		}
+	case http.MethodPost:
+		report = h.RunSelfCheck()
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+
+	if err := respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"selfcheck": report,
+	}); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode self-check response")
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/middleware"
+)
+
+// ConfigUpdate is the body of PUT /config. Only these fields can be changed
+// at runtime; omitted fields are left unchanged.
+type ConfigUpdate struct {
+	LogLevel      *string            `json:"log_level,omitempty"`
+	LogLevels     *map[string]string `json:"log_levels,omitempty"`
+	RetentionDays *int               `json:"retention_days,omitempty"`
+}
+
+// ConfigSection is a read-only group of configuration values for display
+type ConfigSection struct {
+	Title  string
+	Values []ConfigValue
+}
+
+// ConfigValue is a single flattened configuration value
+type ConfigValue struct {
+	Name  string
+	Value string
+}
+
+// SettingsPageData represents data passed to the settings page
+type SettingsPageData struct {
+	TemplateData
+	Settings  common.RuntimeSettings
+	LogLevels []string
+	Sections  []ConfigSection
+}
+
+// UpdateConfigHandler applies changes to the runtime-adjustable configuration
+func (h *APIHandlers) UpdateConfigHandler(w http.ResponseWriter, r *http.Request) {
+	var update ConfigUpdate
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		respondError(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	settings := h.config.RuntimeSettings()
+	if update.LogLevel != nil {
+		settings.LogLevel = *update.LogLevel
+	}
+	if update.LogLevels != nil {
+		settings.LogLevels = *update.LogLevels
+	}
+	if update.RetentionDays != nil {
+		settings.RetentionDays = *update.RetentionDays
+	}
+
+	if fieldErrors := settings.Validate(); len(fieldErrors) > 0 {
+		response := map[string]interface{}{
+			"success":    false,
+			"error":      "Invalid configuration",
+			"status":     http.StatusBadRequest,
+			"request_id": middleware.GetRequestID(r),
+			"errors":     fieldErrors,
+		}
+		if err := respondJSON(w, http.StatusBadRequest, response); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to encode config validation response")
+		}
+		return
+	}
+
+	if err := h.config.ApplyRuntimeSettings(settings); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to apply runtime settings")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeConfiguration, "apply_settings", "Failed to apply configuration"), h.config.IsDevelopment())
+		return
+	}
+
+	h.logger.Info().
+		Str("log_level", settings.LogLevel).
+		Str("log_levels", fmt.Sprint(settings.LogLevels)).
+		Int("retention_days", settings.RetentionDays).
+		Msg("Runtime configuration updated")
+
+	response := map[string]interface{}{
+		"success":  true,
+		"message":  "Configuration updated until the next restart",
+		"settings": settings,
+	}
+
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode config update response")
+	}
+}
+
+// SettingsPageHandler serves the runtime settings page
+func (h *UIHandlers) SettingsPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	storage, logging := h.config.RuntimeSnapshot()
+	data := SettingsPageData{
+		TemplateData: h.templateData("Settings"),
+		Settings:     h.config.RuntimeSettings(),
+		LogLevels:    common.ValidLogLevels,
+		Sections: []ConfigSection{
+			newConfigSection("Collector", h.config.Collector),
+			newConfigSection("Server", h.config.Server),
+			newConfigSection("Storage", storage, "RetentionDays"),
+			newConfigSection("Logging", logging, "Level"),
+			newConfigSection("Receiver", h.config.Receiver),
+		},
+	}
+
+	if h.config.Collector.APIKey != "" {
+		data.Sections[0].Values = append(data.Sections[0].Values, ConfigValue{Name: "APIKey", Value: "(set)"})
+	}
+
+	if err := h.executeTemplate(w, "settings.html", data); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to execute settings template")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
+	}
+}
+
+// newConfigSection flattens a config struct through its JSON form, so fields
+// tagged json:"-" stay redacted. Editable fields are listed in skip.
+func newConfigSection(title string, section interface{}, skip ...string) ConfigSection {
+	var values map[string]interface{}
+	if raw, err := json.Marshal(section); err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		decoder.Decode(&values)
+	}
+	for _, name := range skip {
+		delete(values, name)
+	}
+
+	result := ConfigSection{Title: title}
+	flattenConfig("", values, &result.Values)
+
+	sort.Slice(result.Values, func(i, j int) bool {
+		return result.Values[i].Name < result.Values[j].Name
+	})
+	return result
+}
+
+func flattenConfig(prefix string, values map[string]interface{}, out *[]ConfigValue) {
+	for name, value := range values {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenConfig(name, nested, out)
+			continue
+		}
+		if value == nil {
+			value = ""
+		}
+		*out = append(*out, ConfigValue{Name: name, Value: fmt.Sprint(value)})
+	}
+}
@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/services"
+
+	"github.com/ternarybob/arbor"
+)
+
+// TestConfigReadsDuringUpdates reads /config and the settings page while
+// PUT /config changes the runtime settings. Run with -race to check the
+// readers take the runtime lock.
+func TestConfigReadsDuringUpdates(t *testing.T) {
+	config := common.DefaultConfig()
+	config.Storage.DatabasePath = filepath.Join(t.TempDir(), "test.db")
+	storage, err := services.NewStorage(&config.Storage)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	// Runtime log levels are applied to the shared logger
+	if err := common.InitLogger(&common.LoggingConfig{Level: "info", Format: "text", Output: "console"}); err != nil {
+		t.Fatalf("InitLogger: %v", err)
+	}
+	logger := arbor.NewLogger()
+	api := handlers.NewAPIHandlers(config, storage, logger, services.NewPageAssessor(logger), nil, nil, nil)
+	ui, err := handlers.NewUIHandlers(config, storage, logger, filepath.Join("..", "..", "pages"))
+	if err != nil {
+		t.Fatalf("NewUIHandlers: %v", err)
+	}
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	run := func(name string, serve func(int) int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if status := serve(i); status != http.StatusOK {
+					t.Errorf("%s: status = %d", name, status)
+					return
+				}
+			}
+		}()
+	}
+
+	run("PUT /config", func(i int) int {
+		level := []string{"info", "debug"}[i%2]
+		body := fmt.Sprintf(`{"log_level":%q,"log_levels":{"parser":%q},"retention_days":%d}`, level, level, i)
+		rec := httptest.NewRecorder()
+		api.UpdateConfigHandler(rec, httptest.NewRequest(http.MethodPut, "/config", strings.NewReader(body)))
+		return rec.Code
+	})
+	run("GET /config", func(int) int {
+		rec := httptest.NewRecorder()
+		api.ConfigHandler(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+		return rec.Code
+	})
+	run("GET /ui/settings", func(int) int {
+		rec := httptest.NewRecorder()
+		ui.SettingsPageHandler(rec, httptest.NewRequest(http.MethodGet, "/ui/settings", nil))
+		return rec.Code
+	})
+	wg.Wait()
+
+	if settings := config.RuntimeSettings(); settings.RetentionDays != rounds-1 {
+		t.Errorf("retention_days = %d, want the last update %d", settings.RetentionDays, rounds-1)
+	}
+}
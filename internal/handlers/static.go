@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+func init() {
+	// Not all platforms register these in their mime tables
+	mime.AddExtensionType(".js", "text/javascript; charset=utf-8")
+	mime.AddExtensionType(".css", "text/css; charset=utf-8")
+	mime.AddExtensionType(".svg", "image/svg+xml")
+	mime.AddExtensionType(".zip", "application/zip")
+}
+
+// StaticHandler serves files from dir under the given URL prefix. Directory
+// listings are not served. Requests carrying a ?v= version parameter are
+// cached long-term; the extension bundle is always revalidated.
+func StaticHandler(prefix, dir string, development bool) http.Handler {
+	files := http.StripPrefix(prefix, http.FileServer(http.Dir(dir)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/") {
+			NotFound(w, r)
+			return
+		}
+
+		switch {
+		case development:
+			w.Header().Set("Cache-Control", "no-cache")
+		case strings.HasPrefix(path.Clean(r.URL.Path), path.Join(prefix, "extension")+"/"):
+			w.Header().Set("Cache-Control", "no-cache")
+		case r.URL.Query().Get("v") != "":
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		default:
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+// TicketStats represents aggregate ticket counts
+type TicketStats struct {
+	Total         int            `json:"total"`
+	ByStatus      map[string]int `json:"by_status"`
+	ByPriority    map[string]int `json:"by_priority"`
+	ByType        map[string]int `json:"by_type"`
+	ByProject     map[string]int `json:"by_project"`
+	OldestUpdated *time.Time     `json:"oldest_updated,omitempty"`
+	NewestUpdated *time.Time     `json:"newest_updated,omitempty"`
+}
+
+// CountEntry is a labelled count used for tables and charts
+type CountEntry struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// StatsBreakdown is one named breakdown rendered as a table and chart
+type StatsBreakdown struct {
+	Title     string
+	Entries   []CountEntry
+	ChartJSON string
+}
+
+// ComputeTicketStats counts tickets by status, priority, type and project and
+// finds the range of their updated timestamps. It backs the /stats endpoint,
+// the statistics dashboard and the -stats command.
+func ComputeTicketStats(tickets map[string]*models.TicketData) TicketStats {
+	stats := TicketStats{
+		Total:      len(tickets),
+		ByStatus:   make(map[string]int),
+		ByPriority: make(map[string]int),
+		ByType:     make(map[string]int),
+		ByProject:  make(map[string]int),
+	}
+
+	for _, ticket := range tickets {
+		stats.ByStatus[valueOrUnknown(ticket.Status)]++
+		stats.ByPriority[valueOrUnknown(ticket.Priority)]++
+		stats.ByType[valueOrUnknown(ticket.IssueType)]++
+
+		stats.ByProject[valueOrUnknown(ticketProject(ticket))]++
+
+		if updated, ok := parseTime(ticket.Updated); ok {
+			if stats.OldestUpdated == nil || updated.Before(*stats.OldestUpdated) {
+				stats.OldestUpdated = &updated
+			}
+			if stats.NewestUpdated == nil || updated.After(*stats.NewestUpdated) {
+				stats.NewestUpdated = &updated
+			}
+		}
+	}
+
+	return stats
+}
+
+// withoutReferences drops SourceReference skeleton tickets
+func withoutReferences(tickets map[string]*models.TicketData) map[string]*models.TicketData {
+	kept := make(map[string]*models.TicketData, len(tickets))
+	for key, ticket := range tickets {
+		if ticket.Source != models.SourceReference {
+			kept[key] = ticket
+		}
+	}
+	return kept
+}
+
+// includeReferences reports whether the include_references query parameter
+// asks for reference tickets
+func includeReferences(r *http.Request) bool {
+	include, _ := strconv.ParseBool(r.URL.Query().Get("include_references"))
+	return include
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
+
+// SortedCounts orders counts by descending count, then label
+func SortedCounts(counts map[string]int) []CountEntry {
+	entries := make([]CountEntry, 0, len(counts))
+	for label, count := range counts {
+		entries = append(entries, CountEntry{Label: label, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Label < entries[j].Label
+	})
+	return entries
+}
+
+// newBreakdown builds a breakdown with its chart data pre-encoded
+func newBreakdown(title string, counts map[string]int) StatsBreakdown {
+	entries := SortedCounts(counts)
+	chartJSON, _ := json.Marshal(entries)
+	return StatsBreakdown{Title: title, Entries: entries, ChartJSON: string(chartJSON)}
+}
+
+// StatsHandler returns ticket counts by status, priority, type and project,
+// and each project's freshness. Reference tickets are counted only with
+// include_references=true.
+func (h *APIHandlers) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	tickets, err := h.storage.LoadAllTickets()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load tickets for stats")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	if !includeReferences(r) {
+		tickets = withoutReferences(tickets)
+	}
+
+	freshness, err := h.storage.GetProjectFreshness()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load project freshness for stats")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_freshness", "Failed to load project freshness"), h.config.IsDevelopment())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"stats":     ComputeTicketStats(tickets),
+		"freshness": freshness,
+	}
+
+	if err := respondJSON(w, http.StatusOK, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode stats response")
+	}
+}
+
+// StatsHandler serves the statistics tables partial
+func (h *UIHandlers) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	h.renderPartial(w, "stats_tables", h.statsBreakdowns())
+}
+
+// DashboardHandler serves the statistics dashboard page
+func (h *UIHandlers) DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	data := struct {
+		TemplateData
+		Breakdowns []StatsBreakdown
+	}{
+		TemplateData: h.templateData("Statistics"),
+		Breakdowns:   h.statsBreakdowns(),
+	}
+
+	if err := h.executeTemplate(w, "dashboard.html", data); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to execute dashboard template")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
+	}
+}
+
+func (h *UIHandlers) statsBreakdowns() []StatsBreakdown {
+	tickets, err := h.storage.LoadAllTickets()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load tickets for stats")
+	}
+
+	stats := ComputeTicketStats(withoutReferences(tickets))
+	return []StatsBreakdown{
+		newBreakdown("By Status", stats.ByStatus),
+		newBreakdown("By Priority", stats.ByPriority),
+		newBreakdown("By Type", stats.ByType),
+		newBreakdown("By Project", stats.ByProject),
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// templateFuncs are available to every page and partial template
+var templateFuncs = template.FuncMap{
+	"formatTime":       formatTime,
+	"timeAgo":          timeAgo,
+	"truncate":         truncate,
+	"statusBadgeClass": statusBadgeClass,
+}
+
+// timeLayouts are the timestamp formats found in stored tickets and metadata
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700", // Jira REST API
+	"2006-01-02T15:04:05-0700",     // Jira page <time datetime>
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04", // storage last update
+	"2006-01-02",
+}
+
+// parseTemplates loads the page templates and HTMX partials from pagesDir
+func parseTemplates(pagesDir string) (*template.Template, error) {
+	templates, err := template.New("").Funcs(templateFuncs).ParseGlob(filepath.Join(pagesDir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Load partials (HTMX fragments) if present
+	partialsPath := filepath.Join(pagesDir, "partials", "*.html")
+	if matches, _ := filepath.Glob(partialsPath); len(matches) > 0 {
+		if templates, err = templates.ParseGlob(partialsPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
+
+// executeTemplate renders a named template. In development the templates are
+// re-parsed on every request so page edits show up without a restart.
+func (h *UIHandlers) executeTemplate(w io.Writer, name string, data interface{}) error {
+	templates := h.templates
+	if h.reloadTemplates {
+		reloaded, err := parseTemplates(h.pagesDir)
+		if err != nil {
+			return fmt.Errorf("failed to reload templates: %w", err)
+		}
+		templates = reloaded
+	}
+	return templates.ExecuteTemplate(w, name, data)
+}
+
+// parseTime accepts a time.Time or a timestamp string in one of timeLayouts
+func parseTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, !v.IsZero()
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatTime renders a timestamp as local date and time. Unrecognised strings
+// are returned unchanged.
+func formatTime(value interface{}) string {
+	t, ok := parseTime(value)
+	if !ok {
+		if s, isString := value.(string); isString {
+			return s
+		}
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// timeAgo renders a timestamp relative to now, e.g. "5m ago"
+func timeAgo(value interface{}) string {
+	t, ok := parseTime(value)
+	if !ok {
+		return formatTime(value)
+	}
+
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	default:
+		return t.Local().Format("2006-01-02")
+	}
+}
+
+// truncate shortens s to at most length characters, adding an ellipsis.
+// The length comes first so it can be used in pipelines: {{.Summary | truncate 80}}
+func truncate(length int, s string) string {
+	runes := []rune(s)
+	if length <= 0 || len(runes) <= length {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
+
+// statusBadgeClass maps a Jira status to a badge colour class
+func statusBadgeClass(status string) string {
+	s := strings.ToLower(status)
+	switch {
+	case s == "":
+		return "status-badge"
+	case strings.Contains(s, "done"), strings.Contains(s, "closed"),
+		strings.Contains(s, "resolved"), strings.Contains(s, "complete"):
+		return "status-badge status-done"
+	case strings.Contains(s, "block"):
+		return "status-badge status-blocked"
+	case strings.Contains(s, "progress"), strings.Contains(s, "review"),
+		strings.Contains(s, "test"), strings.Contains(s, "doing"):
+		return "status-badge status-progress"
+	default:
+		return "status-badge status-todo"
+	}
+}
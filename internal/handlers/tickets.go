@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+)
+
+const (
+	// defaultTicketLimit is the number of tickets GET /tickets returns without
+	// a limit parameter
+	defaultTicketLimit = 100
+	// maxTicketLimit caps the limit parameter
+	maxTicketLimit = 1000
+)
+
+// ticketFilter holds the GET /tickets filters. Empty fields match every
+// ticket.
+type ticketFilter struct {
+	status       string
+	issueType    string
+	assignee     string
+	updatedSince time.Time
+	storedSince  time.Time
+
+	includeReferences bool
+}
+
+// matches reports whether a ticket passes the filter. Text filters ignore
+// case. updated_since compares Jira's updated time, or the local write time
+// for tickets stored without one; stored_since compares the local write time,
+// which every re-post, merge or reprocess moves forward.
+func (f ticketFilter) matches(ticket *models.TicketData) bool {
+	if ticket.Source == models.SourceReference && !f.includeReferences {
+		return false
+	}
+	if f.status != "" && !strings.EqualFold(ticket.Status, f.status) {
+		return false
+	}
+	if f.issueType != "" && !strings.EqualFold(ticket.IssueType, f.issueType) {
+		return false
+	}
+	if f.assignee != "" && !strings.EqualFold(ticket.Assignee, f.assignee) {
+		return false
+	}
+	if !f.updatedSince.IsZero() {
+		updated := ticket.JiraUpdated
+		if updated == "" {
+			updated = ticket.Updated
+		}
+		if !timeAtOrAfter(updated, f.updatedSince) {
+			return false
+		}
+	}
+	if !f.storedSince.IsZero() && !timeAtOrAfter(ticket.Updated, f.storedSince) {
+		return false
+	}
+	return true
+}
+
+// timeAtOrAfter reports whether the RFC 3339 timestamp value is not before
+// since. Missing or malformed timestamps never match.
+func timeAtOrAfter(value string, since time.Time) bool {
+	t, err := time.Parse(time.RFC3339, value)
+	return err == nil && !t.Before(since)
+}
+
+// parseUpdatedSince accepts an RFC 3339 timestamp or a plain date
+func parseUpdatedSince(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+// TicketsHandler returns stored tickets as JSON, sorted by project and issue
+// number. The project, status, issue_type, assignee, updated_since and
+// stored_since query parameters filter them, and limit and offset select a page.
+// The total is the number of matching tickets before paging. raw_html is left
+// out unless include_raw=true.
+func (h *APIHandlers) TicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	params := r.URL.Query()
+	project := strings.ToUpper(params.Get("project"))
+	filter := ticketFilter{
+		status:    params.Get("status"),
+		issueType: params.Get("issue_type"),
+		assignee:  params.Get("assignee"),
+
+		includeReferences: includeReferences(r),
+	}
+
+	limit := defaultTicketLimit
+	if raw := params.Get("limit"); raw != "" {
+		l, err := strconv.Atoi(raw)
+		if err != nil || l <= 0 {
+			respondError(w, r, http.StatusBadRequest, "Invalid limit: must be a positive integer")
+			return
+		}
+		limit = min(l, maxTicketLimit)
+	}
+	offset := 0
+	if raw := params.Get("offset"); raw != "" {
+		o, err := strconv.Atoi(raw)
+		if err != nil || o < 0 {
+			respondError(w, r, http.StatusBadRequest, "Invalid offset: must be a non-negative integer")
+			return
+		}
+		offset = o
+	}
+	timeFilters := []struct {
+		name  string
+		since *time.Time
+	}{
+		{"updated_since", &filter.updatedSince},
+		{"stored_since", &filter.storedSince},
+	}
+	for _, timeFilter := range timeFilters {
+		raw := params.Get(timeFilter.name)
+		if raw == "" {
+			continue
+		}
+		since, err := parseUpdatedSince(raw)
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, "Invalid "+timeFilter.name+": use an RFC 3339 timestamp or YYYY-MM-DD date")
+			return
+		}
+		*timeFilter.since = since
+	}
+
+	var stored map[string]*models.TicketData
+	var err error
+	if project != "" {
+		stored, err = h.storage.LoadTickets(project)
+	} else {
+		stored, err = h.storage.LoadAllTickets()
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("project", project).Msg("Failed to load tickets")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_tickets", "Failed to load tickets"), h.config.IsDevelopment())
+		return
+	}
+
+	tickets := make([]*models.TicketData, 0, len(stored))
+	for _, ticket := range stored {
+		if filter.matches(ticket) {
+			tickets = append(tickets, ticket)
+		}
+	}
+	slices.SortFunc(tickets, func(a, b *models.TicketData) int {
+		return common.CompareIssueKeys(a.Key, b.Key)
+	})
+
+	total := len(tickets)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	// The stored page HTML can be megabytes per ticket, so the page's tickets
+	// are copied without it unless include_raw=true
+	page := tickets[start:end]
+	if includeRaw, _ := strconv.ParseBool(params.Get("include_raw")); !includeRaw {
+		for i, ticket := range page {
+			if ticket.RawHTML != "" {
+				stripped := *ticket
+				stripped.RawHTML = ""
+				page[i] = &stripped
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"tickets": page,
+		"count":   end - start,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// loadTicket looks a stored ticket up by issue key in the project the key
+// names. It returns nil when the key is not a valid issue key or the ticket
+// is not stored.
+func loadTicket(storage interfaces.Storage, key string) (*models.TicketData, error) {
+	projectKey, ok := common.IssueKeyProject(key)
+	if !ok {
+		return nil, nil
+	}
+	return storage.GetTicket(projectKey, key)
+}
+
+// TicketHandler returns one stored ticket by key as JSON, with its comments,
+// subtasks, attachments and links. The stored page HTML can be megabytes, so
+// raw_html is left out unless include_raw=true.
+func (h *APIHandlers) TicketHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	key := strings.ToUpper(r.PathValue("key"))
+	projectKey, ok := common.IssueKeyProject(key)
+	if !ok {
+		respondError(w, r, http.StatusBadRequest, "Invalid issue key: "+key)
+		return
+	}
+
+	ticket, err := h.storage.GetTicket(projectKey, key)
+	if err != nil {
+		h.logger.Error().Err(err).Str("key", key).Msg("Failed to load ticket")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_ticket", "Failed to load ticket"), h.config.IsDevelopment())
+		return
+	}
+	if ticket == nil {
+		respondFailure(w, r, common.NewNotFoundError("ticket_not_found", "Ticket not found: "+key), h.config.IsDevelopment())
+		return
+	}
+
+	if includeRaw, _ := strconv.ParseBool(r.URL.Query().Get("include_raw")); !includeRaw {
+		ticket.RawHTML = ""
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"ticket":  ticket,
+	})
+}
@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"aktis-collector-jira/internal/models"
+)
+
+func TestTicketsUpdatedSinceUsesJiraTime(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	// Stored now, but last changed in Jira long before or after the cutoff
+	tickets := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Summary: "Old in Jira", JiraUpdated: "2020-03-01T10:00:00Z"},
+		"ABC-2": {Key: "ABC-2", ProjectID: "ABC", Summary: "Recent in Jira", JiraUpdated: "2024-06-01T10:00:00Z"},
+		"ABC-3": {Key: "ABC-3", ProjectID: "ABC", Summary: "No Jira time"},
+	}
+	if _, err := c.storage.SaveTickets("ABC", tickets); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+
+	listKeys := func(query string) []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c.api.TicketsHandler(rec, httptest.NewRequest(http.MethodGet, "/tickets?"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /tickets?%s status = %d: %s", query, rec.Code, rec.Body)
+		}
+		var response struct {
+			Tickets []*models.TicketData `json:"tickets"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		var keys []string
+		for _, ticket := range response.Tickets {
+			keys = append(keys, ticket.Key)
+		}
+		return keys
+	}
+
+	if keys := listKeys("updated_since=2024-01-01"); !slices.Equal(keys, []string{"ABC-2", "ABC-3"}) {
+		t.Errorf("updated_since keys = %v, want ABC-2 and the ticket without a Jira time", keys)
+	}
+	if keys := listKeys("stored_since=2024-01-01"); !slices.Equal(keys, []string{"ABC-1", "ABC-2", "ABC-3"}) {
+		t.Errorf("stored_since keys = %v, want every ticket", keys)
+	}
+
+	rec := httptest.NewRecorder()
+	c.api.TicketsHandler(rec, httptest.NewRequest(http.MethodGet, "/tickets?stored_since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed stored_since status = %d, want 400", rec.Code)
+	}
+}
+
+func TestTicketsLeaveOutRawHTML(t *testing.T) {
+	c := newTestCollector(t, nil)
+
+	tickets := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Summary: "With page", RawHTML: "<html>page</html>"},
+	}
+	if _, err := c.storage.SaveTickets("ABC", tickets); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+
+	rawHTML := func(query string) string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c.api.TicketsHandler(rec, httptest.NewRequest(http.MethodGet, "/tickets?"+query, nil))
+		var response struct {
+			Tickets []*models.TicketData `json:"tickets"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || len(response.Tickets) != 1 {
+			t.Fatalf("GET /tickets?%s: %d tickets, %v", query, len(response.Tickets), err)
+		}
+		return response.Tickets[0].RawHTML
+	}
+
+	if html := rawHTML("project=ABC"); html != "" {
+		t.Errorf("raw_html without include_raw = %q, want it left out", html)
+	}
+	if html := rawHTML("project=ABC&include_raw=true"); html != "<html>page</html>" {
+		t.Errorf("raw_html with include_raw = %q", html)
+	}
+
+	// The stored ticket keeps its page
+	if ticket, err := c.storage.GetTicket("ABC", "ABC-1"); err != nil || ticket.RawHTML == "" {
+		t.Errorf("stored ticket = %+v, %v", ticket, err)
+	}
+}
@@ -2,22 +2,29 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"net/http"
-	"path/filepath"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
 
 	"github.com/ternarybob/arbor"
 )
 
 // UIHandlers contains all UI endpoint handlers
 type UIHandlers struct {
-	config    *common.Config
-	storage   interfaces.Storage
-	logger    arbor.ILogger
-	templates *template.Template
+	config          *common.Config
+	storage         interfaces.Storage
+	logger          arbor.ILogger
+	templates       *template.Template
+	pagesDir        string
+	reloadTemplates bool
 }
 
 // TemplateData represents data passed to templates
@@ -31,34 +38,39 @@ type TemplateData struct {
 
 // NewUIHandlers creates a new UI handlers instance
 func NewUIHandlers(config *common.Config, storage interfaces.Storage, logger arbor.ILogger, pagesDir string) (*UIHandlers, error) {
-	// Load templates
-	templatesPath := filepath.Join(pagesDir, "*.html")
-	templates, err := template.ParseGlob(templatesPath)
+	// Parse once up front so template errors fail startup in every mode
+	templates, err := parseTemplates(pagesDir)
 	if err != nil {
 		return nil, err
 	}
 
 	return &UIHandlers{
-		config:    config,
-		storage:   storage,
-		logger:    logger,
-		templates: templates,
+		config:          config,
+		storage:         storage,
+		logger:          common.WithComponent(logger, "ui"),
+		templates:       templates,
+		pagesDir:        pagesDir,
+		reloadTemplates: config.IsDevelopment(),
 	}, nil
 }
 
 // IndexHandler serves the main web interface
 func (h *UIHandlers) IndexHandler(w http.ResponseWriter, r *http.Request) {
-	data := TemplateData{
-		Title:       "Jira Collector",
-		ServiceName: h.config.Collector.Name,
-		Version:     common.GetVersion(),
-		Build:       common.GetBuild(),
-		Environment: h.config.Collector.Environment,
+	// "/" matches every path not registered elsewhere
+	if r.URL.Path != "/" {
+		NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
 	}
 
-	if err := h.templates.ExecuteTemplate(w, "index.html", data); err != nil {
+	data := h.templateData("Jira Collector")
+
+	if err := h.executeTemplate(w, "index.html", data); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to execute template")
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
 		return
 	}
 }
@@ -71,43 +83,67 @@ func (h *UIHandlers) BufferDataHandler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodDelete:
 		h.handleClearBufferData(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet, http.MethodDelete)
 	}
 }
 
+// bufferPageSize is the number of tickets rendered per buffer view page
+const bufferPageSize = 50
+
+// BufferPageData represents one page of the buffer data view
+type BufferPageData struct {
+	Entries  []BufferEntry
+	Page     *models.TicketPage
+	PrevPage int
+	NextPage int
+	Error    string
+}
+
+// BufferEntry is a stored ticket with its compact JSON form
+type BufferEntry struct {
+	Key     string
+	Summary string
+	Updated string
+	JSON    string
+}
+
 func (h *UIHandlers) handleGetBufferData(w http.ResponseWriter, r *http.Request) {
-	// Get all stored tickets from extension-collected data
-	allStoredTickets, err := h.storage.LoadAllTickets()
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	// Storage order paging only decodes the tickets on this page
+	result, err := h.storage.QueryTickets(models.TicketQuery{Page: page, PageSize: bufferPageSize, IncludeReferences: true})
 	if err != nil {
-		h.logger.Warn().Err(err).Msg("Failed to load all tickets")
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="metric">
-			<div class="metric-header">Error loading data</div>
-			<p>Failed to load tickets from database</p>
-		</div>`))
+		h.logger.Warn().Err(err).Msg("Failed to load tickets")
+		h.renderPartial(w, "buffer_page", BufferPageData{Error: "Failed to load tickets from database"})
 		return
 	}
 
-	if len(allStoredTickets) == 0 {
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="metric">
-			<div class="metric-header">No data available</div>
-			<p>No Jira tickets have been collected yet. Use the Chrome extension to collect ticket data.</p>
-		</div>`))
-		return
+	data := BufferPageData{
+		Entries: make([]BufferEntry, 0, len(result.Tickets)),
+		Page:    result,
+	}
+	if result.Page > 1 {
+		data.PrevPage = result.Page - 1
+	}
+	if result.Page < result.TotalPages {
+		data.NextPage = result.Page + 1
 	}
 
-	// Return flat JSON array for display
-	w.Header().Set("Content-Type", "application/json")
-	jsonOutput, err := json.MarshalIndent(allStoredTickets, "", "  ")
-	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to marshal tickets data")
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="error">Error formatting database data: ` + err.Error() + `</div>`))
-		return
+	for _, ticket := range result.Tickets {
+		raw, err := json.Marshal(ticket)
+		if err != nil {
+			h.logger.Warn().Err(err).Str("key", ticket.Key).Msg("Failed to marshal ticket")
+			continue
+		}
+		data.Entries = append(data.Entries, BufferEntry{
+			Key:     ticket.Key,
+			Summary: ticket.Summary,
+			Updated: ticket.Updated,
+			JSON:    string(raw),
+		})
 	}
 
-	w.Write(jsonOutput)
+	h.renderPartial(w, "buffer_page", data)
 }
 
 func (h *UIHandlers) handleClearBufferData(w http.ResponseWriter, r *http.Request) {
@@ -130,3 +166,470 @@ func (h *UIHandlers) handleClearBufferData(w http.ResponseWriter, r *http.Reques
 		<p>All stored Jira tickets have been successfully removed from the database.</p>
 	</div>`))
 }
+
+const (
+	defaultTicketPageSize = 25
+	maxTicketPageSize     = 200
+)
+
+// ticketSortColumns are the columns the tickets table can be sorted by
+var ticketSortColumns = map[string]bool{
+	"key": true, "summary": true, "status": true, "priority": true, "assignee": true, "updated": true,
+}
+
+// TicketTableData represents data passed to the tickets table partial
+type TicketTableData struct {
+	Endpoint     string // URL the table reloads itself from
+	Target       string // element ID the table is swapped into
+	FixedProject bool   // hide the project filter (project pages)
+	Query        models.TicketQuery
+	Result       *models.TicketPage
+	Projects     []*models.ProjectData
+	Columns      []string
+	Error        string
+}
+
+// ColumnLabel returns the display heading for a column
+func (d TicketTableData) ColumnLabel(column string) string {
+	if column == "" {
+		return ""
+	}
+	return strings.ToUpper(column[:1]) + column[1:]
+}
+
+// SortURL returns the table URL sorted by column, toggling direction when
+// the column is already sorted
+func (d TicketTableData) SortURL(column string) string {
+	q := d.Query
+	q.Descending = q.SortBy == column && !q.Descending
+	q.SortBy = column
+	q.Page = 1
+	return d.url(q)
+}
+
+// SortIndicator returns an arrow for the currently sorted column
+func (d TicketTableData) SortIndicator(column string) string {
+	if d.Query.SortBy != column {
+		return ""
+	}
+	if d.Query.Descending {
+		return "▼"
+	}
+	return "▲"
+}
+
+// PrevURL returns the table URL for the previous page
+func (d TicketTableData) PrevURL() string {
+	return d.pageURL(d.Result.Page - 1)
+}
+
+// NextURL returns the table URL for the next page
+func (d TicketTableData) NextURL() string {
+	return d.pageURL(d.Result.Page + 1)
+}
+
+func (d TicketTableData) pageURL(page int) string {
+	q := d.Query
+	q.Page = page
+	return d.url(q)
+}
+
+// HasPrev reports whether there is a previous page
+func (d TicketTableData) HasPrev() bool {
+	return d.Result != nil && d.Result.Page > 1
+}
+
+// HasNext reports whether there is a next page
+func (d TicketTableData) HasNext() bool {
+	return d.Result != nil && d.Result.Page < d.Result.TotalPages
+}
+
+func (d TicketTableData) url(q models.TicketQuery) string {
+	values := url.Values{}
+	if q.Project != "" {
+		values.Set("project", q.Project)
+	}
+	if q.Status != "" {
+		values.Set("status", q.Status)
+	}
+	if q.SortBy != "" {
+		values.Set("sort", q.SortBy)
+	}
+	if q.Descending {
+		values.Set("order", "desc")
+	}
+	if q.Page > 1 {
+		values.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.PageSize != defaultTicketPageSize {
+		values.Set("page_size", strconv.Itoa(q.PageSize))
+	}
+	if q.IncludeReferences {
+		values.Set("include_references", "true")
+	}
+
+	if len(values) == 0 {
+		return d.Endpoint
+	}
+	return d.Endpoint + "?" + values.Encode()
+}
+
+// parseTicketQuery reads table filters, sorting and paging from query parameters
+func parseTicketQuery(r *http.Request) models.TicketQuery {
+	params := r.URL.Query()
+
+	query := models.TicketQuery{
+		Project:    strings.ToUpper(strings.TrimSpace(params.Get("project"))),
+		Status:     strings.TrimSpace(params.Get("status")),
+		SortBy:     params.Get("sort"),
+		Descending: params.Get("order") == "desc",
+		Page:       1,
+		PageSize:   defaultTicketPageSize,
+
+		IncludeReferences: includeReferences(r),
+	}
+
+	if !ticketSortColumns[query.SortBy] {
+		query.SortBy = "key"
+	}
+	if page, err := strconv.Atoi(params.Get("page")); err == nil && page > 0 {
+		query.Page = page
+	}
+	if size, err := strconv.Atoi(params.Get("page_size")); err == nil && size > 0 {
+		query.PageSize = min(size, maxTicketPageSize)
+	}
+
+	return query
+}
+
+// TicketsHandler serves the paginated, sortable tickets table partial
+func (h *UIHandlers) TicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	data := h.ticketTable("/ui/tickets", "tickets-content", parseTicketQuery(r))
+	h.renderPartial(w, "tickets_table", data)
+}
+
+// ticketTable queries storage and builds the tickets table partial data
+func (h *UIHandlers) ticketTable(endpoint, target string, query models.TicketQuery) TicketTableData {
+	data := TicketTableData{
+		Endpoint: endpoint,
+		Target:   target,
+		Query:    query,
+		Columns:  []string{"key", "summary", "status", "priority", "assignee", "updated"},
+	}
+
+	result, err := h.storage.QueryTickets(query)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to query tickets")
+		data.Error = "Failed to load tickets from database"
+	}
+	data.Result = result
+
+	if projects, err := h.storage.LoadProjects(); err == nil {
+		data.Projects = projects
+	} else {
+		h.logger.Warn().Err(err).Msg("Failed to load projects for ticket filters")
+	}
+
+	return data
+}
+
+// renderPartial renders an HTMX fragment template
+func (h *UIHandlers) renderPartial(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := h.executeTemplate(w, name, data); err != nil {
+		h.logger.Error().Err(err).Str("template", name).Msg("Failed to execute template")
+	}
+}
+
+// TicketRawHandler returns a single stored ticket as indented JSON for debugging
+func (h *UIHandlers) TicketRawHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	key := strings.ToUpper(r.PathValue("key"))
+	ticket, err := loadTicket(h.storage, key)
+	if err != nil {
+		h.logger.Error().Err(err).Str("key", key).Msg("Failed to load ticket")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_ticket", "Failed to load ticket"), h.config.IsDevelopment())
+		return
+	}
+	if ticket == nil {
+		respondFailure(w, r, common.NewNotFoundError("ticket_not_found", "Ticket not found: "+key), h.config.IsDevelopment())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(ticket); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode ticket")
+	}
+}
+
+// ProjectSummary represents a project row in the projects list partial
+type ProjectSummary struct {
+	Project     *models.ProjectData
+	TicketCount int
+	LastUpdate  string
+	Freshness   string // fresh, stale or never
+}
+
+// ProjectPageData represents data passed to the project drill-down page
+type ProjectPageData struct {
+	TemplateData
+	Project      *models.ProjectData
+	TicketCount  int
+	StatusCounts []StatusCount
+	LastUpdate   string
+	Tickets      TicketTableData
+}
+
+// StatusCount is the number of tickets in a status
+type StatusCount struct {
+	Status string
+	Count  int
+}
+
+// ProjectsHandler serves the projects list partial with links to each project page
+func (h *UIHandlers) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	projects, err := h.storage.LoadProjects()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load projects")
+	}
+
+	freshness := make(map[string]string)
+	if projectFreshness, err := h.storage.GetProjectFreshness(); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to load project freshness")
+	} else {
+		for _, f := range projectFreshness {
+			freshness[f.Key] = f.Status
+		}
+	}
+
+	summaries := make([]ProjectSummary, 0, len(projects))
+	for _, project := range projects {
+		summary := ProjectSummary{Project: project, Freshness: freshness[project.Key]}
+		if tickets, err := h.storage.LoadTickets(project.Key); err == nil {
+			summary.TicketCount = len(tickets)
+		}
+		summary.LastUpdate, _ = h.storage.GetLastUpdate(project.Key)
+		summaries = append(summaries, summary)
+	}
+
+	h.renderPartial(w, "projects_list", summaries)
+}
+
+// ProjectPageHandler serves the drill-down page for a single project
+func (h *UIHandlers) ProjectPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	key := strings.ToUpper(r.PathValue("key"))
+
+	tickets, err := h.storage.LoadTickets(key)
+	if err != nil {
+		h.logger.Error().Err(err).Str("project", key).Msg("Failed to load project tickets")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_project", "Failed to load project"), h.config.IsDevelopment())
+		return
+	}
+
+	project := h.findProject(key)
+	if project == nil {
+		if len(tickets) == 0 {
+			NotFound(w, r)
+			return
+		}
+		// Tickets collected before the project list was visited
+		project = &models.ProjectData{Key: key, Name: key}
+	}
+
+	counts := make(map[string]int)
+	for _, ticket := range tickets {
+		status := ticket.Status
+		if status == "" {
+			status = "Unknown"
+		}
+		counts[status]++
+	}
+	statusCounts := make([]StatusCount, 0, len(counts))
+	for status, count := range counts {
+		statusCounts = append(statusCounts, StatusCount{Status: status, Count: count})
+	}
+	sort.Slice(statusCounts, func(i, j int) bool {
+		if statusCounts[i].Count != statusCounts[j].Count {
+			return statusCounts[i].Count > statusCounts[j].Count
+		}
+		return statusCounts[i].Status < statusCounts[j].Status
+	})
+
+	lastUpdate, _ := h.storage.GetLastUpdate(key)
+	if lastUpdate == "" {
+		lastUpdate = "Never"
+	}
+
+	query := parseTicketQuery(r)
+	query.Project = key
+	table := h.ticketTable("/ui/projects/"+url.PathEscape(key)+"/tickets", "project-tickets", query)
+	table.FixedProject = true
+
+	data := ProjectPageData{
+		TemplateData: h.templateData(project.Name),
+		Project:      project,
+		TicketCount:  len(tickets),
+		StatusCounts: statusCounts,
+		LastUpdate:   lastUpdate,
+		Tickets:      table,
+	}
+
+	if err := h.executeTemplate(w, "project.html", data); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to execute project template")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
+	}
+}
+
+// ProjectTicketsHandler serves the tickets table partial scoped to one project
+func (h *UIHandlers) ProjectTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	key := strings.ToUpper(r.PathValue("key"))
+	query := parseTicketQuery(r)
+	query.Project = key
+
+	data := h.ticketTable("/ui/projects/"+url.PathEscape(key)+"/tickets", "project-tickets", query)
+	data.FixedProject = true
+	h.renderPartial(w, "tickets_table", data)
+}
+
+// findProject returns the stored project with the given key, or nil
+func (h *UIHandlers) findProject(key string) *models.ProjectData {
+	projects, err := h.storage.LoadProjects()
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Failed to load projects")
+		return nil
+	}
+
+	for _, project := range projects {
+		if strings.EqualFold(project.Key, key) {
+			return project
+		}
+	}
+	return nil
+}
+
+// templateData builds the common page template fields
+func (h *UIHandlers) templateData(title string) TemplateData {
+	return TemplateData{
+		Title:       title,
+		ServiceName: h.config.Collector.Name,
+		Version:     common.GetVersion(),
+		Build:       common.GetBuild(),
+		Environment: h.config.Collector.Environment,
+	}
+}
+
+// TicketPageData represents data passed to the ticket detail page
+type TicketPageData struct {
+	TemplateData
+	Ticket     *models.TicketData
+	Comments   []models.Comment
+	LinkGroups []LinkGroup
+}
+
+// LinkGroup is a set of issue links sharing a link type
+type LinkGroup struct {
+	LinkType string
+	Links    []models.IssueLink
+}
+
+// ProjectKey returns the ticket's stored project key
+func (d TicketPageData) ProjectKey() string {
+	return ticketProject(d.Ticket)
+}
+
+// FormatSize returns a human readable attachment size
+func (d TicketPageData) FormatSize(size int64) string {
+	switch {
+	case size <= 0:
+		return ""
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+// TicketPageHandler serves the detail page for a single ticket
+func (h *UIHandlers) TicketPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	key := strings.ToUpper(r.PathValue("key"))
+	ticket, err := loadTicket(h.storage, key)
+	if err != nil {
+		h.logger.Error().Err(err).Str("key", key).Msg("Failed to load ticket")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeStorage, "load_ticket", "Failed to load ticket"), h.config.IsDevelopment())
+		return
+	}
+	if ticket == nil {
+		NotFound(w, r)
+		return
+	}
+
+	// Comments are shown oldest first
+	comments := append([]models.Comment(nil), ticket.Comments...)
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Created < comments[j].Created
+	})
+
+	groups := make(map[string]*LinkGroup)
+	var linkGroups []LinkGroup
+	for _, link := range ticket.Links {
+		linkType := link.LinkType
+		if linkType == "" {
+			linkType = "related"
+		}
+		if groups[linkType] == nil {
+			groups[linkType] = &LinkGroup{LinkType: linkType}
+		}
+		groups[linkType].Links = append(groups[linkType].Links, link)
+	}
+	for _, group := range groups {
+		linkGroups = append(linkGroups, *group)
+	}
+	sort.Slice(linkGroups, func(i, j int) bool {
+		return linkGroups[i].LinkType < linkGroups[j].LinkType
+	})
+
+	data := TicketPageData{
+		TemplateData: h.templateData(ticket.Key),
+		Ticket:       ticket,
+		Comments:     comments,
+		LinkGroups:   linkGroups,
+	}
+
+	if err := h.executeTemplate(w, "ticket.html", data); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to execute ticket template")
+		respondFailure(w, r, common.WrapError(err, common.ErrorTypeInternal, "render_template", "Internal server error"), h.config.IsDevelopment())
+	}
+}
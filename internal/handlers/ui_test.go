@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+	"aktis-collector-jira/internal/models"
+	"aktis-collector-jira/internal/services"
+
+	"github.com/ternarybob/arbor"
+)
+
+// seedBufferStore opens a store in a temporary directory holding n tickets
+// with page-sized descriptions, so decoding a ticket costs what it would in
+// a real database
+func seedBufferStore(b *testing.B, n int) *handlers.UIHandlers {
+	b.Helper()
+	config := common.DefaultConfig()
+	config.Storage.DatabasePath = filepath.Join(b.TempDir(), "test.db")
+	storage, err := services.NewStorage(&config.Storage)
+	if err != nil {
+		b.Fatalf("NewStorage: %v", err)
+	}
+	b.Cleanup(func() { storage.Close() })
+
+	description := strings.Repeat("Steps to reproduce and expected behaviour. ", 50)
+	tickets := make(map[string]*models.TicketData, n)
+	for i := 1; i <= n; i++ {
+		key := fmt.Sprintf("ABC-%d", i)
+		tickets[key] = &models.TicketData{
+			Key:         key,
+			ProjectID:   "ABC",
+			Summary:     fmt.Sprintf("Ticket %d", i),
+			Description: description,
+			Status:      "Open",
+			Updated:     "2026-01-02T03:04:05Z",
+		}
+	}
+	if _, err := storage.SaveTickets("ABC", tickets); err != nil {
+		b.Fatalf("SaveTickets: %v", err)
+	}
+
+	ui, err := handlers.NewUIHandlers(config, storage, arbor.NewLogger(), filepath.Join("..", "..", "pages"))
+	if err != nil {
+		b.Fatalf("NewUIHandlers: %v", err)
+	}
+	return ui
+}
+
+// BenchmarkBufferDataPage renders one page of /database/data from a small and
+// a large store. Only the page's tickets are decoded and rendered, so the two
+// should differ by the key walk alone, not by the number of tickets stored.
+func BenchmarkBufferDataPage(b *testing.B) {
+	for _, size := range []struct {
+		name    string
+		tickets int
+	}{
+		{"small", 500},
+		{"large", 20000},
+	} {
+		b.Run(size.name, func(b *testing.B) {
+			ui := seedBufferStore(b, size.tickets)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				recorder := httptest.NewRecorder()
+				ui.BufferDataHandler(recorder, httptest.NewRequest(http.MethodGet, "/database/data?page=2", nil))
+				if recorder.Code != http.StatusOK {
+					b.Fatalf("status = %d, want 200", recorder.Code)
+				}
+			}
+		})
+	}
+}
@@ -1,35 +1,89 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/middleware"
+
 	"github.com/gorilla/websocket"
 	"github.com/ternarybob/arbor"
 )
 
+const (
+	// clientSendBuffer is the number of messages queued per client before
+	// the oldest queued message is dropped
+	clientSendBuffer = 64
+	// writeWait is the time allowed to write a single message to a client
+	writeWait = 10 * time.Second
+	// logLookback re-reads recent log entries each poll because the log
+	// store is written asynchronously; entries are de-duplicated by index
+	logLookback = 5 * time.Second
+)
+
+// wsClient is a single WebSocket connection with its own outbound queue
+type wsClient struct {
+	conn     *websocket.Conn
+	send     chan []byte
+	logLevel string // minimum level of streamed log events; empty disables log streaming
+}
+
 // WebSocketHub manages active WebSocket connections and log streaming
 type WebSocketHub struct {
-	clients     map[*websocket.Conn]bool
-	broadcast   chan []byte
-	register    chan *websocket.Conn
-	unregister  chan *websocket.Conn
-	mutex       sync.RWMutex
-	logger      arbor.ILogger
-	lastLogTime time.Time
+	clients           map[*wsClient]bool
+	broadcast         chan []byte
+	register          chan *wsClient
+	unregister        chan *wsClient
+	mutex             sync.RWMutex
+	config            *common.Config
+	logger            arbor.ILogger
+	upgrader          websocket.Upgrader
+	lastLogTime       time.Time
+	lastLogIndex      uint64
+	messagesBroadcast atomic.Uint64
+	droppedMessages   atomic.Uint64
+	eventCounts       map[string]uint64
+	eventMutex        sync.Mutex
+	done              chan struct{}
+	closed            bool
+	closeMu           sync.Mutex // orders writer registration against Shutdown
+	writers           sync.WaitGroup
+}
+
+// HubStats represents WebSocket hub activity counters
+type HubStats struct {
+	ConnectedClients  int               `json:"connected_clients"`
+	MessagesBroadcast uint64            `json:"messages_broadcast"`
+	MessagesDropped   uint64            `json:"messages_dropped"`
+	EventCounts       map[string]uint64 `json:"event_counts"`
 }
 
 // NewWebSocketHub creates a new WebSocket hub
-func NewWebSocketHub(logger arbor.ILogger) *WebSocketHub {
+func NewWebSocketHub(config *common.Config, logger arbor.ILogger) *WebSocketHub {
 	hub := &WebSocketHub{
-		clients:     make(map[*websocket.Conn]bool),
+		clients:     make(map[*wsClient]bool),
 		broadcast:   make(chan []byte, 256),
-		register:    make(chan *websocket.Conn),
-		unregister:  make(chan *websocket.Conn),
+		register:    make(chan *wsClient),
+		unregister:  make(chan *wsClient),
+		config:      config,
 		logger:      logger,
 		lastLogTime: time.Now(),
+		eventCounts: make(map[string]uint64),
+		done:        make(chan struct{}),
+	}
+	hub.upgrader = websocket.Upgrader{
+		CheckOrigin: hub.checkOrigin,
+		// Failed handshakes get the same JSON errors as the rest of the API
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			respondError(w, r, status, reason.Error())
+		},
 	}
 	go hub.run()
 	return hub
@@ -39,6 +93,8 @@ func NewWebSocketHub(logger arbor.ILogger) *WebSocketHub {
 func (h *WebSocketHub) run() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
+	logTicker := time.NewTicker(time.Second)
+	defer logTicker.Stop()
 
 	for {
 		select {
@@ -52,85 +108,374 @@ func (h *WebSocketHub) run() {
 			h.mutex.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				client.Close()
+				// Closing the queue stops the client's writer, which closes the connection
+				close(client.send)
 			}
 			h.mutex.Unlock()
 			h.logger.Debug().Msg("WebSocket client disconnected")
 
 		case message := <-h.broadcast:
-			h.mutex.RLock()
+			h.broadcastMessage(message)
+
+		case <-h.done:
+			// Closing every queue makes each writer send a close frame
+			h.mutex.Lock()
 			for client := range h.clients {
-				err := client.WriteMessage(websocket.TextMessage, message)
-				if err != nil {
-					h.logger.Warn().Err(err).Msg("Failed to send WebSocket message")
-					client.Close()
-					delete(h.clients, client)
-				}
+				delete(h.clients, client)
+				close(client.send)
 			}
-			h.mutex.RUnlock()
+			h.mutex.Unlock()
+			return
 
 		case <-ticker.C:
-			// Send heartbeat to all clients
-			h.SendStatus("online")
+			// Send heartbeat to all clients directly; queueing onto h.broadcast
+			// from the run goroutine could block if the channel is full
+			h.recordEvent("status")
+			h.broadcastMessage(h.statusMessage("online"))
 
-			// Stream new logs to clients
+		case <-logTicker.C:
+			// Stream new logs to subscribed clients
 			h.streamLogs()
 		}
 	}
 }
 
+// broadcastMessage queues a message for every client. It must only be called
+// from the run goroutine, which owns all mutations of the clients map and is
+// the only sender on each client's queue.
+func (h *WebSocketHub) broadcastMessage(message []byte) {
+	h.messagesBroadcast.Add(1)
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for client := range h.clients {
+		h.enqueue(client, message)
+	}
+}
+
+// enqueue adds a message to a client's queue without blocking. When the queue
+// is full the oldest queued message is dropped to make room.
+func (h *WebSocketHub) enqueue(client *wsClient, message []byte) {
+	select {
+	case client.send <- message:
+		return
+	default:
+	}
+
+	// Queue is full - drop the oldest message
+	select {
+	case <-client.send:
+		h.droppedMessages.Add(1)
+	default:
+	}
+
+	select {
+	case client.send <- message:
+	default:
+		h.droppedMessages.Add(1)
+	}
+}
+
+// writePump writes queued messages to the client connection until the queue is closed
+func (h *WebSocketHub) writePump(client *wsClient) {
+	defer h.writers.Done()
+	defer client.conn.Close()
+
+	for message := range client.send {
+		client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := client.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			h.logger.Warn().Err(err).Msg("Failed to send WebSocket message")
+			// Closing the connection makes the reader unregister the client
+			return
+		}
+	}
+
+	client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	client.conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+}
+
 // SendStatus broadcasts server status to all clients
 func (h *WebSocketHub) SendStatus(status string) {
+	h.recordEvent("status")
+	h.send(h.statusMessage(status))
+}
+
+// statusMessage builds a status message payload
+func (h *WebSocketHub) statusMessage(status string) []byte {
 	msg := map[string]interface{}{
 		"type":      "status",
 		"status":    status,
 		"timestamp": time.Now().Unix(),
 	}
 	data, _ := json.Marshal(msg)
-	h.broadcast <- data
+	return data
 }
 
 // SendCollectionUpdate broadcasts collection updates to all clients
 func (h *WebSocketHub) SendCollectionUpdate(eventType string, data interface{}) {
+	h.recordEvent(eventType)
 	msg := map[string]interface{}{
 		"type":      eventType,
 		"data":      data,
 		"timestamp": time.Now().Unix(),
 	}
 	jsonData, _ := json.Marshal(msg)
-	h.broadcast <- jsonData
+	h.send(jsonData)
+}
+
+// SendError broadcasts a newly tracked error so the UI can show it
+func (h *WebSocketHub) SendError(tracked TrackedError) {
+	h.SendCollectionUpdate("error", tracked)
+}
+
+// send queues a message for broadcast, discarding it once the hub is shut down
+func (h *WebSocketHub) send(message []byte) {
+	select {
+	case h.broadcast <- message:
+	case <-h.done:
+	}
+}
+
+// Shutdown closes all client connections with a close frame and waits for
+// their writers to finish or the context to expire
+func (h *WebSocketHub) Shutdown(ctx context.Context) error {
+	// Once closed is set no writer is added, so Wait below cannot race an Add
+	h.closeMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+	h.closeMu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// recordEvent increments the counter for an event type
+func (h *WebSocketHub) recordEvent(eventType string) {
+	h.eventMutex.Lock()
+	h.eventCounts[eventType]++
+	h.eventMutex.Unlock()
+}
+
+// Stats returns a snapshot of the hub's activity counters
+func (h *WebSocketHub) Stats() HubStats {
+	h.mutex.RLock()
+	connected := len(h.clients)
+	h.mutex.RUnlock()
+
+	h.eventMutex.Lock()
+	eventCounts := make(map[string]uint64, len(h.eventCounts))
+	for eventType, count := range h.eventCounts {
+		eventCounts[eventType] = count
+	}
+	h.eventMutex.Unlock()
+
+	return HubStats{
+		ConnectedClients:  connected,
+		MessagesBroadcast: h.messagesBroadcast.Load(),
+		MessagesDropped:   h.droppedMessages.Load(),
+		EventCounts:       eventCounts,
+	}
+}
+
+// ResetStats clears the message and event counters (connected clients are not affected)
+func (h *WebSocketHub) ResetStats() {
+	h.messagesBroadcast.Store(0)
+	h.droppedMessages.Store(0)
+
+	h.eventMutex.Lock()
+	h.eventCounts = make(map[string]uint64)
+	h.eventMutex.Unlock()
+}
+
+// StatsHandler returns the hub's activity counters
+func (h *WebSocketHub) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	if err := respondJSON(w, http.StatusOK, h.Stats()); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to encode WebSocket stats")
+	}
 }
 
-// streamLogs sends new logs to all connected clients
-// TODO: Implement server log streaming when Arbor v1.4.45 WebSocket API is clarified
-// The memory writer and log store interfaces need to be verified with source code access
+// streamLogs sends new in-memory log entries to clients that subscribed with
+// ?logs=<level>. It must only be called from the run goroutine and must not
+// log, or every poll would produce new entries to stream.
 func (h *WebSocketHub) streamLogs() {
-	// Placeholder for future implementation
-	// Arbor v1.4.45 has memory writer and WebSocket capabilities,
-	// but the exact API for retrieving logs from the store needs verification
+	memoryWriter := arbor.GetRegisteredMemoryWriter(arbor.WRITER_MEMORY)
+	if memoryWriter == nil {
+		return
+	}
+
+	events, err := memoryWriter.GetEntriesSince(h.lastLogTime.Add(-logLookback))
+	if err != nil {
+		return
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for _, event := range events {
+		if event.Index <= h.lastLogIndex {
+			continue
+		}
+		h.lastLogIndex = event.Index
+		if event.Timestamp.After(h.lastLogTime) {
+			h.lastLogTime = event.Timestamp
+		}
+
+		entry := newLogEntry(event)
+		var message []byte
+		for client := range h.clients {
+			if client.logLevel == "" || !meetsLevel(entry.Level, client.logLevel) {
+				continue
+			}
+			if message == nil {
+				message, _ = json.Marshal(map[string]interface{}{
+					"type":      "log",
+					"data":      entry,
+					"timestamp": time.Now().Unix(),
+				})
+			}
+			h.enqueue(client, message)
+		}
+	}
 }
 
-// Upgrader for WebSocket connections
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
+// checkOrigin allows same-host requests, requests without an Origin header and
+// origins in the configured allowlist. All origins are allowed when no
+// allowlist is configured.
+func (h *WebSocketHub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	allowed := h.config.Collector.AllowedOrigins
+	if len(allowed) == 0 {
 		return true // Allow all origins for Chrome extension
-	},
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+
+	h.logger.Warn().Str("origin", origin).Msg("WebSocket origin not allowed")
+	return false
+}
+
+// authenticate checks the API key presented as a ?token= query parameter or a
+// Sec-WebSocket-Protocol value. It returns the matched subprotocol (if any) so
+// it can be echoed back in the upgrade response.
+func (h *WebSocketHub) authenticate(r *http.Request) (bool, string) {
+	apiKey := h.config.Collector.APIKey
+	if apiKey == "" {
+		return true, ""
+	}
+
+	if token := r.URL.Query().Get("token"); token != "" && middleware.MatchAPIKey(token, apiKey) {
+		return true, ""
+	}
+
+	for _, protocol := range websocket.Subprotocols(r) {
+		if middleware.MatchAPIKey(protocol, apiKey) {
+			return true, protocol
+		}
+	}
+
+	return false, ""
+}
+
+// rejectShutdown closes a connection that arrived while the hub shuts down
+func (h *WebSocketHub) rejectShutdown(conn *websocket.Conn) {
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+		time.Now().Add(writeWait))
+	conn.Close()
 }
 
 // WebSocketHandler handles WebSocket connection requests
 func (h *WebSocketHub) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	ok, protocol := h.authenticate(r)
+	if !ok {
+		h.logger.Warn().Str("remote_addr", r.RemoteAddr).Msg("WebSocket connection rejected: missing or invalid token")
+		respondError(w, r, http.StatusUnauthorized, "Missing or invalid token")
+		return
+	}
+
+	var responseHeader http.Header
+	if protocol != "" {
+		responseHeader = http.Header{"Sec-WebSocket-Protocol": []string{protocol}}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("WebSocket upgrade failed")
 		return
 	}
 
-	h.register <- conn
+	client := &wsClient{
+		conn:     conn,
+		send:     make(chan []byte, clientSendBuffer),
+		logLevel: strings.ToLower(r.URL.Query().Get("logs")),
+	}
+
+	// The writer is counted before Shutdown can start waiting for writers
+	h.closeMu.Lock()
+	if h.closed {
+		h.closeMu.Unlock()
+		h.rejectShutdown(conn)
+		return
+	}
+	h.writers.Add(1)
+	h.closeMu.Unlock()
+
+	select {
+	case h.register <- client:
+	case <-h.done:
+		h.writers.Done()
+		h.rejectShutdown(conn)
+		return
+	}
+
+	// Each client gets its own writer so a slow client cannot stall the hub
+	go h.writePump(client)
 
 	// Keep connection alive and handle messages
 	go func() {
 		defer func() {
-			h.unregister <- conn
+			select {
+			case h.unregister <- client:
+			case <-h.done:
+			}
 		}()
 
 		for {
@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/handlers"
+
+	"github.com/gorilla/websocket"
+	"github.com/ternarybob/arbor"
+)
+
+// newTestHub starts a hub behind an httptest server and returns the server's
+// WebSocket URL
+func newTestHub(t *testing.T) (*handlers.WebSocketHub, string) {
+	t.Helper()
+	hub := handlers.NewWebSocketHub(common.DefaultConfig(), arbor.NewLogger())
+	server := httptest.NewServer(http.HandlerFunc(hub.WebSocketHandler))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx)
+		server.Close()
+	})
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// waitForClients waits until the hub has registered n clients
+func waitForClients(t *testing.T, hub *handlers.WebSocketHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Stats().ConnectedClients != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("connected clients = %d, want %d", hub.Stats().ConnectedClients, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// readEvent reads messages until one of the given type arrives
+func readEvent(t *testing.T, conn *websocket.Conn, eventType string) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("reading %s event: %v", eventType, err)
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("invalid WebSocket message %q: %v", data, err)
+		}
+		if msg["type"] == eventType {
+			return msg
+		}
+	}
+}
+
+func TestWebSocketHubBroadcast(t *testing.T) {
+	hub, url := newTestHub(t)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	waitForClients(t, hub, 1)
+
+	hub.SendCollectionUpdate("collection_success", map[string]interface{}{"url": "https://example.atlassian.net"})
+
+	msg := readEvent(t, conn, "collection_success")
+	data, _ := msg["data"].(map[string]interface{})
+	if data["url"] != "https://example.atlassian.net" {
+		t.Errorf("event data = %v", msg["data"])
+	}
+	if got := hub.Stats().EventCounts["collection_success"]; got != 1 {
+		t.Errorf("collection_success count = %d, want 1", got)
+	}
+}
+
+// TestWebSocketHubConcurrentClients connects, disconnects and broadcasts from
+// many goroutines at once. Run with -race to check the clients map is only
+// mutated by the hub.
+func TestWebSocketHubConcurrentClients(t *testing.T) {
+	hub, url := newTestHub(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+				if err != nil {
+					t.Errorf("dial: %v", err)
+					return
+				}
+				conn.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+				conn.ReadMessage()
+				conn.Close()
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				hub.SendCollectionUpdate("collection_progress", map[string]int{"sender": i, "seq": j})
+				hub.Stats()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every client has gone, so the hub ends up with none registered
+	waitForClients(t, hub, 0)
+}
+
+// TestWebSocketHubShutdownWhileConnecting shuts the hub down while clients
+// are still connecting. Shutdown must wait for every registered writer and
+// clients arriving afterwards must be turned away.
+func TestWebSocketHubShutdownWhileConnecting(t *testing.T) {
+	hub, url := newTestHub(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			// Read until the hub closes the connection
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	wg.Wait()
+
+	if got := hub.Stats().ConnectedClients; got != 0 {
+		t.Errorf("connected clients after shutdown = %d, want 0", got)
+	}
+}
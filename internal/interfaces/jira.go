@@ -2,20 +2,41 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"aktis-collector-jira/internal/models"
+
+	"golang.org/x/net/html"
 )
 
 // Storage defines the interface for persistent data storage operations
 type Storage interface {
-	SaveTickets(projectKey string, tickets map[string]*models.TicketData) error
+	SaveTickets(projectKey string, tickets map[string]*models.TicketData) (int, error)
+	ImportTickets(projectKey string, tickets []*models.TicketData) error
 	LoadTickets(projectKey string) (map[string]*models.TicketData, error)
 	LoadAllTickets() (map[string]*models.TicketData, error)
+	GetTicket(projectKey, key string) (*models.TicketData, error)
+	QueryTickets(query models.TicketQuery) (*models.TicketPage, error)
+	ScanTickets(query models.TicketQuery, fn func(*models.TicketData) error) error
 	ClearAllTickets() error
+	ClearProjectTickets(projectKey string) (int, error)
+	ClearedAt(projectKey string) (*time.Time, error)
 	ClearAllProjects() error
 	GetLastUpdate(projectKey string) (string, error)
-	SaveProjects(projects []*models.ProjectData) error
+	GetProjectFreshness() ([]*models.ProjectFreshness, error)
+	SaveProjects(projects []*models.ProjectData) (int, error)
+	CountStored() (projects, tickets int, err error)
 	LoadProjects() ([]*models.ProjectData, error)
+	SetProjectDisabled(projectKey string, disabled bool) error
+	AppendAssessment(record *models.AssessmentRecord) error
+	LoadAssessments(query models.AssessmentQuery) ([]*models.AssessmentRecord, error)
+	SaveExtensionStats(stats *models.ExtensionVersionStats) error
+	LoadExtensionStats() ([]*models.ExtensionVersionStats, error)
+	Backup(path string) (int64, error)
+	Ping() error
+	CheckWritable() error
+	Capacity() (*models.StorageCapacity, error)
+	OnStorageFull(fn func(*models.StorageCapacity))
 	Close() error
 }
 
@@ -24,9 +45,24 @@ type WebService interface {
 	Start(ctx context.Context) error
 	Stop() error
 	IsRunning() bool
+	SelfCheck() *models.SelfCheckReport
+}
+
+// Notifier delivers collection events to the configured webhooks. Notify
+// must not block the caller.
+type Notifier interface {
+	Notify(event *models.NotificationEvent)
+	Close()
+}
+
+// IssueFetcher fetches single issues from the Jira REST API. FetchIssue
+// returns nil when Jira has no issue with the key.
+type IssueFetcher interface {
+	FetchIssue(ctx context.Context, key string) (*models.TicketData, error)
 }
 
 // PageAssessor defines the interface for analyzing web page types
 type PageAssessor interface {
-	AssessPage(htmlContent, url string) (*models.PageAssessment, error)
+	AssessPage(ctx context.Context, htmlContent, url string) (*models.PageAssessment, error)
+	AssessDocument(ctx context.Context, doc *html.Node, url string) (*models.PageAssessment, error)
 }
@@ -0,0 +1,299 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the request duration histogram bounds in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// SizeBuckets are payload size histogram bounds in bytes, from 1 KB to 16 MB
+var SizeBuckets = []float64{1 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20}
+
+// Registry holds collector metrics and renders them in the Prometheus text format
+type Registry struct {
+	mutex      sync.Mutex
+	buckets    []float64
+	requests   map[requestKey]*requestStats
+	counters   map[string]*metric
+	gauges     map[string]*metric
+	histograms map[string]*histogram
+	startTime  time.Time
+}
+
+type requestKey struct {
+	route       string
+	method      string
+	statusClass string
+}
+
+type requestStats struct {
+	count        uint64
+	durationSum  float64
+	bucketCounts []uint64
+	sizeSum      uint64
+}
+
+type metric struct {
+	help   string
+	values map[string]float64 // keyed by rendered label set
+}
+
+type histogram struct {
+	help    string
+	buckets []float64
+	series  map[string]*histogramSeries // keyed by rendered label set
+}
+
+type histogramSeries struct {
+	labels       []string
+	count        uint64
+	sum          float64
+	bucketCounts []uint64
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		buckets:    DefaultBuckets,
+		requests:   make(map[requestKey]*requestStats),
+		counters:   make(map[string]*metric),
+		gauges:     make(map[string]*metric),
+		histograms: make(map[string]*histogram),
+		startTime:  time.Now(),
+	}
+}
+
+// ObserveRequest records a completed HTTP request. route should be the
+// registered pattern rather than the raw path to keep label cardinality bounded.
+func (m *Registry) ObserveRequest(route, method string, status int, duration time.Duration, size int64) {
+	key := requestKey{route: route, method: method, statusClass: fmt.Sprintf("%dxx", status/100)}
+	seconds := duration.Seconds()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats, ok := m.requests[key]
+	if !ok {
+		stats = &requestStats{bucketCounts: make([]uint64, len(m.buckets))}
+		m.requests[key] = stats
+	}
+
+	stats.count++
+	stats.durationSum += seconds
+	if size > 0 {
+		stats.sizeSum += uint64(size)
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			stats.bucketCounts[i]++
+		}
+	}
+}
+
+// AddCounter increments a counter by delta. labels are alternating name/value pairs.
+func (m *Registry) AddCounter(name, help string, delta float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	getMetric(m.counters, name, help).values[formatLabels(labels)] += delta
+}
+
+// SetGauge sets a gauge to value. labels are alternating name/value pairs.
+func (m *Registry) SetGauge(name, help string, value float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	getMetric(m.gauges, name, help).values[formatLabels(labels)] = value
+}
+
+// ObserveHistogram records value in a histogram with the given bucket upper
+// bounds. The buckets of the first observation are used for the histogram's
+// lifetime. labels are alternating name/value pairs.
+func (m *Registry) ObserveHistogram(name, help string, buckets []float64, value float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h, ok := m.histograms[name]
+	if !ok {
+		h = &histogram{help: help, buckets: buckets, series: make(map[string]*histogramSeries)}
+		m.histograms[name] = h
+	}
+
+	key := formatLabels(labels)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labels: labels, bucketCounts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	series.count++
+	series.sum += value
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.bucketCounts[i]++
+		}
+	}
+}
+
+func getMetric(metrics map[string]*metric, name, help string) *metric {
+	mt, ok := metrics[name]
+	if !ok {
+		mt = &metric{help: help, values: make(map[string]float64)}
+		metrics[name] = mt
+	}
+	return mt
+}
+
+// WriteTo renders all metrics in the Prometheus text exposition format
+func (m *Registry) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	m.mutex.Lock()
+
+	fmt.Fprintf(&b, "# HELP process_uptime_seconds Time since the collector started\n")
+	fmt.Fprintf(&b, "# TYPE process_uptime_seconds gauge\n")
+	fmt.Fprintf(&b, "process_uptime_seconds %g\n", time.Since(m.startTime).Seconds())
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, c := keys[i], keys[j]
+		if a.route != c.route {
+			return a.route < c.route
+		}
+		if a.method != c.method {
+			return a.method < c.method
+		}
+		return a.statusClass < c.statusClass
+	})
+
+	if len(keys) > 0 {
+		b.WriteString("# HELP http_requests_total Total HTTP requests by route, method and status class\n")
+		b.WriteString("# TYPE http_requests_total counter\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "http_requests_total%s %d\n", key.labels(), m.requests[key].count)
+		}
+
+		b.WriteString("# HELP http_request_duration_seconds HTTP request duration by route, method and status class\n")
+		b.WriteString("# TYPE http_request_duration_seconds histogram\n")
+		for _, key := range keys {
+			stats := m.requests[key]
+			for i, bound := range m.buckets {
+				fmt.Fprintf(&b, "http_request_duration_seconds_bucket%s %d\n", key.labelsWith("le", fmt.Sprintf("%g", bound)), stats.bucketCounts[i])
+			}
+			fmt.Fprintf(&b, "http_request_duration_seconds_bucket%s %d\n", key.labelsWith("le", "+Inf"), stats.count)
+			fmt.Fprintf(&b, "http_request_duration_seconds_sum%s %g\n", key.labels(), stats.durationSum)
+			fmt.Fprintf(&b, "http_request_duration_seconds_count%s %d\n", key.labels(), stats.count)
+		}
+
+		b.WriteString("# HELP http_response_size_bytes HTTP response body size by route, method and status class\n")
+		b.WriteString("# TYPE http_response_size_bytes summary\n")
+		for _, key := range keys {
+			stats := m.requests[key]
+			fmt.Fprintf(&b, "http_response_size_bytes_sum%s %d\n", key.labels(), stats.sizeSum)
+			fmt.Fprintf(&b, "http_response_size_bytes_count%s %d\n", key.labels(), stats.count)
+		}
+	}
+
+	writeMetrics(&b, m.counters, "counter")
+	writeMetrics(&b, m.gauges, "gauge")
+	writeHistograms(&b, m.histograms)
+
+	m.mutex.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func writeMetrics(b *strings.Builder, metrics map[string]*metric, metricType string) {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mt := metrics[name]
+		fmt.Fprintf(b, "# HELP %s %s\n", name, mt.help)
+		fmt.Fprintf(b, "# TYPE %s %s\n", name, metricType)
+
+		labelSets := make([]string, 0, len(mt.values))
+		for labels := range mt.values {
+			labelSets = append(labelSets, labels)
+		}
+		sort.Strings(labelSets)
+		for _, labels := range labelSets {
+			fmt.Fprintf(b, "%s%s %g\n", name, labels, mt.values[labels])
+		}
+	}
+}
+
+func writeHistograms(b *strings.Builder, histograms map[string]*histogram) {
+	names := make([]string, 0, len(histograms))
+	for name := range histograms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := histograms[name]
+		fmt.Fprintf(b, "# HELP %s %s\n", name, h.help)
+		fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+
+		keys := make([]string, 0, len(h.series))
+		for key := range h.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			series := h.series[key]
+			for i, bound := range h.buckets {
+				fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLabel(series.labels, "le", fmt.Sprintf("%g", bound)), series.bucketCounts[i])
+			}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", name, withLabel(series.labels, "le", "+Inf"), series.count)
+			fmt.Fprintf(b, "%s_sum%s %g\n", name, key, series.sum)
+			fmt.Fprintf(b, "%s_count%s %d\n", name, key, series.count)
+		}
+	}
+}
+
+// withLabel renders labels with one more name/value pair appended
+func withLabel(labels []string, name, value string) string {
+	return formatLabels(append(append([]string(nil), labels...), name, value))
+}
+
+// Handler serves the registry in the Prometheus text format
+func (m *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	}
+}
+
+func (k requestKey) labels() string {
+	return formatLabels([]string{"route", k.route, "method", k.method, "status", k.statusClass})
+}
+
+func (k requestKey) labelsWith(name, value string) string {
+	return formatLabels([]string{"route", k.route, "method", k.method, "status", k.statusClass, name, value})
+}
+
+// formatLabels renders name/value pairs as {name="value",...}
+func formatLabels(pairs []string) string {
+	if len(pairs) < 2 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"aktis-collector-jira/internal/common"
+)
+
+// APIKeyFromRequest extracts an API key from the X-API-Key header or a bearer token
+func APIKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+
+	return ""
+}
+
+// MatchAPIKey compares a presented key with the configured key in constant time
+func MatchAPIKey(presented, apiKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(apiKey)) == 1
+}
+
+// ValidAPIKey reports whether the request carries the configured API key.
+// Requests are always valid when no API key is configured.
+func ValidAPIKey(r *http.Request, apiKey string) bool {
+	if apiKey == "" {
+		return true
+	}
+	return MatchAPIKey(APIKeyFromRequest(r), apiKey)
+}
+
+// BasicAuth challenges UI requests for credentials when ui_auth is configured.
+// With use_api_key any username is accepted and the password must be the API key.
+func BasicAuth(config *common.CollectorConfig) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		ui := config.UIAuth
+		if ui.Username == "" && !ui.UseAPIKey {
+			return next
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || !validCredentials(ui, config.APIKey, username, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="`+config.Name+`", charset="UTF-8"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// validCredentials checks basic auth credentials. Values are hashed before
+// comparison so neither their content nor their length leaks through timing.
+func validCredentials(ui common.UIAuthConfig, apiKey, username, password string) bool {
+	if ui.UseAPIKey {
+		return secureCompare(password, apiKey)
+	}
+
+	userMatch := secureCompare(username, ui.Username)
+	passMatch := secureCompare(password, ui.Password)
+	return userMatch && passMatch
+}
+
+func secureCompare(presented, expected string) bool {
+	a := sha256.Sum256([]byte(presented))
+	b := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// APIKey rejects requests that do not carry the configured API key
+func APIKey(config *common.CollectorConfig) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !ValidAPIKey(r, config.APIKey) {
+				WriteError(w, r, http.StatusUnauthorized, "Missing or invalid API key")
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
@@ -8,7 +8,7 @@ func CORS(next http.HandlerFunc) http.HandlerFunc {
 		// Add CORS headers for Chrome extension
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 		// Handle preflight OPTIONS request
 		if r.Method == "OPTIONS" {
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the JSON body returned for all API errors
+type ErrorResponse struct {
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+	Status    int    `json:"status"`
+	Code      string `json:"code,omitempty"`
+	Details   string `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WriteError writes a JSON error response with the request's ID
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	WriteErrorResponse(w, r, ErrorResponse{Error: message, Status: status})
+}
+
+// WriteErrorResponse writes resp with its status code and the request's ID
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, resp ErrorResponse) {
+	resp.Success = false
+	resp.RequestID = GetRequestID(r)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Status)
+	json.NewEncoder(w).Encode(resp)
+}
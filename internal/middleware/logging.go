@@ -38,6 +38,7 @@ func Logging(logger arbor.ILogger) func(http.HandlerFunc) http.HandlerFunc {
 			duration := time.Since(start)
 
 			logger.Debug().
+				Str("request_id", GetRequestID(r)).
 				Str("method", r.Method).
 				Str("path", r.URL.Path).
 				Str("remote_addr", r.RemoteAddr).
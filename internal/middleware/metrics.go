@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"aktis-collector-jira/internal/metrics"
+)
+
+// metricsResponseWriter captures the status code and body size of a response
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	size       int64
+}
+
+func (mrw *metricsResponseWriter) WriteHeader(code int) {
+	mrw.statusCode = code
+	mrw.ResponseWriter.WriteHeader(code)
+}
+
+func (mrw *metricsResponseWriter) Write(b []byte) (int, error) {
+	n, err := mrw.ResponseWriter.Write(b)
+	mrw.size += int64(n)
+	return n, err
+}
+
+// Hijack lets WebSocket upgrades pass through the metrics wrapper
+func (mrw *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	mrw.statusCode = http.StatusSwitchingProtocols
+	return http.NewResponseController(mrw.ResponseWriter).Hijack()
+}
+
+func (mrw *metricsResponseWriter) Flush() {
+	http.NewResponseController(mrw.ResponseWriter).Flush()
+}
+
+func (mrw *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return mrw.ResponseWriter
+}
+
+// Metrics records request count, duration and response size in the registry.
+// route maps a request to its registered pattern so labels stay bounded.
+func Metrics(registry *metrics.Registry, route func(*http.Request) string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			mrw := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next(mrw, r)
+
+			registry.ObserveRequest(route(r), r.Method, mrw.statusCode, time.Since(start), mrw.size)
+		}
+	}
+}
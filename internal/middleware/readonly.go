@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"aktis-collector-jira/internal/common"
+)
+
+// ReadOnly rejects requests that would change the database when storage is
+// opened read-only. GET, HEAD and OPTIONS requests pass through.
+func ReadOnly(config *common.StorageConfig) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if config.ReadOnly {
+					WriteErrorResponse(w, r, ErrorResponse{
+						Error:  "This collector is in read-only mode (storage.read_only); send changes to the instance that writes the database",
+						Status: http.StatusForbidden,
+						Code:   "read_only",
+					})
+					return
+				}
+			}
+
+			next(w, r)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+// StorageCapacity compares the database size and the free space on its disk
+// with storage.max_database_mb and storage.min_free_disk_mb
+type StorageCapacity struct {
+	// DatabaseBytes is the data held, without the free pages a file keeps
+	// after deletes; FileBytes is the size of the file itself
+	DatabaseBytes    int64 `json:"database_bytes"`
+	FileBytes        int64 `json:"file_bytes"`
+	FreeDiskBytes    int64 `json:"free_disk_bytes"`
+	MaxDatabaseBytes int64 `json:"max_database_bytes,omitempty"`
+	MinFreeDiskBytes int64 `json:"min_free_disk_bytes,omitempty"`
+	// Exceeded is set while writes are refused, with the limit in Reason
+	Exceeded bool   `json:"exceeded"`
+	Reason   string `json:"reason,omitempty"`
+	// Near is set once a limit is close enough for storage.auto_prune to run
+	Near bool `json:"near"`
+}
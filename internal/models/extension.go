@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ExtensionVersionStats is the receiver traffic from one extension version
+type ExtensionVersionStats struct {
+	Version   string    `json:"version"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"`     // requests answered with a 4xx or 5xx status
+	SourceIPs []string  `json:"source_ips"` // distinct client addresses, capped
+}
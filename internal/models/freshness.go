@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Project freshness statuses
+const (
+	FreshnessFresh = "fresh"
+	FreshnessStale = "stale"
+	FreshnessNever = "never" // no collection recorded
+	// FreshnessDisabled marks a project whose collection is paused; it is
+	// never reported stale
+	FreshnessDisabled = "disabled"
+)
+
+// AgeDistribution counts tickets by how long ago their content last changed
+type AgeDistribution struct {
+	UnderDay   int `json:"under_1d"`
+	UnderWeek  int `json:"under_7d"`
+	UnderMonth int `json:"under_30d"`
+	Older      int `json:"older"`
+	Unknown    int `json:"unknown"` // missing or unparseable updated timestamp
+}
+
+// Add counts one ticket of the given age
+func (d *AgeDistribution) Add(age time.Duration) {
+	switch {
+	case age < 24*time.Hour:
+		d.UnderDay++
+	case age < 7*24*time.Hour:
+		d.UnderWeek++
+	case age < 30*24*time.Hour:
+		d.UnderMonth++
+	default:
+		d.Older++
+	}
+}
+
+// ProjectFreshness reports how recently a project was collected and how old
+// its tickets are. Reference tickets are not counted.
+type ProjectFreshness struct {
+	Key              string          `json:"key"`
+	Tickets          int             `json:"tickets"`
+	LastCollection   *time.Time      `json:"last_collection,omitempty"`
+	StalenessSeconds float64         `json:"staleness_seconds"` // time since LastCollection
+	Status           string          `json:"status"`            // fresh, stale or never
+	UpdatedAges      AgeDistribution `json:"updated_ages"`
+	// NewestJiraUpdate is the latest Jira updated time seen on the project's
+	// issue pages, as opposed to LastCollection, the local write time
+	NewestJiraUpdate *time.Time `json:"newest_jira_update,omitempty"`
+	// IncompleteSave is set while a batched save for the project is running,
+	// or when one stopped before its last batch
+	IncompleteSave *SaveProgress `json:"incomplete_save,omitempty"`
+}
+
+// SaveProgress records how far a batched ticket save has committed. Tickets
+// are saved in key order, so everything up to LastKey is stored. Fingerprint
+// identifies the saved tickets' keys and content, so a repeat of the same
+// save can resume after LastKey.
+type SaveProgress struct {
+	Started     time.Time `json:"started"`
+	Committed   int       `json:"committed"`
+	Total       int       `json:"total"`
+	LastKey     string    `json:"last_key"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+}
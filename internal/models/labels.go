@@ -0,0 +1,39 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxListItemLength caps a label or component name, in characters
+const maxListItemLength = 255
+
+// NormalizeList cleans a label or component list: items are trimmed, runs of
+// whitespace collapsed, empty items dropped, long items cut to
+// maxListItemLength and duplicates removed ignoring case, keeping the first
+// spelling. The result is sorted case-insensitively so the same set always
+// stores the same way. It returns nil when nothing is left.
+func NormalizeList(items []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		item = strings.Join(strings.Fields(item), " ")
+		if item == "" {
+			continue
+		}
+		if runes := []rune(item); len(runes) > maxListItemLength {
+			item = strings.TrimSpace(string(runes[:maxListItemLength]))
+		}
+		folded := strings.ToLower(item)
+		if seen[folded] {
+			continue
+		}
+		seen[folded] = true
+		result = append(result, item)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToLower(result[i]) < strings.ToLower(result[j])
+	})
+	return result
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeList(t *testing.T) {
+	long := strings.Repeat("x", maxListItemLength+10)
+
+	tests := []struct {
+		name  string
+		items []string
+		want  []string
+	}{
+		{"nil", nil, nil},
+		{"only blanks", []string{"", "  ", "\t"}, nil},
+		{"trimmed and collapsed", []string{"  needs   review ", "backend\n"}, []string{"backend", "needs review"}},
+		{"duplicates ignore case and keep the first spelling", []string{"Backend", "backend", "BACKEND"}, []string{"Backend"}},
+		{"sorted ignoring case", []string{"zeta", "Alpha", "beta"}, []string{"Alpha", "beta", "zeta"}},
+		{"long items are cut", []string{long}, []string{long[:maxListItemLength]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeList(tt.items); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeList(%q) = %q, want %q", tt.items, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergeTicketLabelsFromMixedSources merges labels from the API and from
+// loose HTML list text in both orders. The result is the same sorted set
+// either way; only the first spelling seen is kept.
+func TestMergeTicketLabelsFromMixedSources(t *testing.T) {
+	fromAPI := &TicketData{Key: "ABC-7", Labels: []string{"backend", "Login", "urgent"}, Components: []string{"Auth"}}
+	fromHTML := &TicketData{Key: "ABC-7", Labels: []string{" login ", "urgent", "", "needs  review"}, Components: []string{"auth", " API"}}
+
+	apiFirst := MergeTicket(&TicketData{Key: "ABC-7"}, fromAPI)
+	apiFirst = MergeTicket(apiFirst, fromHTML)
+	if want := []string{"backend", "Login", "needs review", "urgent"}; !reflect.DeepEqual(apiFirst.Labels, want) {
+		t.Errorf("API then HTML labels = %q, want %q", apiFirst.Labels, want)
+	}
+	if want := []string{"API", "Auth"}; !reflect.DeepEqual(apiFirst.Components, want) {
+		t.Errorf("API then HTML components = %q, want %q", apiFirst.Components, want)
+	}
+
+	htmlFirst := MergeTicket(&TicketData{Key: "ABC-7"}, fromHTML)
+	htmlFirst = MergeTicket(htmlFirst, fromAPI)
+	if want := []string{"backend", "login", "needs review", "urgent"}; !reflect.DeepEqual(htmlFirst.Labels, want) {
+		t.Errorf("HTML then API labels = %q, want %q", htmlFirst.Labels, want)
+	}
+
+	// Merging the same labels again changes nothing
+	again := MergeTicket(apiFirst, fromHTML)
+	if !reflect.DeepEqual(again.Labels, apiFirst.Labels) {
+		t.Errorf("labels after repeat merge = %q, want %q", again.Labels, apiFirst.Labels)
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// MergeTicket combines a stored ticket with an incoming copy of the same
+// ticket. Non-empty incoming values win and empty incoming values never erase
+// stored data, so a sparse record from a list page cannot wipe the details
+// collected from an issue page. Labels and components are the normalized
+// union of both copies. Comments, attachments, subtasks, links and work log
+// entries are merged by ID or key. Created is always kept from the
+// stored ticket.
+func MergeTicket(existing, incoming *TicketData) *TicketData {
+	merged := *existing
+
+	mergeString(&merged.ProjectID, incoming.ProjectID)
+	mergeString(&merged.URL, incoming.URL)
+	mergeString(&merged.Summary, incoming.Summary)
+	mergeString(&merged.Description, incoming.Description)
+	mergeString(&merged.IssueType, incoming.IssueType)
+	mergeString(&merged.Status, incoming.Status)
+	mergeString(&merged.Priority, incoming.Priority)
+	mergeString(&merged.Reporter, incoming.Reporter)
+	mergeString(&merged.Assignee, incoming.Assignee)
+	mergeString(&merged.RawHTML, incoming.RawHTML)
+	mergeString(&merged.Hash, incoming.Hash)
+	// A passing reference never relabels a ticket collected from its own page
+	if incoming.Source != SourceReference || merged.Source == "" {
+		mergeString(&merged.Source, incoming.Source)
+	}
+	if merged.Created == "" {
+		merged.Created = incoming.Created
+	}
+	// RFC 3339 UTC strings order by time; an older page never moves it back
+	if incoming.JiraUpdated > merged.JiraUpdated {
+		merged.JiraUpdated = incoming.JiraUpdated
+	}
+
+	if len(incoming.Labels) > 0 {
+		merged.Labels = NormalizeList(append(slices.Clip(existing.Labels), incoming.Labels...))
+	}
+	if len(incoming.Components) > 0 {
+		merged.Components = NormalizeList(append(slices.Clip(existing.Components), incoming.Components...))
+	}
+	if len(incoming.CustomFields) > 0 {
+		merged.CustomFields = make(map[string]interface{}, len(existing.CustomFields)+len(incoming.CustomFields))
+		maps.Copy(merged.CustomFields, existing.CustomFields)
+		maps.Copy(merged.CustomFields, incoming.CustomFields)
+	}
+
+	merged.Comments = mergeByKey(existing.Comments, incoming.Comments, func(c Comment) string { return c.ID })
+	merged.Attachments = mergeByKey(existing.Attachments, incoming.Attachments, func(a Attachment) string { return a.ID })
+	merged.Subtasks = mergeByKey(existing.Subtasks, incoming.Subtasks, func(s Subtask) string { return s.Key })
+	merged.WorkLog = mergeByKey(existing.WorkLog, incoming.WorkLog, func(w WorkLogEntry) string { return w.ID })
+	merged.Links = mergeByKey(existing.Links, incoming.Links, func(l IssueLink) string {
+		if l.IssueKey == "" {
+			return ""
+		}
+		return l.LinkType + "|" + l.Direction + "|" + l.IssueKey
+	})
+
+	return &merged
+}
+
+func mergeString(field *string, incoming string) {
+	if incoming != "" {
+		*field = incoming
+	}
+}
+
+// mergeByKey returns existing with incoming items applied: an item replaces
+// the existing item with the same key and is appended otherwise. Items without
+// a key are only appended when no identical item is stored.
+func mergeByKey[T comparable](existing, incoming []T, key func(T) string) []T {
+	if len(incoming) == 0 {
+		return existing
+	}
+
+	merged := append([]T(nil), existing...)
+	positions := make(map[string]int, len(merged))
+	for i, item := range merged {
+		if k := key(item); k != "" {
+			positions[k] = i
+		}
+	}
+
+	for _, item := range incoming {
+		k := key(item)
+		if k == "" {
+			if !slices.Contains(merged, item) {
+				merged = append(merged, item)
+			}
+			continue
+		}
+		if i, ok := positions[k]; ok {
+			merged[i] = item
+			continue
+		}
+		positions[k] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
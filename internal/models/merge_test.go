@@ -0,0 +1,221 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// detailTicket is a ticket as stored from its own issue page
+func detailTicket() *TicketData {
+	return &TicketData{
+		Key:          "ABC-7",
+		ProjectID:    "ABC",
+		URL:          "https://example.atlassian.net/browse/ABC-7",
+		Summary:      "Fix login",
+		Description:  "Login is broken after the upgrade",
+		IssueType:    "Bug",
+		Status:       "Open",
+		Priority:     "High",
+		Created:      "2024-01-02T10:00:00Z",
+		Updated:      "2024-01-05T10:00:00Z",
+		JiraUpdated:  "2024-01-04T09:00:00Z",
+		Reporter:     "bob",
+		Assignee:     "alice",
+		Labels:       []string{"backend", "login"},
+		Components:   []string{"Auth"},
+		CustomFields: map[string]interface{}{"story_points": 3.0},
+		Subtasks:     []Subtask{{Key: "ABC-8", Summary: "Write test", Status: "Open"}},
+		WorkLog:      []WorkLogEntry{{ID: "w1", Author: "alice", TimeSpent: "1h"}},
+		Source:       "extension",
+	}
+}
+
+func TestMergeTicket(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming *TicketData
+		check    func(t *testing.T, merged *TicketData)
+	}{
+		{
+			name:     "empty values never erase",
+			incoming: &TicketData{Key: "ABC-7", ProjectID: "ABC", Summary: "Fix login", Labels: []string{}},
+			check: func(t *testing.T, merged *TicketData) {
+				if want := detailTicket(); !reflect.DeepEqual(merged, want) {
+					t.Errorf("merged = %+v\nwant     %+v", merged, want)
+				}
+			},
+		},
+		{
+			name:     "non-empty values win",
+			incoming: &TicketData{Key: "ABC-7", Status: "Done", Assignee: "carol"},
+			check: func(t *testing.T, merged *TicketData) {
+				if merged.Status != "Done" || merged.Assignee != "carol" || merged.Summary != "Fix login" {
+					t.Errorf("status %q, assignee %q, summary %q", merged.Status, merged.Assignee, merged.Summary)
+				}
+			},
+		},
+		{
+			name:     "created is kept",
+			incoming: &TicketData{Key: "ABC-7", Created: "2024-03-01T00:00:00Z"},
+			check: func(t *testing.T, merged *TicketData) {
+				if merged.Created != "2024-01-02T10:00:00Z" {
+					t.Errorf("created = %q", merged.Created)
+				}
+			},
+		},
+		{
+			name:     "older jira update never moves it back",
+			incoming: &TicketData{Key: "ABC-7", JiraUpdated: "2024-01-01T00:00:00Z"},
+			check: func(t *testing.T, merged *TicketData) {
+				if merged.JiraUpdated != "2024-01-04T09:00:00Z" {
+					t.Errorf("jira_updated = %q", merged.JiraUpdated)
+				}
+			},
+		},
+		{
+			name:     "labels and components are a union",
+			incoming: &TicketData{Key: "ABC-7", Labels: []string{"LOGIN", "urgent"}, Components: []string{"API"}},
+			check: func(t *testing.T, merged *TicketData) {
+				if want := []string{"backend", "login", "urgent"}; !reflect.DeepEqual(merged.Labels, want) {
+					t.Errorf("labels = %v, want %v", merged.Labels, want)
+				}
+				if want := []string{"API", "Auth"}; !reflect.DeepEqual(merged.Components, want) {
+					t.Errorf("components = %v, want %v", merged.Components, want)
+				}
+			},
+		},
+		{
+			name:     "custom fields are combined",
+			incoming: &TicketData{Key: "ABC-7", CustomFields: map[string]interface{}{"sprint": "Sprint 4"}},
+			check: func(t *testing.T, merged *TicketData) {
+				want := map[string]interface{}{"story_points": 3.0, "sprint": "Sprint 4"}
+				if !reflect.DeepEqual(merged.CustomFields, want) {
+					t.Errorf("custom fields = %v, want %v", merged.CustomFields, want)
+				}
+			},
+		},
+		{
+			name: "subtasks and work log merge by key",
+			incoming: &TicketData{
+				Key:      "ABC-7",
+				Subtasks: []Subtask{{Key: "ABC-8", Summary: "Write test", Status: "Done"}, {Key: "ABC-9", Summary: "Deploy"}},
+				WorkLog:  []WorkLogEntry{{ID: "w2", Author: "bob", TimeSpent: "2h"}},
+			},
+			check: func(t *testing.T, merged *TicketData) {
+				wantSubtasks := []Subtask{{Key: "ABC-8", Summary: "Write test", Status: "Done"}, {Key: "ABC-9", Summary: "Deploy"}}
+				if !reflect.DeepEqual(merged.Subtasks, wantSubtasks) {
+					t.Errorf("subtasks = %+v", merged.Subtasks)
+				}
+				wantWorkLog := []WorkLogEntry{{ID: "w1", Author: "alice", TimeSpent: "1h"}, {ID: "w2", Author: "bob", TimeSpent: "2h"}}
+				if !reflect.DeepEqual(merged.WorkLog, wantWorkLog) {
+					t.Errorf("work log = %+v", merged.WorkLog)
+				}
+			},
+		},
+		{
+			name:     "a reference never relabels a collected ticket",
+			incoming: &TicketData{Key: "ABC-7", ProjectID: "ABC", Source: SourceReference},
+			check: func(t *testing.T, merged *TicketData) {
+				if merged.Source != "extension" {
+					t.Errorf("source = %q, want extension", merged.Source)
+				}
+			},
+		},
+		{
+			name:     "a collected page replaces the source",
+			incoming: &TicketData{Key: "ABC-7", Source: "api"},
+			check: func(t *testing.T, merged *TicketData) {
+				if merged.Source != "api" {
+					t.Errorf("source = %q, want api", merged.Source)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := detailTicket()
+			merged := MergeTicket(existing, tt.incoming)
+			tt.check(t, merged)
+			if !reflect.DeepEqual(existing, detailTicket()) {
+				t.Errorf("MergeTicket modified the stored ticket: %+v", existing)
+			}
+		})
+	}
+}
+
+func TestMergeTicketReferenceSource(t *testing.T) {
+	// A key first seen in passing is a reference until its own page arrives
+	reference := &TicketData{Key: "ABC-7", ProjectID: "ABC", Source: SourceReference}
+	if merged := MergeTicket(&TicketData{Key: "ABC-7"}, reference); merged.Source != SourceReference {
+		t.Errorf("source of unlabelled ticket = %q, want %s", merged.Source, SourceReference)
+	}
+	if merged := MergeTicket(reference, detailTicket()); merged.Source != "extension" {
+		t.Errorf("source after collection = %q, want extension", merged.Source)
+	}
+}
+
+func TestMergeTicketNestedByKey(t *testing.T) {
+	existing := &TicketData{
+		Key: "ABC-7",
+		Comments: []Comment{
+			{ID: "c1", Author: "bob", Body: "First"},
+			{ID: "c2", Author: "alice", Body: "Second"},
+		},
+		Attachments: []Attachment{{ID: "a1", Filename: "log.txt", Size: 10}},
+		Links: []IssueLink{
+			{LinkType: "blocks", Direction: "outward", IssueKey: "ABC-9", IssueSummary: "Deploy"},
+			{LinkType: "relates to", Direction: "outward", IssueSummary: "Unresolved link"},
+		},
+	}
+	incoming := &TicketData{
+		Key: "ABC-7",
+		Comments: []Comment{
+			{ID: "c2", Author: "alice", Body: "Second, edited"},
+			{ID: "c3", Author: "carol", Body: "Third"},
+		},
+		Attachments: []Attachment{
+			{ID: "a1", Filename: "log.txt", Size: 12},
+			{ID: "a2", Filename: "screenshot.png", Size: 2048},
+		},
+		Links: []IssueLink{
+			{LinkType: "blocks", Direction: "outward", IssueKey: "ABC-9", IssueSummary: "Deploy to production"},
+			{LinkType: "blocks", Direction: "inward", IssueKey: "ABC-9", IssueSummary: "Deploy to production"},
+			{LinkType: "relates to", Direction: "outward", IssueSummary: "Unresolved link"},
+		},
+	}
+
+	merged := MergeTicket(existing, incoming)
+
+	wantComments := []Comment{
+		{ID: "c1", Author: "bob", Body: "First"},
+		{ID: "c2", Author: "alice", Body: "Second, edited"},
+		{ID: "c3", Author: "carol", Body: "Third"},
+	}
+	if !reflect.DeepEqual(merged.Comments, wantComments) {
+		t.Errorf("comments = %+v\nwant %+v", merged.Comments, wantComments)
+	}
+	wantAttachments := []Attachment{
+		{ID: "a1", Filename: "log.txt", Size: 12},
+		{ID: "a2", Filename: "screenshot.png", Size: 2048},
+	}
+	if !reflect.DeepEqual(merged.Attachments, wantAttachments) {
+		t.Errorf("attachments = %+v\nwant %+v", merged.Attachments, wantAttachments)
+	}
+	// Links are keyed by type, direction and issue; a link without an issue
+	// key is only kept once
+	wantLinks := []IssueLink{
+		{LinkType: "blocks", Direction: "outward", IssueKey: "ABC-9", IssueSummary: "Deploy to production"},
+		{LinkType: "relates to", Direction: "outward", IssueSummary: "Unresolved link"},
+		{LinkType: "blocks", Direction: "inward", IssueKey: "ABC-9", IssueSummary: "Deploy to production"},
+	}
+	if !reflect.DeepEqual(merged.Links, wantLinks) {
+		t.Errorf("links = %+v\nwant %+v", merged.Links, wantLinks)
+	}
+
+	// A page without comments or attachments keeps the stored ones
+	merged = MergeTicket(merged, &TicketData{Key: "ABC-7", Summary: "Fix login"})
+	if len(merged.Comments) != 3 || len(merged.Attachments) != 2 || len(merged.Links) != 3 {
+		t.Errorf("after sparse page: %d comments, %d attachments, %d links", len(merged.Comments), len(merged.Attachments), len(merged.Links))
+	}
+}
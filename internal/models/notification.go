@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Notification events sent to webhooks
+const (
+	EventRunFailed             = "run_failed"
+	EventRunCompleted          = "run_completed"
+	EventTicketsAddedThreshold = "tickets_added_threshold"
+	// EventStorageFull is sent when writes start being refused because a
+	// storage limit was reached
+	EventStorageFull = "storage_full"
+)
+
+// NotificationEvent is the JSON payload posted to notification webhooks
+type NotificationEvent struct {
+	Event         string    `json:"event"`
+	Timestamp     time.Time `json:"timestamp"`
+	Collector     string    `json:"collector"`
+	Source        string    `json:"source"` // receiver or storage
+	Project       string    `json:"project,omitempty"`
+	PageType      string    `json:"page_type,omitempty"`
+	URL           string    `json:"url,omitempty"` // host and path only
+	ProjectsAdded int       `json:"projects_added"`
+	TicketsAdded  int       `json:"tickets_added"`
+	Error         string    `json:"error,omitempty"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // PageAssessment represents the result of analyzing a Jira page
 type PageAssessment struct {
 	PageType    string   `json:"page_type"`
@@ -8,3 +10,16 @@ type PageAssessment struct {
 	Indicators  []string `json:"indicators"`
 	Collectable bool     `json:"collectable"`
 }
+
+// AssessmentRecord is one entry in the page assessment history kept for
+// diagnosing detection changes
+type AssessmentRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Source      string    `json:"source"` // assess or receiver
+	URL         string    `json:"url"`    // host and path only
+	PageType    string    `json:"page_type"`
+	Confidence  string    `json:"confidence"`
+	Indicators  []string  `json:"indicators"`
+	Collectable bool      `json:"collectable"`
+	Outcome     string    `json:"outcome"` // assessed, collected, skipped, empty or failed
+}
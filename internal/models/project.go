@@ -9,4 +9,6 @@ type ProjectData struct {
 	URL         string `json:"url"`
 	Description string `json:"description"`
 	Updated     string `json:"updated"`
+	// Disabled pauses collection for the project; see POST /projects/{key}/disable
+	Disabled bool `json:"disabled,omitempty"`
 }
@@ -0,0 +1,32 @@
+package models
+
+// TicketQuery describes a filtered, sorted and paginated ticket listing
+type TicketQuery struct {
+	Project    string `json:"project,omitempty"`
+	Status     string `json:"status,omitempty"`
+	SortBy     string `json:"sort_by,omitempty"` // key, summary, status, priority, assignee, updated or empty for storage order
+	Descending bool   `json:"descending,omitempty"`
+	Page       int    `json:"page"`      // 1-based
+	PageSize   int    `json:"page_size"` // tickets per page
+
+	// IncludeReferences keeps SourceReference skeleton tickets in the result
+	IncludeReferences bool `json:"include_references,omitempty"`
+}
+
+// TicketPage is one page of a ticket query result
+type TicketPage struct {
+	Tickets    []*TicketData `json:"tickets"`
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	TotalPages int           `json:"total_pages"`
+	Statuses   []string      `json:"statuses"` // distinct statuses in the project, for filtering
+}
+
+// AssessmentQuery selects entries from the page assessment history, newest
+// first
+type AssessmentQuery struct {
+	PageType string `json:"page_type,omitempty"`
+	Outcome  string `json:"outcome,omitempty"`
+	Limit    int    `json:"limit"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Self-check statuses, from best to worst
+const (
+	SelfCheckPass = "pass"
+	SelfCheckWarn = "warn" // works, but part of the setup is missing
+	SelfCheckFail = "fail"
+)
+
+// SelfCheckResult is the outcome of one setup check
+type SelfCheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// SelfCheckReport is the outcome of a self-check run. Status is the worst
+// status of its checks.
+type SelfCheckReport struct {
+	Status    string            `json:"status"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Checks    []SelfCheckResult `json:"checks"`
+}
+
+// Add appends a check result and lowers the report status to match it
+func (r *SelfCheckReport) Add(result SelfCheckResult) {
+	r.Checks = append(r.Checks, result)
+	if selfCheckRank(result.Status) > selfCheckRank(r.Status) {
+		r.Status = result.Status
+	}
+}
+
+func selfCheckRank(status string) int {
+	switch status {
+	case SelfCheckFail:
+		return 2
+	case SelfCheckWarn:
+		return 1
+	default:
+		return 0
+	}
+}
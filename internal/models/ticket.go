@@ -2,16 +2,19 @@ package models
 
 // TicketData represents a Jira ticket/issue with comprehensive details
 type TicketData struct {
-	Key          string                 `json:"key"`
-	ProjectID    string                 `json:"project_id"`
-	URL          string                 `json:"url"`
-	Summary      string                 `json:"summary"`
-	Description  string                 `json:"description"`
-	IssueType    string                 `json:"issue_type"`
-	Status       string                 `json:"status"`
-	Priority     string                 `json:"priority"`
-	Created      string                 `json:"created"`
-	Updated      string                 `json:"updated"`
+	Key         string `json:"key"`
+	ProjectID   string `json:"project_id"`
+	URL         string `json:"url"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+	IssueType   string `json:"issue_type"`
+	Status      string `json:"status"`
+	Priority    string `json:"priority"`
+	Created     string `json:"created"`
+	Updated     string `json:"updated"`
+	// JiraUpdated is Jira's own updated time for the issue (RFC 3339, UTC)
+	// when the page showed it, unlike Updated, which is the local write time
+	JiraUpdated  string                 `json:"jira_updated,omitempty"`
 	Reporter     string                 `json:"reporter"`
 	Assignee     string                 `json:"assignee"`
 	Labels       []string               `json:"labels"`
@@ -26,9 +29,20 @@ type TicketData struct {
 	WorkLog     []WorkLogEntry `json:"worklog,omitempty"`
 	RawHTML     string         `json:"raw_html,omitempty"` // Keep raw HTML for future parsing
 
-	Hash string `json:"hash"`
+	// Source names the collector that last enriched the ticket, e.g.
+	// "extension", or SourceReference for a key seen only in passing
+	Source string `json:"source,omitempty"`
+
+	// Hash is common.TicketHash of the content, computed by HashVersion
+	Hash        string `json:"hash"`
+	HashVersion int    `json:"hash_version,omitempty"`
 }
 
+// SourceReference marks a skeleton ticket created from a key mentioned on a
+// board or generic page. It carries no content and is left out of ticket
+// lists and statistics unless references are asked for.
+const SourceReference = "reference"
+
 // Comment represents a ticket comment
 type Comment struct {
 	ID      string `json:"id"`
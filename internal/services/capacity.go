@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+
+	"github.com/ternarybob/arbor"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// Auto-prune starts once the database reaches pruneDatabasePercent of
+	// max_database_mb or the free disk space falls below pruneFreeDiskFactor
+	// times min_free_disk_mb
+	pruneDatabasePercent = 90
+	pruneFreeDiskFactor  = 2
+	// pruneCooldown is the least time between two automatic prunes
+	pruneCooldown = 10 * time.Minute
+	// pruneBatchSize is the number of tickets changed per prune transaction
+	pruneBatchSize = 500
+)
+
+// log returns the storage logger. It is looked up on use, so commands that
+// open the database without logging anything do not set up a logger.
+func (s *storage) log() arbor.ILogger {
+	return common.GetLogger("storage")
+}
+
+// OnStorageFull registers fn to be called, outside the storage locks, each
+// time a storage limit starts refusing writes
+func (s *storage) OnStorageFull(fn func(*models.StorageCapacity)) {
+	s.capacityMu.Lock()
+	s.onFull = fn
+	s.capacityMu.Unlock()
+}
+
+// Capacity measures the database and its disk against storage.max_database_mb
+// and storage.min_free_disk_mb. The data size leaves out free pages, so it
+// drops after a prune even though bbolt never shrinks the file; it is only
+// brought up to date by the next write. A change into or out of the exceeded
+// state is logged, and with storage.auto_prune a limit that is near starts a
+// prune in the background.
+func (s *storage) Capacity() (*models.StorageCapacity, error) {
+	capacity := &models.StorageCapacity{
+		MaxDatabaseBytes: int64(s.config.MaxDatabaseMB) << 20,
+		MinFreeDiskBytes: int64(s.config.MinFreeDiskMB) << 20,
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		capacity.FileBytes = tx.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, databaseError(s.config.DatabasePath, err)
+	}
+	capacity.DatabaseBytes = capacity.FileBytes - int64(s.db.Stats().FreeAlloc)
+
+	free, err := diskFree(filepath.Dir(s.config.DatabasePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read free disk space: %w", err)
+	}
+	capacity.FreeDiskBytes = free
+
+	maxBytes, minFree := capacity.MaxDatabaseBytes, capacity.MinFreeDiskBytes
+	switch {
+	case maxBytes > 0 && capacity.DatabaseBytes >= maxBytes:
+		capacity.Exceeded = true
+		capacity.Reason = fmt.Sprintf("database holds %d MB, storage.max_database_mb is %d", capacity.DatabaseBytes>>20, s.config.MaxDatabaseMB)
+	case minFree > 0 && free < minFree:
+		capacity.Exceeded = true
+		capacity.Reason = fmt.Sprintf("%d MB free on the database disk, storage.min_free_disk_mb is %d", free>>20, s.config.MinFreeDiskMB)
+	}
+	capacity.Near = capacity.Exceeded ||
+		(maxBytes > 0 && capacity.DatabaseBytes*100 >= maxBytes*pruneDatabasePercent) ||
+		(minFree > 0 && free < minFree*pruneFreeDiskFactor)
+
+	s.recordCapacity(capacity)
+	return capacity, nil
+}
+
+// recordCapacity logs a change into or out of the exceeded state, calls the
+// OnStorageFull callback on the change in, and starts an automatic prune when
+// a limit is near
+func (s *storage) recordCapacity(capacity *models.StorageCapacity) {
+	s.capacityMu.Lock()
+	changed := capacity.Exceeded != s.full
+	s.full = capacity.Exceeded
+	onFull := s.onFull
+	s.capacityMu.Unlock()
+
+	if changed && capacity.Exceeded {
+		s.log().Warn().Str("reason", capacity.Reason).Msg("Storage limit reached, refusing writes")
+		if onFull != nil {
+			onFull(capacity)
+		}
+	} else if changed {
+		s.log().Info().Msg("Storage back within its limits, accepting writes")
+	}
+
+	if capacity.Near && s.config.AutoPrune && !s.config.ReadOnly {
+		s.startPrune()
+	}
+}
+
+// checkCapacity refuses a write with a storage_full error while a storage
+// limit is exceeded. A capacity that cannot be measured does not block the
+// write.
+func (s *storage) checkCapacity() error {
+	if s.config.MaxDatabaseMB == 0 && s.config.MinFreeDiskMB == 0 {
+		return nil
+	}
+
+	capacity, err := s.Capacity()
+	if err != nil {
+		s.log().Warn().Err(err).Msg("Failed to measure storage capacity, allowing write")
+		return nil
+	}
+	if capacity.Exceeded {
+		return common.WrapError(common.ErrStorageFull, common.ErrorTypeStorage, "storage_full", "Storage limit reached: "+capacity.Reason)
+	}
+	return nil
+}
+
+// checkBackupSpace refuses a backup of size bytes that would leave less than
+// storage.min_free_disk_mb free on the disk holding dir
+func (s *storage) checkBackupSpace(dir string, size int64) error {
+	free, err := diskFree(dir)
+	if err != nil {
+		s.log().Warn().Err(err).Str("dir", dir).Msg("Failed to read free disk space, writing backup anyway")
+		return nil
+	}
+
+	if needed := size + int64(s.config.MinFreeDiskMB)<<20; free < needed {
+		return common.WrapError(common.ErrStorageFull, common.ErrorTypeStorage, "storage_full",
+			fmt.Sprintf("Backup of %d MB would leave less than storage.min_free_disk_mb %d free in %s (%d MB free)", size>>20, s.config.MinFreeDiskMB, dir, free>>20))
+	}
+	return nil
+}
+
+// startPrune runs prune in the background unless a prune is running or one
+// finished within pruneCooldown
+func (s *storage) startPrune() {
+	s.capacityMu.Lock()
+	if s.pruning || time.Since(s.lastPrune) < pruneCooldown {
+		s.capacityMu.Unlock()
+		return
+	}
+	s.pruning = true
+	s.capacityMu.Unlock()
+
+	s.pruneWG.Add(1)
+	go func() {
+		defer s.pruneWG.Done()
+		s.log().Info().Msg("Storage limit near, pruning old tickets and raw HTML")
+		removed, stripped, err := s.prune()
+
+		s.capacityMu.Lock()
+		s.pruning = false
+		s.lastPrune = time.Now()
+		s.capacityMu.Unlock()
+
+		if err != nil {
+			s.log().Error().Err(err).Int("removed", removed).Int("stripped", stripped).Msg("Storage prune failed")
+			return
+		}
+		s.log().Info().Int("removed", removed).Int("stripped", stripped).Msg("Storage pruned")
+	}()
+}
+
+// prune deletes tickets whose content has not changed within
+// storage.retention_days and strips the stored raw HTML from the rest, in
+// write transactions of pruneBatchSize tickets. It returns the number of
+// tickets deleted and stripped.
+func (s *storage) prune() (removed, stripped int, err error) {
+	var cutoff time.Time
+	if days := s.config.CurrentRetentionDays(); days > 0 {
+		cutoff = time.Now().AddDate(0, 0, -days)
+	}
+
+	var expired, withHTML [][]byte
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(ticketsBucket)).ForEach(func(k, v []byte) error {
+			var ticket models.TicketData
+			if err := json.Unmarshal(v, &ticket); err != nil {
+				return nil
+			}
+			key := append([]byte(nil), k...)
+			if updated, err := time.Parse(time.RFC3339, ticket.Updated); err == nil && !cutoff.IsZero() && updated.Before(cutoff) {
+				expired = append(expired, key)
+			} else if ticket.RawHTML != "" {
+				withHTML = append(withHTML, key)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for start := 0; start < len(expired); start += pruneBatchSize {
+		batch := expired[start:min(start+pruneBatchSize, len(expired))]
+		deleted := 0
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket([]byte(ticketsBucket))
+			for _, key := range batch {
+				// The ticket may have been captured again or gone since the scan
+				var ticket models.TicketData
+				data := bucket.Get(key)
+				if data == nil || json.Unmarshal(data, &ticket) != nil {
+					continue
+				}
+				if updated, err := time.Parse(time.RFC3339, ticket.Updated); err != nil || !updated.Before(cutoff) {
+					continue
+				}
+				if err := bucket.Delete(key); err != nil {
+					return fmt.Errorf("failed to delete ticket %s: %w", key, err)
+				}
+				deleted++
+			}
+			return nil
+		})
+		if err != nil {
+			return removed, stripped, err
+		}
+		removed += deleted
+	}
+
+	for start := 0; start < len(withHTML); start += pruneBatchSize {
+		batch := withHTML[start:min(start+pruneBatchSize, len(withHTML))]
+		rewritten := 0
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket([]byte(ticketsBucket))
+			for _, key := range batch {
+				// The ticket may have changed or gone since the scan
+				var ticket models.TicketData
+				data := bucket.Get(key)
+				if data == nil || json.Unmarshal(data, &ticket) != nil || ticket.RawHTML == "" {
+					continue
+				}
+				ticket.RawHTML = ""
+				stored, err := json.Marshal(&ticket)
+				if err != nil {
+					return fmt.Errorf("failed to marshal ticket %s: %w", key, err)
+				}
+				if err := bucket.Put(key, stored); err != nil {
+					return fmt.Errorf("failed to store ticket %s: %w", key, err)
+				}
+				rewritten++
+			}
+			return nil
+		})
+		if err != nil {
+			return removed, stripped, err
+		}
+		stripped += rewritten
+	}
+
+	return removed, stripped, nil
+}
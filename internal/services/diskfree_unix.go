@@ -0,0 +1,14 @@
+//go:build !windows
+
+package services
+
+import "golang.org/x/sys/unix"
+
+// diskFree returns the bytes available to this process on the disk holding dir
+func diskFree(dir string) (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
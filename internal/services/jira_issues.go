@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+)
+
+// jiraIssueFields are the issue fields requested by FetchJiraIssue
+const jiraIssueFields = "summary,description,issuetype,status,priority,updated,reporter,assignee,labels,components"
+
+// jiraTimeLayout is the timestamp format of Jira REST API fields
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// jiraIssue is the body of /rest/api/2/issue/{key}
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string      `json:"summary"`
+		Description string      `json:"description"`
+		IssueType   *jiraNamed  `json:"issuetype"`
+		Status      *jiraNamed  `json:"status"`
+		Priority    *jiraNamed  `json:"priority"`
+		Updated     string      `json:"updated"`
+		Reporter    *jiraUser   `json:"reporter"`
+		Assignee    *jiraUser   `json:"assignee"`
+		Labels      []string    `json:"labels"`
+		Components  []jiraNamed `json:"components"`
+	} `json:"fields"`
+}
+
+type jiraNamed struct {
+	Name string `json:"name"`
+}
+
+type jiraUser struct {
+	DisplayName string `json:"displayName"`
+}
+
+// jiraIssueFetcher implements interfaces.IssueFetcher with FetchJiraIssue
+type jiraIssueFetcher struct {
+	config *common.JiraConfig
+}
+
+// NewJiraIssueFetcher returns an IssueFetcher for the configured Jira site
+func NewJiraIssueFetcher(config *common.JiraConfig) interfaces.IssueFetcher {
+	return &jiraIssueFetcher{config: config}
+}
+
+func (f *jiraIssueFetcher) FetchIssue(ctx context.Context, key string) (*models.TicketData, error) {
+	return FetchJiraIssue(ctx, f.config, key)
+}
+
+// FetchJiraIssue fetches one issue through the REST API and returns it as a
+// ticket, or nil when Jira has no issue with that key. Errors are typed as
+// for FetchJiraProjects.
+func FetchJiraIssue(ctx context.Context, config *common.JiraConfig, key string) (*models.TicketData, error) {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=" + url.QueryEscape(jiraIssueFields)
+
+	var issue jiraIssue
+	if err := getJira(ctx, config, path, "issue "+key, &issue); err != nil {
+		var collectorErr *common.CollectorError
+		if errors.As(err, &collectorErr) && collectorErr.Code == "jira_not_found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	projectKey, ok := common.IssueKeyProject(issue.Key)
+	if !ok {
+		return nil, common.NewJiraError("jira_invalid_response", "jira returned the malformed issue key "+issue.Key)
+	}
+	fields := issue.Fields
+	ticket := &models.TicketData{
+		Key:         issue.Key,
+		ProjectID:   projectKey,
+		URL:         common.ResolveURL(config.BaseURL, "/browse/"+issue.Key),
+		Summary:     fields.Summary,
+		Description: fields.Description,
+		Labels:      models.NormalizeList(fields.Labels),
+	}
+	if fields.IssueType != nil {
+		ticket.IssueType = fields.IssueType.Name
+	}
+	if fields.Status != nil {
+		ticket.Status = fields.Status.Name
+	}
+	if fields.Priority != nil {
+		ticket.Priority = fields.Priority.Name
+	}
+	if fields.Reporter != nil {
+		ticket.Reporter = fields.Reporter.DisplayName
+	}
+	if fields.Assignee != nil {
+		ticket.Assignee = fields.Assignee.DisplayName
+	}
+	components := make([]string, 0, len(fields.Components))
+	for _, component := range fields.Components {
+		components = append(components, component.Name)
+	}
+	ticket.Components = models.NormalizeList(components)
+	if updated, err := time.Parse(jiraTimeLayout, fields.Updated); err == nil {
+		ticket.JiraUpdated = updated.UTC().Format(time.RFC3339)
+	}
+	return ticket, nil
+}
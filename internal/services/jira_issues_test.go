@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+)
+
+func TestFetchJiraIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/rest/api/2/issue/ABC-1":
+			w.Write([]byte(`{"key":"ABC-1","fields":{
+				"summary":"Login fails","description":"Steps to reproduce",
+				"issuetype":{"name":"Bug"},"status":{"name":"In Progress"},"priority":{"name":"High"},
+				"updated":"2024-03-05T10:15:00.000+1100",
+				"reporter":{"displayName":"Ann"},"assignee":null,
+				"labels":["auth","ui"],"components":[{"name":"Web"}]}}`))
+		case "/rest/api/2/issue/ABC-404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessages":["Issue does not exist or you do not have permission to see it."]}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	config := &common.JiraConfig{BaseURL: server.URL, TimeoutSeconds: 5}
+	config.API.Username = "me@example.com"
+	config.API.APIToken = "secret"
+
+	ticket, err := FetchJiraIssue(context.Background(), config, "ABC-1")
+	if err != nil {
+		t.Fatalf("FetchJiraIssue: %v", err)
+	}
+	if ticket.Key != "ABC-1" || ticket.ProjectID != "ABC" || ticket.Summary != "Login fails" ||
+		ticket.Description != "Steps to reproduce" || ticket.IssueType != "Bug" ||
+		ticket.Status != "In Progress" || ticket.Priority != "High" ||
+		ticket.Reporter != "Ann" || ticket.Assignee != "" {
+		t.Errorf("ticket = %+v", ticket)
+	}
+	if ticket.URL != server.URL+"/browse/ABC-1" {
+		t.Errorf("url = %q", ticket.URL)
+	}
+	if ticket.JiraUpdated != "2024-03-04T23:15:00Z" {
+		t.Errorf("jira_updated = %q", ticket.JiraUpdated)
+	}
+	if !slices.Equal(ticket.Labels, []string{"auth", "ui"}) || !slices.Equal(ticket.Components, []string{"Web"}) {
+		t.Errorf("labels = %v, components = %v", ticket.Labels, ticket.Components)
+	}
+
+	ticket, err = FetchJiraIssue(context.Background(), config, "ABC-404")
+	if err != nil || ticket != nil {
+		t.Errorf("missing issue: ticket = %+v, err = %v, want nil, nil", ticket, err)
+	}
+
+	if _, err := FetchJiraIssue(context.Background(), config, "ABC-500"); errorType(err) != common.ErrorTypeJira {
+		t.Errorf("server error: err = %v, want a jira error", err)
+	}
+
+	config.API.APIToken = "wrong"
+	if _, err := FetchJiraIssue(context.Background(), config, "ABC-1"); errorType(err) != common.ErrorTypeAuth {
+		t.Errorf("wrong token: err = %v, want an auth error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+)
+
+// maxJiraResponseBytes bounds one response read from Jira
+const maxJiraResponseBytes = 16 << 20
+
+// FetchJiraProjects lists the projects visible to the configured Jira account
+// through the REST API. Its errors are CollectorErrors typed configuration
+// for missing credentials, auth when Jira rejects them, network when Jira
+// cannot be reached and jira for an unexpected response.
+func FetchJiraProjects(ctx context.Context, config *common.JiraConfig) ([]*models.ProjectData, error) {
+	var projects []*models.ProjectData
+	if err := getJira(ctx, config, "/rest/api/2/project", "the project list", &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// getJira requests path from the Jira REST API with the configured
+// credentials and decodes the JSON response into out. what names the
+// resource in error messages. A 404 is a jira error with the code
+// jira_not_found.
+func getJira(ctx context.Context, config *common.JiraConfig, path, what string, out interface{}) error {
+	if !config.HasCredentials() {
+		return common.NewConfigurationError("jira_credentials_missing",
+			"jira base_url, api.username and api.api_token must be configured")
+	}
+
+	endpoint := strings.TrimRight(config.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return jiraError(err, common.ErrorTypeConfiguration, "jira_base_url_invalid", "invalid jira base_url")
+	}
+	req.SetBasicAuth(config.API.Username, config.API.APIToken)
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: time.Duration(config.TimeoutSeconds) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return jiraError(err, common.ErrorTypeNetwork, "jira_unreachable", "failed to reach Jira at "+config.BaseURL)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return common.NewAuthError("jira_credentials_rejected",
+			fmt.Sprintf("jira rejected the credentials for %s (%s)", config.API.Username, resp.Status))
+	case resp.StatusCode == http.StatusNotFound:
+		return common.NewJiraError("jira_not_found",
+			fmt.Sprintf("jira returned %s for %s", resp.Status, what))
+	case resp.StatusCode != http.StatusOK:
+		return common.NewJiraError("jira_status",
+			fmt.Sprintf("jira returned %s for %s", resp.Status, what))
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJiraResponseBytes)).Decode(out); err != nil {
+		return jiraError(err, common.ErrorTypeJira, "jira_invalid_response", "invalid response from Jira for "+what)
+	}
+	return nil
+}
+
+// jiraError wraps err as a CollectorError that keeps its text in the details
+func jiraError(err error, errorType common.ErrorType, code, message string) error {
+	wrapped := common.WrapError(err, errorType, code, message)
+	wrapped.Details = err.Error()
+	return wrapped
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aktis-collector-jira/internal/common"
+)
+
+func TestFetchJiraProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/project" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"id":"1","key":"ABC","name":"Alpha"},{"id":"2","key":"DEV","name":"Development"}]`))
+	}))
+	defer server.Close()
+
+	config := &common.JiraConfig{BaseURL: server.URL + "/", TimeoutSeconds: 5}
+	config.API.Username = "me@example.com"
+	config.API.APIToken = "secret"
+
+	projects, err := FetchJiraProjects(context.Background(), config)
+	if err != nil {
+		t.Fatalf("FetchJiraProjects: %v", err)
+	}
+	if len(projects) != 2 || projects[0].Key != "ABC" || projects[1].Name != "Development" {
+		t.Errorf("projects = %+v", projects)
+	}
+
+	config.API.APIToken = "wrong"
+	_, err = FetchJiraProjects(context.Background(), config)
+	if errorType(err) != common.ErrorTypeAuth || !strings.Contains(err.Error(), "rejected the credentials") {
+		t.Errorf("wrong token: err = %v", err)
+	}
+
+	config.API.APIToken = ""
+	if _, err := FetchJiraProjects(context.Background(), config); errorType(err) != common.ErrorTypeConfiguration {
+		t.Errorf("missing token: err = %v, want a configuration error", err)
+	}
+
+	config.API.APIToken = "secret"
+	config.BaseURL = server.URL + "/missing"
+	if _, err := FetchJiraProjects(context.Background(), config); errorType(err) != common.ErrorTypeJira {
+		t.Errorf("unexpected status: err = %v, want a jira error", err)
+	}
+
+	server.Close()
+	config.BaseURL = server.URL
+	if _, err := FetchJiraProjects(context.Background(), config); errorType(err) != common.ErrorTypeNetwork {
+		t.Errorf("server down: err = %v, want a network error", err)
+	}
+}
+
+// errorType returns the type of the CollectorError in err's chain, or ""
+func errorType(err error) common.ErrorType {
+	var collectorErr *common.CollectorError
+	if errors.As(err, &collectorErr) {
+		return collectorErr.Type
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/metrics"
+	"aktis-collector-jira/internal/models"
+
+	"github.com/ternarybob/arbor"
+)
+
+// notifierQueueSize bounds the events waiting for delivery; further events
+// are dropped so a slow webhook never holds up collection
+const notifierQueueSize = 100
+
+// notifier posts collection events to the configured webhooks from a single
+// background worker, retrying failed deliveries and pausing webhooks that
+// keep failing
+type notifier struct {
+	config  *common.NotificationsConfig
+	client  *http.Client
+	logger  arbor.ILogger
+	metrics *metrics.Registry
+
+	events    chan *models.NotificationEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	stopped   chan struct{}
+
+	// Circuit breaker state by webhook index
+	failures    []int
+	pausedUntil []time.Time
+}
+
+// NewNotifier starts a notifier for the configured webhooks
+func NewNotifier(config *common.NotificationsConfig, logger arbor.ILogger, registry *metrics.Registry) interfaces.Notifier {
+	n := &notifier{
+		config:      config,
+		client:      &http.Client{Timeout: time.Duration(config.TimeoutSeconds) * time.Second},
+		logger:      logger,
+		metrics:     registry,
+		events:      make(chan *models.NotificationEvent, notifierQueueSize),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+		failures:    make([]int, len(config.Webhooks)),
+		pausedUntil: make([]time.Time, len(config.Webhooks)),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues event for every webhook that wants it. It never blocks: the
+// event is dropped when the queue is full or the notifier is closed.
+func (n *notifier) Notify(event *models.NotificationEvent) {
+	if !n.wanted(event) {
+		return
+	}
+
+	select {
+	case <-n.done:
+		return
+	default:
+	}
+
+	select {
+	case n.events <- event:
+	default:
+		if n.metrics != nil {
+			n.metrics.AddCounter("notifications_dropped_total", "Notifications dropped because the delivery queue was full", 1, "event", event.Event)
+		}
+		n.logger.Warn().Str("event", event.Event).Msg("Notification queue full, dropping event")
+	}
+}
+
+// Close stops the worker after the delivery in progress, if any. Queued
+// events are discarded.
+func (n *notifier) Close() {
+	n.closeOnce.Do(func() { close(n.done) })
+	<-n.stopped
+}
+
+func (n *notifier) wanted(event *models.NotificationEvent) bool {
+	for _, webhook := range n.config.Webhooks {
+		if webhookWants(webhook, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookWants reports whether webhook receives event. Threshold events go
+// only to webhooks whose tickets_added_threshold the event reaches.
+func webhookWants(webhook common.WebhookConfig, event *models.NotificationEvent) bool {
+	if !webhook.Wants(event.Event) {
+		return false
+	}
+	if event.Event == models.EventTicketsAddedThreshold {
+		return webhook.TicketsAddedThreshold > 0 && event.TicketsAdded >= webhook.TicketsAddedThreshold
+	}
+	return true
+}
+
+func (n *notifier) run() {
+	defer close(n.stopped)
+	for {
+		select {
+		case <-n.done:
+			return
+		case event := <-n.events:
+			n.deliver(event)
+		}
+	}
+}
+
+// deliver posts event to each webhook that wants it, skipping paused webhooks
+func (n *notifier) deliver(event *models.NotificationEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error().Err(err).Str("event", event.Event).Msg("Failed to encode notification")
+		return
+	}
+
+	for i, webhook := range n.config.Webhooks {
+		if !webhookWants(webhook, event) {
+			continue
+		}
+
+		host := webhookHost(webhook.URL)
+		if time.Now().Before(n.pausedUntil[i]) {
+			if n.metrics != nil {
+				n.metrics.AddCounter("notifications_skipped_total", "Notifications not sent because the webhook is paused after repeated failures", 1, "event", event.Event)
+			}
+			n.logger.Debug().Str("event", event.Event).Str("webhook", host).Msg("Webhook paused, skipping notification")
+			continue
+		}
+
+		if err := n.post(webhook.URL, body); err != nil {
+			n.failures[i]++
+			if n.metrics != nil {
+				n.metrics.AddCounter("notifications_failed_total", "Notifications that could not be delivered after retries", 1, "event", event.Event)
+			}
+			n.logger.Warn().
+				Err(err).
+				Str("event", event.Event).
+				Str("webhook", host).
+				Int("consecutive_failures", n.failures[i]).
+				Msg("Failed to deliver notification")
+
+			if threshold := n.config.BreakerThreshold; threshold > 0 && n.failures[i] >= threshold {
+				cooldown := time.Duration(n.config.BreakerCooldownSeconds) * time.Second
+				n.pausedUntil[i] = time.Now().Add(cooldown)
+				n.failures[i] = 0
+				n.logger.Warn().
+					Str("webhook", host).
+					Str("cooldown", cooldown.String()).
+					Msg("Pausing webhook after repeated failures")
+			}
+			continue
+		}
+
+		n.failures[i] = 0
+		if n.metrics != nil {
+			n.metrics.AddCounter("notifications_sent_total", "Notifications delivered to webhooks", 1, "event", event.Event)
+		}
+		n.logger.Debug().Str("event", event.Event).Str("webhook", host).Msg("Notification delivered")
+	}
+}
+
+// post sends body to webhookURL, retrying network errors, 429 and 5xx responses
+// with doubling backoff. Closing the notifier abandons the retries.
+func (n *notifier) post(webhookURL string, body []byte) error {
+	backoff := time.Second
+	var err error
+	for attempt := 0; attempt <= n.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-n.done:
+				return fmt.Errorf("notifier closed: %w", err)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		if retry, err = n.postOnce(webhookURL, body); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// webhookHost returns the webhook's host for logging. Webhook paths often
+// hold secret tokens and are never logged.
+func webhookHost(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "invalid URL"
+	}
+	return u.Host
+}
+
+// postOnce makes one delivery attempt and reports whether a failure is worth
+// retrying
+func (n *notifier) postOnce(webhookURL string, body []byte) (bool, error) {
+	resp, err := n.client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The URL may carry a secret token, so only the cause is kept
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
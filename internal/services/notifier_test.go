@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+
+	"github.com/ternarybob/arbor"
+)
+
+// TestNotifierWithoutMetrics runs every delivery outcome (sent, failed,
+// skipped while paused and dropped from a full queue) with no metrics
+// registry, which must not panic
+func TestNotifierWithoutMetrics(t *testing.T) {
+	// The first webhook is the one under test. The second always accepts and
+	// reports each event, which shows the first has been handled.
+	var failing, blocking atomic.Bool
+	release := make(chan struct{})
+	received := make(chan string, notifierQueueSize*2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tested" {
+			if failing.Load() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if blocking.Load() {
+			<-release
+		}
+		var event models.NotificationEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- event.TransactionID
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := common.DefaultConfig().Notifications
+	config.Webhooks = []common.WebhookConfig{{URL: server.URL + "/tested"}, {URL: server.URL + "/witness"}}
+	config.MaxRetries = 0
+	config.BreakerThreshold = 1
+	notifier := NewNotifier(&config, arbor.NewLogger(), nil)
+	defer notifier.Close()
+
+	notify := func(id string) {
+		t.Helper()
+		notifier.Notify(&models.NotificationEvent{Event: models.EventRunCompleted, TransactionID: id})
+		select {
+		case got := <-received:
+			if got != id {
+				t.Fatalf("witness received %q, want %q", got, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s was not delivered", id)
+		}
+	}
+	notify("sent")
+	failing.Store(true)
+	notify("failed")
+	notify("skipped")
+
+	// Stall the worker on the witness, then overflow the queue
+	blocking.Store(true)
+	notifier.Notify(&models.NotificationEvent{Event: models.EventRunCompleted, TransactionID: "stalled"})
+	for i := 0; i <= notifierQueueSize+1; i++ {
+		notifier.Notify(&models.NotificationEvent{Event: models.EventRunCompleted, TransactionID: "queued"})
+	}
+	close(release)
+}
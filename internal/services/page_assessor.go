@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/interfaces"
 	"aktis-collector-jira/internal/models"
 
@@ -24,29 +26,46 @@ func NewPageAssessor(logger arbor.ILogger) interfaces.PageAssessor {
 }
 
 // AssessPage analyzes HTML and URL to determine page type without parsing full content
-func (pa *pageAssessor) AssessPage(htmlContent, url string) (*models.PageAssessment, error) {
-	assessment := &models.PageAssessment{
-		PageType:    "unknown",
-		Confidence:  "low",
-		Description: "Page type could not be determined",
-		Indicators:  []string{},
-		Collectable: false,
+func (pa *pageAssessor) AssessPage(ctx context.Context, htmlContent, url string) (*models.PageAssessment, error) {
+	if id := common.TransactionID(ctx); id != "" {
+		pa = &pageAssessor{logger: common.WithTransaction(pa.logger, id)}
 	}
 
 	// Parse HTML to check for indicators
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
 		pa.logger.Warn().Err(err).Msg("Failed to parse HTML for assessment")
-		return assessment, nil // Return assessment anyway, don't error
+		return unknownAssessment(), nil // Return assessment anyway, don't error
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	return pa.AssessDocument(ctx, doc, url)
+}
+
+// AssessDocument assesses an already parsed page, so a caller that also
+// parses the page can build the tree once. It stops with the context's error
+// once ctx is done.
+func (pa *pageAssessor) AssessDocument(ctx context.Context, doc *html.Node, url string) (*models.PageAssessment, error) {
+	if id := common.TransactionID(ctx); id != "" {
+		pa = &pageAssessor{logger: common.WithTransaction(pa.logger, id)}
+	}
+
+	assessment := unknownAssessment()
+
 	// Check URL patterns first
 	urlIndicators := pa.checkURLPatterns(url)
 	assessment.Indicators = append(assessment.Indicators, urlIndicators...)
 
 	// Check HTML structure
-	htmlIndicators := pa.checkHTMLStructure(doc)
-	assessment.Indicators = append(assessment.Indicators, htmlIndicators...)
+	if doc != nil {
+		htmlIndicators, err := pa.checkHTMLStructure(common.NewWalkCheck(ctx), doc)
+		if err != nil {
+			return nil, err
+		}
+		assessment.Indicators = append(assessment.Indicators, htmlIndicators...)
+	}
 
 	// Determine page type based on indicators
 	assessment.PageType = pa.determinePageType(url, assessment.Indicators)
@@ -64,6 +83,16 @@ func (pa *pageAssessor) AssessPage(htmlContent, url string) (*models.PageAssessm
 	return assessment, nil
 }
 
+func unknownAssessment() *models.PageAssessment {
+	return &models.PageAssessment{
+		PageType:    "unknown",
+		Confidence:  "low",
+		Description: "Page type could not be determined",
+		Indicators:  []string{},
+		Collectable: false,
+	}
+}
+
 // checkURLPatterns checks URL for known Jira patterns
 func (pa *pageAssessor) checkURLPatterns(url string) []string {
 	indicators := []string{}
@@ -106,8 +135,9 @@ func (pa *pageAssessor) checkURLPatterns(url string) []string {
 }
 
 // checkHTMLStructure looks for HTML patterns that indicate page type
-// Enhanced to detect modern Jira Cloud structures
-func (pa *pageAssessor) checkHTMLStructure(doc *html.Node) []string {
+// Enhanced to detect modern Jira Cloud structures. It returns walk's error
+// when the walk stopped early.
+func (pa *pageAssessor) checkHTMLStructure(walk *common.WalkCheck, doc *html.Node) ([]string, error) {
 	indicators := []string{}
 	issueKeyRegex := regexp.MustCompile(`\b([A-Z]+-\d+)\b`)
 
@@ -117,6 +147,9 @@ func (pa *pageAssessor) checkHTMLStructure(doc *html.Node) []string {
 
 	var traverse func(*html.Node, int)
 	traverse = func(n *html.Node, depth int) {
+		if walk.Stop() {
+			return
+		}
 		if n.Type == html.ElementNode {
 			// Check for project table structure
 			if n.Data == "table" {
@@ -200,6 +233,9 @@ func (pa *pageAssessor) checkHTMLStructure(doc *html.Node) []string {
 	}
 
 	traverse(doc, 0)
+	if err := walk.Err(); err != nil {
+		return nil, err
+	}
 
 	// Add indicators based on counts
 	if issueLinksCount >= 3 {
@@ -215,7 +251,7 @@ func (pa *pageAssessor) checkHTMLStructure(doc *html.Node) []string {
 		pa.logger.Debug().Int("count", projectLinksCount).Msg("Found multiple project links")
 	}
 
-	return indicators
+	return indicators, nil
 }
 
 // determinePageType determines the page type based on indicators
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ternarybob/arbor"
+	"golang.org/x/net/html"
+)
+
+// issueListPage builds an issue list page for project ABC with the given
+// number of rows
+func issueListPage(rows int) string {
+	var b strings.Builder
+	b.WriteString("<html><head><title>ABC issues - Jira</title></head><body><table id=\"issuetable\">\n")
+	for i := 1; i <= rows; i++ {
+		fmt.Fprintf(&b, "<tr data-issue-key=\"ABC-%d\"><td class=\"issuekey\"><a href=\"/browse/ABC-%d\">ABC-%d</a></td>"+
+			"<td data-testid=\"issue-table.summary\">Issue number %d</td><td class=\"status\">Open</td></tr>\n", i, i, i, i)
+	}
+	b.WriteString("</table></body></html>\n")
+	return b.String()
+}
+
+func BenchmarkAssessDocument(b *testing.B) {
+	const pageURL = "https://example.atlassian.net/projects/ABC/issues"
+	assessor := NewPageAssessor(arbor.NewLogger())
+	for _, size := range []struct {
+		name string
+		rows int
+	}{
+		{"small", 3},
+		{"medium", 200},
+		{"huge", 10000},
+	} {
+		b.Run(size.name, func(b *testing.B) {
+			page := issueListPage(size.rows)
+			doc, err := html.Parse(strings.NewReader(page))
+			if err != nil {
+				b.Fatalf("html.Parse: %v", err)
+			}
+			b.SetBytes(int64(len(page)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				assessment, err := assessor.AssessDocument(context.Background(), doc, pageURL)
+				if err != nil {
+					b.Fatalf("AssessDocument: %v", err)
+				}
+				if assessment.PageType != "issueList" {
+					b.Fatalf("page type = %s, want issueList", assessment.PageType)
+				}
+			}
+		})
+	}
+}
+
+func TestAssessDocumentMatchesAssessPage(t *testing.T) {
+	assessor := NewPageAssessor(arbor.NewLogger())
+	pages := []struct {
+		url  string
+		html string
+	}{
+		{"https://example.atlassian.net/projects/ABC/issues", issueListPage(5)},
+		{"https://example.atlassian.net/browse/ABC-7", `<html><body><h1 data-testid="issue.views.issue-base.foundation.summary.heading">Fix login</h1></body></html>`},
+		{"https://example.com/about", "<html><body><p>Nothing to see here</p></body></html>"},
+	}
+	for _, page := range pages {
+		fromString, err := assessor.AssessPage(context.Background(), page.html, page.url)
+		if err != nil {
+			t.Fatalf("AssessPage(%s): %v", page.url, err)
+		}
+		doc, err := html.Parse(strings.NewReader(page.html))
+		if err != nil {
+			t.Fatalf("html.Parse: %v", err)
+		}
+		fromDoc, err := assessor.AssessDocument(context.Background(), doc, page.url)
+		if err != nil {
+			t.Fatalf("AssessDocument(%s): %v", page.url, err)
+		}
+		if !reflect.DeepEqual(fromDoc, fromString) {
+			t.Errorf("%s: AssessDocument = %+v, AssessPage = %+v", page.url, fromDoc, fromString)
+		}
+	}
+}
@@ -1,16 +1,26 @@
 package services
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/interfaces"
 	"aktis-collector-jira/internal/models"
 
+	"github.com/ternarybob/arbor"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -19,14 +29,40 @@ const (
 	metadataBucket  = "metadata"
 	processedBucket = "processed"
 	projectsBucket  = "projects"
-	lastUpdateKey   = "last_update"
-	sendCountKey    = "send_count"
-	refreshCountKey = "refresh_count"
+	// assessmentsBucket holds the page assessment history keyed by sequence
+	assessmentsBucket = "assessments"
+	lastUpdateKey     = "last_update"
+	sendCountKey      = "send_count"
+	refreshCountKey   = "refresh_count"
+	saveProgressKey   = "save_progress"
+	jiraUpdatedKey    = "jira_updated"
+	// clearedAtKey records when tickets were cleared, alone for all tickets
+	// or as "<project>:cleared_at" for one project
+	clearedAtKey = "cleared_at"
+	// extensionKeyPrefix marks extension version statistics in the metadata bucket
+	extensionKeyPrefix = "extension:"
+	// selfCheckKey holds the time of the last self-check write probe
+	selfCheckKey = "selfcheck"
 )
 
+// ticketMetadataKeys are the per-project metadata entries that describe the
+// stored tickets, stored as "<project>:<key>" and removed with the tickets
+var ticketMetadataKeys = []string{lastUpdateKey, saveProgressKey, jiraUpdatedKey}
+
+// ErrDatabaseLocked is returned when another process holds the database lock
+var ErrDatabaseLocked = errors.New("database is locked by another process")
+
 type storage struct {
 	db     *bolt.DB
 	config *common.StorageConfig
+
+	// Storage limit state for Capacity and the automatic prune
+	capacityMu sync.Mutex
+	full       bool
+	onFull     func(*models.StorageCapacity)
+	pruning    bool
+	lastPrune  time.Time
+	pruneWG    sync.WaitGroup
 }
 
 func NewStorage(config *common.StorageConfig) (interfaces.Storage, error) {
@@ -38,6 +74,9 @@ func NewStorage(config *common.StorageConfig) (interfaces.Storage, error) {
 	db, err := bolt.Open(config.DatabasePath, 0600, &bolt.Options{
 		Timeout: 1 * time.Second,
 	})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseLocked, config.DatabasePath)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -55,6 +94,9 @@ func NewStorage(config *common.StorageConfig) (interfaces.Storage, error) {
 		if _, err := tx.CreateBucketIfNotExists([]byte(projectsBucket)); err != nil {
 			return err
 		}
+		if _, err := tx.CreateBucketIfNotExists([]byte(assessmentsBucket)); err != nil {
+			return err
+		}
 		return nil
 	})
 	if err != nil {
@@ -68,32 +110,278 @@ func NewStorage(config *common.StorageConfig) (interfaces.Storage, error) {
 	}, nil
 }
 
+// lockRetryInterval is how often OpenStorage retries a locked database
+const lockRetryInterval = 2 * time.Second
+
+// OpenStorage opens the database for the server, read-only when
+// storage.read_only is set. While another process holds the lock it retries
+// for storage.lock_wait_seconds, logging each attempt, and then returns a
+// storage CollectorError naming the file.
+func OpenStorage(config *common.StorageConfig, logger arbor.ILogger) (interfaces.Storage, error) {
+	deadline := time.Now().Add(time.Duration(config.LockWaitSeconds) * time.Second)
+	open := NewStorage
+	if config.ReadOnly {
+		open = NewReadOnlyStorage
+	}
+
+	for {
+		storage, err := open(config)
+		if !errors.Is(err, ErrDatabaseLocked) {
+			if err != nil {
+				return nil, databaseError(config.DatabasePath, err)
+			}
+			return storage, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, databaseError(config.DatabasePath, err)
+		}
+
+		logger.Warn().
+			Str("path", config.DatabasePath).
+			Dur("remaining", remaining.Round(time.Second)).
+			Msg("Database locked by another process, retrying...")
+		time.Sleep(min(lockRetryInterval, remaining))
+	}
+}
+
+// databaseError classifies a database failure as locked, unavailable or
+// corrupt, with a message naming the file
+func databaseError(path string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrDatabaseLocked), errors.Is(err, bolt.ErrTimeout):
+		return common.WrapError(err, common.ErrorTypeStorage, "database_locked",
+			fmt.Sprintf("Database %s is locked by another process; a running export or backup, or a second collector using the same database_path, is the likely cause", path))
+	case errors.Is(err, bolt.ErrDatabaseNotOpen), errors.Is(err, os.ErrNotExist), errors.Is(err, os.ErrPermission):
+		return common.WrapError(err, common.ErrorTypeStorage, "database_unavailable",
+			fmt.Sprintf("Database %s is not available", path))
+	default:
+		return common.WrapError(err, common.ErrorTypeStorage, "database_corrupt",
+			fmt.Sprintf("Database %s could not be read and may be corrupt; restore it from a backup", path))
+	}
+}
+
+// Ping checks that the database is open and its buckets can be read. Its
+// errors carry the database_locked, database_unavailable or database_corrupt
+// code.
+func (s *storage) Ping() error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, name := range []string{ticketsBucket, metadataBucket, projectsBucket} {
+			if tx.Bucket([]byte(name)) == nil {
+				return fmt.Errorf("bucket %s is missing", name)
+			}
+		}
+		return nil
+	})
+	return databaseError(s.config.DatabasePath, err)
+}
+
+// CheckWritable commits a small write to the metadata bucket, so a database
+// that can be read but not written is found before a collection fails
+func (s *storage) CheckWritable() error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(metadataBucket))
+		if bucket == nil {
+			return fmt.Errorf("bucket %s is missing", metadataBucket)
+		}
+		return bucket.Put([]byte(selfCheckKey), []byte(time.Now().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return fmt.Errorf("database %s is not writable: %w", s.config.DatabasePath, err)
+	}
+	return nil
+}
+
+// NewReadOnlyStorage opens an existing database without write access, for
+// commands that only read such as backups
+func NewReadOnlyStorage(config *common.StorageConfig) (interfaces.Storage, error) {
+	if _, err := os.Stat(config.DatabasePath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db, err := bolt.Open(config.DatabasePath, 0600, &bolt.Options{
+		Timeout:  1 * time.Second,
+		ReadOnly: true,
+	})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseLocked, config.DatabasePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &storage{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// Backup writes a consistent snapshot of the database to path, replacing any
+// existing file only once the copy is complete, and returns its size in bytes
+func (s *storage) Backup(path string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	var size int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		if err := s.checkBackupSpace(filepath.Dir(path), size); err != nil {
+			return err
+		}
+		return tx.CopyFile(tmpPath, 0600)
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return size, nil
+}
+
 func (s *storage) Close() error {
+	s.pruneWG.Wait()
 	if s.db != nil {
 		return s.db.Close()
 	}
 	return nil
 }
 
-func (s *storage) SaveTickets(projectKey string, tickets map[string]*models.TicketData) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+// SaveTickets stores tickets for a project and returns how many were not
+// stored before. A ticket that is already stored is merged with the incoming
+// copy rather than replaced (see models.MergeTicket). The merge runs in the
+// write transaction, so concurrent saves for a project cannot lose updates.
+// Updated only moves when the content hash changes, and a ticket that is
+// byte-for-byte unchanged is not rewritten.
+//
+// Tickets are written in key order, storage.save_batch_size per transaction,
+// so a large save does not hold the write lock throughout. Each committed
+// batch records its progress in the project's metadata, and the last batch
+// clears it and moves the project's last update. A save that fails part way
+// keeps the batches already committed and returns their added count with the
+// error. Repeating it with the same tickets resumes after the last committed
+// batch; the count it returns covers only the tickets saved by the repeat.
+func (s *storage) SaveTickets(projectKey string, tickets map[string]*models.TicketData) (int, error) {
+	if err := s.checkCapacity(); err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(tickets))
+	for key := range tickets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	progress := models.SaveProgress{Started: time.Now(), Total: len(keys), Fingerprint: saveFingerprint(tickets, keys)}
+	interrupted, err := s.loadSaveProgress(projectKey)
+	if err != nil {
+		return 0, err
+	}
+	if interrupted != nil && interrupted.Fingerprint == progress.Fingerprint && interrupted.Total == len(keys) &&
+		interrupted.Committed > 0 && interrupted.Committed < len(keys) && keys[interrupted.Committed-1] == interrupted.LastKey {
+		progress = *interrupted
+		keys = keys[progress.Committed:]
+	}
+
+	batchSize := s.config.SaveBatchSize
+	if batchSize <= 0 || batchSize > len(keys) {
+		batchSize = len(keys)
+	}
+
+	added := 0
+	for start := 0; ; start += batchSize {
+		end := min(start+batchSize, len(keys))
+		batchAdded, err := s.saveTicketBatch(projectKey, tickets, keys[start:end], &progress, end == len(keys))
+		if err != nil {
+			return added, err
+		}
+		added += batchAdded
+		if end == len(keys) {
+			return added, nil
+		}
+	}
+}
+
+// saveFingerprint returns a SHA-256 over the keys and content hashes of the
+// tickets being saved, computed before the save changes them
+func saveFingerprint(tickets map[string]*models.TicketData, keys []string) string {
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", key, common.TicketHash(tickets[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadSaveProgress returns the project's save progress record, or nil when no
+// batched save is running or interrupted. An unreadable record is ignored, so
+// the next save starts over.
+func (s *storage) loadSaveProgress(projectKey string) (*models.SaveProgress, error) {
+	var progress *models.SaveProgress
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(metadataBucket)).Get([]byte(fmt.Sprintf("%s:%s", projectKey, saveProgressKey)))
+		var stored models.SaveProgress
+		if data != nil && json.Unmarshal(data, &stored) == nil {
+			progress = &stored
+		}
+		return nil
+	})
+	return progress, err
+}
+
+// saveTicketBatch merges and writes the tickets for keys in one transaction.
+// The final batch moves the project's last update and clears the save
+// progress; earlier batches record it.
+func (s *storage) saveTicketBatch(projectKey string, tickets map[string]*models.TicketData, keys []string, progress *models.SaveProgress, final bool) (int, error) {
+	added := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		added = 0
 		bucket := tx.Bucket([]byte(ticketsBucket))
-		now := time.Now()
+		now := progress.Started
+		newestJiraUpdate := ""
 
-		for _, ticket := range tickets {
+		for _, ticketKey := range keys {
+			ticket := tickets[ticketKey]
 			key := []byte(fmt.Sprintf("%s:%s", projectKey, ticket.Key))
 			existing := bucket.Get(key)
 
+			var stored models.TicketData
 			if existing == nil {
 				ticket.Created = now.Format(time.RFC3339)
+				added++
+			} else {
+				if err := json.Unmarshal(existing, &stored); err != nil {
+					return fmt.Errorf("failed to unmarshal stored ticket %s: %w", ticket.Key, err)
+				}
+				ticket = models.MergeTicket(&stored, ticket)
 			}
 
-			ticket.Updated = now.Format(time.RFC3339)
+			newestJiraUpdate = max(newestJiraUpdate, ticket.JiraUpdated)
+
+			ticket.Hash = common.TicketHash(ticket)
+			ticket.HashVersion = common.TicketHashVersion
+			contentUnchanged := existing != nil && stored.HashVersion == ticket.HashVersion && stored.Hash == ticket.Hash
+			if contentUnchanged {
+				ticket.Updated = stored.Updated
+			} else {
+				ticket.Updated = now.Format(time.RFC3339)
+			}
 
 			data, err := json.Marshal(ticket)
 			if err != nil {
 				return fmt.Errorf("failed to marshal ticket %s: %w", ticket.Key, err)
 			}
+			if contentUnchanged && bytes.Equal(data, existing) {
+				continue
+			}
 
 			if err := bucket.Put(key, data); err != nil {
 				return fmt.Errorf("failed to save ticket %s: %w", ticket.Key, err)
@@ -101,10 +389,90 @@ func (s *storage) SaveTickets(projectKey string, tickets map[string]*models.Tick
 		}
 
 		metaBucket := tx.Bucket([]byte(metadataBucket))
+		if err := advanceJiraUpdated(metaBucket, projectKey, newestJiraUpdate); err != nil {
+			return err
+		}
+		progressKey := []byte(fmt.Sprintf("%s:%s", projectKey, saveProgressKey))
+		if !final {
+			batchProgress := *progress
+			batchProgress.Committed += len(keys)
+			batchProgress.LastKey = keys[len(keys)-1]
+			data, err := json.Marshal(batchProgress)
+			if err != nil {
+				return fmt.Errorf("failed to marshal save progress: %w", err)
+			}
+			return metaBucket.Put(progressKey, data)
+		}
+
+		if err := metaBucket.Delete(progressKey); err != nil {
+			return err
+		}
 		lastUpdateKey := []byte(fmt.Sprintf("%s:%s", projectKey, lastUpdateKey))
 		lastUpdateData, _ := now.MarshalBinary()
 		return metaBucket.Put(lastUpdateKey, lastUpdateData)
 	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to save tickets %d-%d of %d: %w", progress.Committed+1, progress.Committed+len(keys), progress.Total, err)
+	}
+	progress.Committed += len(keys)
+	if len(keys) > 0 {
+		progress.LastKey = keys[len(keys)-1]
+	}
+	return added, nil
+}
+
+// ImportTickets stores tickets exactly as given, keeping their created and
+// updated timestamps, and records the import as the project's last update
+func (s *storage) ImportTickets(projectKey string, tickets []*models.TicketData) error {
+	if err := s.checkCapacity(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ticketsBucket))
+
+		for _, ticket := range tickets {
+			data, err := json.Marshal(ticket)
+			if err != nil {
+				return fmt.Errorf("failed to marshal ticket %s: %w", ticket.Key, err)
+			}
+
+			key := []byte(fmt.Sprintf("%s:%s", projectKey, ticket.Key))
+			if err := bucket.Put(key, data); err != nil {
+				return fmt.Errorf("failed to import ticket %s: %w", ticket.Key, err)
+			}
+		}
+
+		metaBucket := tx.Bucket([]byte(metadataBucket))
+		newestJiraUpdate := ""
+		for _, ticket := range tickets {
+			newestJiraUpdate = max(newestJiraUpdate, ticket.JiraUpdated)
+		}
+		if err := advanceJiraUpdated(metaBucket, projectKey, newestJiraUpdate); err != nil {
+			return err
+		}
+		lastUpdateKey := []byte(fmt.Sprintf("%s:%s", projectKey, lastUpdateKey))
+		lastUpdateData, _ := time.Now().MarshalBinary()
+		return metaBucket.Put(lastUpdateKey, lastUpdateData)
+	})
+}
+
+// advanceJiraUpdated records newest as the project's newest Jira updated
+// time unless a later one is already stored. newest is an RFC 3339 UTC
+// string; empty and unparseable values are ignored.
+func advanceJiraUpdated(metaBucket *bolt.Bucket, projectKey, newest string) error {
+	updated, err := time.Parse(time.RFC3339, newest)
+	if err != nil {
+		return nil
+	}
+
+	key := []byte(fmt.Sprintf("%s:%s", projectKey, jiraUpdatedKey))
+	var stored time.Time
+	if data := metaBucket.Get(key); data != nil && stored.UnmarshalBinary(data) == nil && !updated.After(stored) {
+		return nil
+	}
+	data, _ := updated.MarshalBinary()
+	return metaBucket.Put(key, data)
 }
 
 func (s *storage) LoadTickets(projectKey string) (map[string]*models.TicketData, error) {
@@ -161,17 +529,105 @@ func (s *storage) ClearAllTickets() error {
 			return fmt.Errorf("failed to recreate tickets bucket: %w", err)
 		}
 
-		// Also clear metadata
-		if err := tx.DeleteBucket([]byte(metadataBucket)); err != nil {
-			return fmt.Errorf("failed to delete metadata bucket: %w", err)
+		// Remove the metadata describing the tickets. Extension statistics
+		// and per-project tombstones in the same bucket are kept.
+		metaBucket := tx.Bucket([]byte(metadataBucket))
+		var keys [][]byte
+		err := metaBucket.ForEach(func(k, _ []byte) error {
+			if _, key, ok := strings.Cut(string(k), ":"); ok && slices.Contains(ticketMetadataKeys, key) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-
-		if _, err := tx.CreateBucket([]byte(metadataBucket)); err != nil {
-			return fmt.Errorf("failed to recreate metadata bucket: %w", err)
+		for _, k := range keys {
+			if err := metaBucket.Delete(k); err != nil {
+				return fmt.Errorf("failed to delete metadata %s: %w", k, err)
+			}
 		}
 
+		return putClearedAt(metaBucket, clearedAtKey)
+	})
+}
+
+// putClearedAt writes a tombstone recording that tickets were cleared now
+func putClearedAt(metaBucket *bolt.Bucket, key string) error {
+	data, _ := time.Now().MarshalBinary()
+	return metaBucket.Put([]byte(key), data)
+}
+
+// ClearedAt returns when the project's tickets were last cleared, either for
+// the project alone or with all tickets, or nil when they were not cleared
+// within storage.tombstone_hours
+func (s *storage) ClearedAt(projectKey string) (*time.Time, error) {
+	window := s.config.TombstoneWindow()
+	if window <= 0 {
+		return nil, nil
+	}
+
+	var latest *time.Time
+	err := s.db.View(func(tx *bolt.Tx) error {
+		metaBucket := tx.Bucket([]byte(metadataBucket))
+		for _, key := range []string{clearedAtKey, fmt.Sprintf("%s:%s", projectKey, clearedAtKey)} {
+			data := metaBucket.Get([]byte(key))
+			if data == nil {
+				continue
+			}
+			var cleared time.Time
+			if err := cleared.UnmarshalBinary(data); err != nil {
+				continue
+			}
+			if time.Since(cleared) < window && (latest == nil || cleared.After(*latest)) {
+				latest = &cleared
+			}
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tombstones: %w", err)
+	}
+	return latest, nil
+}
+
+// ClearProjectTickets deletes all tickets stored for a project along with its
+// last update metadata and records a tombstone (see ClearedAt), returning the
+// number of tickets removed
+func (s *storage) ClearProjectTickets(projectKey string) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ticketsBucket))
+		prefix := []byte(fmt.Sprintf("%s:", projectKey))
+
+		// Collect keys first; deleting while iterating skips entries
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return fmt.Errorf("failed to delete ticket %s: %w", k, err)
+			}
+			removed++
+		}
+
+		metaBucket := tx.Bucket([]byte(metadataBucket))
+		for _, key := range ticketMetadataKeys {
+			if err := metaBucket.Delete([]byte(fmt.Sprintf("%s:%s", projectKey, key))); err != nil {
+				return err
+			}
+		}
+		return putClearedAt(metaBucket, fmt.Sprintf("%s:%s", projectKey, clearedAtKey))
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
 }
 
 func (s *storage) ClearAllProjects() error {
@@ -215,11 +671,143 @@ func (s *storage) GetLastUpdate(projectKey string) (string, error) {
 	return lastUpdate.Format("2006-01-02 15:04"), nil
 }
 
-func (s *storage) SaveProjects(projects []*models.ProjectData) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+// GetProjectFreshness reports, for every project with stored tickets, a
+// collection record or a project entry, the time since its last collection
+// and the age distribution of its tickets' updated timestamps. A project is
+// stale once storage.stale_after_hours has passed since it was collected.
+func (s *storage) GetProjectFreshness() ([]*models.ProjectFreshness, error) {
+	now := time.Now()
+	projects := make(map[string]*models.ProjectFreshness)
+	disabledProjects := make(map[string]bool)
+	project := func(key string) *models.ProjectFreshness {
+		if projects[key] == nil {
+			projects[key] = &models.ProjectFreshness{Key: key}
+		}
+		return projects[key]
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if err := tx.Bucket([]byte(projectsBucket)).ForEach(func(k, v []byte) error {
+			project(string(k))
+			var stored models.ProjectData
+			if json.Unmarshal(v, &stored) == nil && stored.Disabled {
+				disabledProjects[string(k)] = true
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket([]byte(ticketsBucket)).ForEach(func(k, v []byte) error {
+			projectKey, _, found := bytes.Cut(k, []byte(":"))
+			if !found {
+				return nil
+			}
+
+			// Only the fields needed here are decoded
+			var ticket struct {
+				Updated string `json:"updated"`
+				Source  string `json:"source"`
+			}
+			if err := json.Unmarshal(v, &ticket); err != nil || ticket.Source == models.SourceReference {
+				return nil
+			}
+
+			freshness := project(string(projectKey))
+			freshness.Tickets++
+			updated, err := time.Parse(time.RFC3339, ticket.Updated)
+			if err != nil {
+				freshness.UpdatedAges.Unknown++
+				return nil
+			}
+			freshness.UpdatedAges.Add(now.Sub(updated))
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		suffix := []byte(":" + lastUpdateKey)
+		progressSuffix := []byte(":" + saveProgressKey)
+		jiraUpdatedSuffix := []byte(":" + jiraUpdatedKey)
+		return tx.Bucket([]byte(metadataBucket)).ForEach(func(k, v []byte) error {
+			if bytes.HasSuffix(k, jiraUpdatedSuffix) {
+				var newest time.Time
+				if err := newest.UnmarshalBinary(v); err == nil {
+					project(string(bytes.TrimSuffix(k, jiraUpdatedSuffix))).NewestJiraUpdate = &newest
+				}
+				return nil
+			}
+			if bytes.HasSuffix(k, progressSuffix) {
+				var progress models.SaveProgress
+				if err := json.Unmarshal(v, &progress); err == nil {
+					project(string(bytes.TrimSuffix(k, progressSuffix))).IncompleteSave = &progress
+				}
+				return nil
+			}
+			if !bytes.HasSuffix(k, suffix) {
+				return nil
+			}
+			var lastUpdate time.Time
+			if err := lastUpdate.UnmarshalBinary(v); err != nil || lastUpdate.IsZero() {
+				return nil
+			}
+			project(string(bytes.TrimSuffix(k, suffix))).LastCollection = &lastUpdate
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project freshness: %w", err)
+	}
+
+	staleAfter := s.config.StaleAfter()
+	result := make([]*models.ProjectFreshness, 0, len(projects))
+	for _, freshness := range projects {
+		if freshness.LastCollection == nil {
+			freshness.Status = models.FreshnessNever
+		} else {
+			staleness := now.Sub(*freshness.LastCollection)
+			freshness.StalenessSeconds = staleness.Seconds()
+			freshness.Status = models.FreshnessFresh
+			if staleAfter > 0 && staleness > staleAfter {
+				freshness.Status = models.FreshnessStale
+			}
+		}
+		if disabledProjects[freshness.Key] {
+			freshness.Status = models.FreshnessDisabled
+		}
+		result = append(result, freshness)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	return result, nil
+}
+
+// SaveProjects stores projects, replacing any stored under the same key, and
+// returns how many were not stored before
+func (s *storage) SaveProjects(projects []*models.ProjectData) (int, error) {
+	if err := s.checkCapacity(); err != nil {
+		return 0, err
+	}
+
+	added := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(projectsBucket))
 
+		added = 0
 		for _, project := range projects {
+			existing := bucket.Get([]byte(project.Key))
+			if existing == nil {
+				added++
+			} else {
+				// A collected project keeps its stored disabled flag
+				var stored models.ProjectData
+				if json.Unmarshal(existing, &stored) == nil && stored.Disabled && !project.Disabled {
+					copied := *project
+					copied.Disabled = true
+					project = &copied
+				}
+			}
+
 			data, err := json.Marshal(project)
 			if err != nil {
 				return fmt.Errorf("failed to marshal project %s: %w", project.Key, err)
@@ -232,6 +820,45 @@ func (s *storage) SaveProjects(projects []*models.ProjectData) error {
 
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// SetProjectDisabled pauses or resumes collection for a project, creating a
+// bare project record when none is stored
+func (s *storage) SetProjectDisabled(projectKey string, disabled bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(projectsBucket))
+
+		project := models.ProjectData{Key: projectKey}
+		if existing := bucket.Get([]byte(projectKey)); existing != nil {
+			if err := json.Unmarshal(existing, &project); err != nil {
+				return fmt.Errorf("failed to unmarshal project %s: %w", projectKey, err)
+			}
+		}
+		project.Disabled = disabled
+
+		data, err := json.Marshal(project)
+		if err != nil {
+			return fmt.Errorf("failed to marshal project %s: %w", projectKey, err)
+		}
+		if err := bucket.Put([]byte(projectKey), data); err != nil {
+			return fmt.Errorf("failed to save project %s: %w", projectKey, err)
+		}
+		return nil
+	})
+}
+
+// CountStored returns the number of stored projects and tickets
+func (s *storage) CountStored() (projects, tickets int, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		projects = tx.Bucket([]byte(projectsBucket)).Stats().KeyN
+		tickets = tx.Bucket([]byte(ticketsBucket)).Stats().KeyN
+		return nil
+	})
+	return projects, tickets, err
 }
 
 func (s *storage) LoadProjects() ([]*models.ProjectData, error) {
@@ -254,3 +881,387 @@ func (s *storage) LoadProjects() ([]*models.ProjectData, error) {
 
 	return projects, err
 }
+
+// AppendAssessment adds a page assessment to the history, dropping the oldest
+// entries beyond the configured assessment_history. It does nothing when the
+// history is disabled.
+func (s *storage) AppendAssessment(record *models.AssessmentRecord) error {
+	limit := s.config.AssessmentHistory
+	if limit <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal assessment: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(assessmentsBucket))
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := bucket.Put(key, data); err != nil {
+			return fmt.Errorf("failed to save assessment: %w", err)
+		}
+
+		// Keys are sequential, so everything at or below seq-limit is beyond the cap
+		if seq <= uint64(limit) {
+			return nil
+		}
+		oldest := seq - uint64(limit)
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) <= oldest; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LoadAssessments returns the page assessment history, newest first,
+// filtered by page type and outcome
+func (s *storage) LoadAssessments(query models.AssessmentQuery) ([]*models.AssessmentRecord, error) {
+	records := make([]*models.AssessmentRecord, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(assessmentsBucket))
+		if bucket == nil {
+			return nil // database created before the history existed
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var record models.AssessmentRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				continue
+			}
+			if query.PageType != "" && record.PageType != query.PageType {
+				continue
+			}
+			if query.Outcome != "" && record.Outcome != query.Outcome {
+				continue
+			}
+			records = append(records, &record)
+			if query.Limit > 0 && len(records) >= query.Limit {
+				break
+			}
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+// SaveExtensionStats stores the statistics for one extension version in the
+// metadata bucket, replacing any earlier copy
+func (s *storage) SaveExtensionStats(stats *models.ExtensionVersionStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extension stats: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(metadataBucket))
+		if err := bucket.Put([]byte(extensionKeyPrefix+stats.Version), data); err != nil {
+			return fmt.Errorf("failed to save extension stats %s: %w", stats.Version, err)
+		}
+		return nil
+	})
+}
+
+// LoadExtensionStats returns the stored statistics for every extension version
+func (s *storage) LoadExtensionStats() ([]*models.ExtensionVersionStats, error) {
+	result := make([]*models.ExtensionVersionStats, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := []byte(extensionKeyPrefix)
+		c := tx.Bucket([]byte(metadataBucket)).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var stats models.ExtensionVersionStats
+			if err := json.Unmarshal(v, &stats); err != nil {
+				continue
+			}
+			result = append(result, &stats)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load extension stats: %w", err)
+	}
+	return result, nil
+}
+
+// GetTicket loads a single ticket from a project with one direct lookup. It
+// returns nil when the ticket is not stored.
+func (s *storage) GetTicket(projectKey, key string) (*models.TicketData, error) {
+	var ticket *models.TicketData
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(ticketsBucket)).Get([]byte(fmt.Sprintf("%s:%s", projectKey, key)))
+		if data == nil {
+			return nil
+		}
+		ticket = &models.TicketData{}
+		return json.Unmarshal(data, ticket)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ticket %s: %w", key, err)
+	}
+
+	return ticket, nil
+}
+
+// QueryTickets returns one page of tickets matching the query's filters,
+// sorted by the requested column
+func (s *storage) QueryTickets(query models.TicketQuery) (*models.TicketPage, error) {
+	if query.SortBy == "" && query.Status == "" {
+		return s.queryTicketsInStorageOrder(query)
+	}
+
+	var tickets []*models.TicketData
+	statuses := make(map[string]bool)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ticketsBucket))
+
+		var prefix []byte
+		if query.Project != "" {
+			prefix = []byte(fmt.Sprintf("%s:", query.Project))
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var ticket models.TicketData
+			if err := json.Unmarshal(v, &ticket); err != nil {
+				continue
+			}
+
+			if ticket.Source == models.SourceReference && !query.IncludeReferences {
+				continue
+			}
+			if ticket.Status != "" {
+				statuses[ticket.Status] = true
+			}
+			if query.Status != "" && !strings.EqualFold(ticket.Status, query.Status) {
+				continue
+			}
+
+			tickets = append(tickets, &ticket)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tickets: %w", err)
+	}
+
+	sortTickets(tickets, query.SortBy, query.Descending)
+
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = 25
+	}
+	totalPages := (len(tickets) + pageSize - 1) / pageSize
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	if totalPages > 0 && page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(tickets) {
+		start = len(tickets)
+	}
+	if end > len(tickets) {
+		end = len(tickets)
+	}
+
+	statusList := make([]string, 0, len(statuses))
+	for status := range statuses {
+		statusList = append(statusList, status)
+	}
+	sort.Strings(statusList)
+
+	return &models.TicketPage{
+		Tickets:    tickets[start:end],
+		Total:      len(tickets),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+		Statuses:   statusList,
+	}, nil
+}
+
+// scanBatchSize is how many stored tickets ScanTickets reads per transaction
+const scanBatchSize = 500
+
+// ScanTickets calls fn for every ticket matching the query's project, status
+// and reference filters, in storage key order. Sorting and paging are
+// ignored. Tickets are read in batches, each in its own read transaction, so
+// a slow fn such as a client download does not hold the database open.
+// Scanning stops at the first error from fn.
+func (s *storage) ScanTickets(query models.TicketQuery, fn func(*models.TicketData) error) error {
+	var prefix []byte
+	if query.Project != "" {
+		prefix = []byte(fmt.Sprintf("%s:", query.Project))
+	}
+
+	var after []byte
+	for {
+		batch := make([]*models.TicketData, 0, scanBatchSize)
+		more := false
+
+		err := s.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket([]byte(ticketsBucket)).Cursor()
+
+			k, v := c.Seek(prefix)
+			if after != nil {
+				if k, v = c.Seek(after); bytes.Equal(k, after) {
+					k, v = c.Next()
+				}
+			}
+
+			for scanned := 0; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				if scanned == scanBatchSize {
+					more = true
+					break
+				}
+				scanned++
+				// Keys are only valid inside the transaction
+				after = append(after[:0], k...)
+
+				var ticket models.TicketData
+				if err := json.Unmarshal(v, &ticket); err != nil {
+					continue
+				}
+				if ticket.Source == models.SourceReference && !query.IncludeReferences {
+					continue
+				}
+				if query.Status != "" && !strings.EqualFold(ticket.Status, query.Status) {
+					continue
+				}
+				batch = append(batch, &ticket)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan tickets: %w", err)
+		}
+
+		for _, ticket := range batch {
+			if err := fn(ticket); err != nil {
+				return err
+			}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// isReferenceJSON reports whether an encoded ticket is a reference. Only the
+// top-level source field is decoded, so the storage-order query can skip
+// references without decoding every ticket in full; a description or custom
+// field that happens to contain the same text does not count.
+func isReferenceJSON(v []byte) bool {
+	var ticket struct {
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal(v, &ticket); err != nil {
+		return false
+	}
+	return ticket.Source == models.SourceReference
+}
+
+// queryTicketsInStorageOrder pages through tickets in key order, decoding only
+// the tickets on the requested page so cost does not grow with database size
+func (s *storage) queryTicketsInStorageOrder(query models.TicketQuery) (*models.TicketPage, error) {
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = 25
+	}
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+
+	result := &models.TicketPage{
+		Tickets:  make([]*models.TicketData, 0, pageSize),
+		PageSize: pageSize,
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ticketsBucket))
+
+		var prefix []byte
+		if query.Project != "" {
+			prefix = []byte(fmt.Sprintf("%s:", query.Project))
+		}
+
+		start := (page - 1) * pageSize
+		c := bucket.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if !query.IncludeReferences && isReferenceJSON(v) {
+				continue
+			}
+			index := result.Total
+			result.Total++
+			if index < start || index >= start+pageSize {
+				continue
+			}
+
+			var ticket models.TicketData
+			if err := json.Unmarshal(v, &ticket); err != nil {
+				continue
+			}
+			result.Tickets = append(result.Tickets, &ticket)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tickets: %w", err)
+	}
+
+	result.TotalPages = (result.Total + pageSize - 1) / pageSize
+	result.Page = page
+	return result, nil
+}
+
+// sortTickets orders tickets by the given column. Issue keys sort naturally
+// so PROJ-9 comes before PROJ-10.
+func sortTickets(tickets []*models.TicketData, sortBy string, descending bool) {
+	less := func(a, b *models.TicketData) bool {
+		switch sortBy {
+		case "summary":
+			return strings.ToLower(a.Summary) < strings.ToLower(b.Summary)
+		case "status":
+			return strings.ToLower(a.Status) < strings.ToLower(b.Status)
+		case "priority":
+			return strings.ToLower(a.Priority) < strings.ToLower(b.Priority)
+		case "assignee":
+			return strings.ToLower(a.Assignee) < strings.ToLower(b.Assignee)
+		case "updated":
+			return a.Updated < b.Updated
+		default:
+			return common.CompareIssueKeys(a.Key, b.Key) < 0
+		}
+	}
+
+	sort.SliceStable(tickets, func(i, j int) bool {
+		if descending {
+			return less(tickets[j], tickets[i])
+		}
+		return less(tickets[i], tickets[j])
+	})
+}
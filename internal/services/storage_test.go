@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// numberedTickets returns tickets ABC-1 to ABC-n with summaries starting with
+// prefix
+func numberedTickets(project string, n int, prefix string) map[string]*models.TicketData {
+	tickets := make(map[string]*models.TicketData, n)
+	for i := 1; i <= n; i++ {
+		key := fmt.Sprintf("%s-%d", project, i)
+		tickets[key] = &models.TicketData{Key: key, ProjectID: project, Summary: fmt.Sprintf("%s %d", prefix, i), Status: "Open"}
+	}
+	return tickets
+}
+
+// putRawTicket writes data as the stored record of key, bypassing SaveTickets
+func putRawTicket(t *testing.T, s *storage, key string, data []byte) {
+	t.Helper()
+	project, _ := common.IssueKeyProject(key)
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(ticketsBucket))
+		if data == nil {
+			return bucket.Delete([]byte(project + ":" + key))
+		}
+		return bucket.Put([]byte(project+":"+key), data)
+	})
+	if err != nil {
+		t.Fatalf("writing %s: %v", key, err)
+	}
+}
+
+// editSummary changes the stored summary of key behind SaveTickets' back
+func editSummary(t *testing.T, s *storage, key, summary string) {
+	t.Helper()
+	project, _ := common.IssueKeyProject(key)
+	ticket, err := s.GetTicket(project, key)
+	if err != nil || ticket == nil {
+		t.Fatalf("GetTicket %s: %v, %v", key, ticket, err)
+	}
+	ticket.Summary = summary
+	data, _ := json.Marshal(ticket)
+	putRawTicket(t, s, key, data)
+}
+
+func TestSaveTicketsResumesInterruptedSave(t *testing.T) {
+	s := newTestStorage(t, func(config *common.StorageConfig) { config.SaveBatchSize = 3 }).(*storage)
+
+	// An unreadable ABC-5 fails the second batch, ABC-4 to ABC-6
+	putRawTicket(t, s, "ABC-5", []byte("{not json"))
+	added, err := s.SaveTickets("ABC", numberedTickets("ABC", 9, "Ticket"))
+	if err == nil || added != 3 {
+		t.Fatalf("SaveTickets over a corrupt record = %d, %v; want 3 and an error", added, err)
+	}
+	progress, _ := s.loadSaveProgress("ABC")
+	if progress == nil || progress.Committed != 3 || progress.Total != 9 || progress.LastKey != "ABC-3" {
+		t.Fatalf("save progress = %+v, want 3 of 9 up to ABC-3", progress)
+	}
+	if lastUpdate, _ := s.GetLastUpdate("ABC"); lastUpdate != "" {
+		t.Errorf("last update = %q after an interrupted save", lastUpdate)
+	}
+
+	// Repeating the save skips the committed batch: the edit to ABC-1 stays
+	putRawTicket(t, s, "ABC-5", nil)
+	editSummary(t, s, "ABC-1", "Edited")
+	added, err = s.SaveTickets("ABC", numberedTickets("ABC", 9, "Ticket"))
+	if err != nil || added != 6 {
+		t.Fatalf("resumed SaveTickets = %d, %v; want 6", added, err)
+	}
+	tickets, _ := s.LoadTickets("ABC")
+	if len(tickets) != 9 || tickets["ABC-1"].Summary != "Edited" || tickets["ABC-9"].Summary != "Ticket 9" {
+		t.Errorf("after resuming: %d tickets, ABC-1 %+v", len(tickets), tickets["ABC-1"])
+	}
+	if progress, _ := s.loadSaveProgress("ABC"); progress != nil {
+		t.Errorf("save progress left behind: %+v", progress)
+	}
+	if lastUpdate, _ := s.GetLastUpdate("ABC"); lastUpdate == "" {
+		t.Error("last update not recorded by the final batch")
+	}
+}
+
+func TestSaveTicketsRestartsChangedSave(t *testing.T) {
+	s := newTestStorage(t, func(config *common.StorageConfig) { config.SaveBatchSize = 3 }).(*storage)
+	putRawTicket(t, s, "ABC-5", []byte("{not json"))
+	if _, err := s.SaveTickets("ABC", numberedTickets("ABC", 9, "Ticket")); err == nil {
+		t.Fatal("SaveTickets over a corrupt record succeeded")
+	}
+	putRawTicket(t, s, "ABC-5", nil)
+
+	// Different content is a different save, so it starts from the first key
+	if _, err := s.SaveTickets("ABC", numberedTickets("ABC", 9, "Renamed")); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	if ticket, _ := s.GetTicket("ABC", "ABC-1"); ticket == nil || ticket.Summary != "Renamed 1" {
+		t.Errorf("ABC-1 = %+v, want it rewritten", ticket)
+	}
+}
+
+// BenchmarkSaveTicketsDuringLargeSave times small receiver-sized saves while
+// another goroutine keeps saving a 20000 ticket project, with the whole save
+// in one transaction and in batches. The p95 metric is the latency a receiver
+// request sees behind the large save.
+func BenchmarkSaveTicketsDuringLargeSave(b *testing.B) {
+	const largeTickets = 20000
+	for _, batchSize := range []int{0, 1000} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			s := newTestStorage(b, func(config *common.StorageConfig) { config.SaveBatchSize = batchSize })
+			large := numberedTickets("BIG", largeTickets, "Large")
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for round := 0; ; round++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					// Change every summary so each round rewrites all tickets
+					for key, ticket := range large {
+						ticket.Summary = fmt.Sprintf("Large %s round %d", key, round)
+					}
+					if _, err := s.SaveTickets("BIG", large); err != nil {
+						b.Errorf("large SaveTickets: %v", err)
+						return
+					}
+				}
+			}()
+
+			var latencies []time.Duration
+			for i := 0; b.Loop(); i++ {
+				small := numberedTickets("ABC", 5, fmt.Sprintf("Small %d", i))
+				start := time.Now()
+				if _, err := s.SaveTickets("ABC", small); err != nil {
+					b.Fatalf("small SaveTickets: %v", err)
+				}
+				latencies = append(latencies, time.Since(start))
+			}
+			close(stop)
+			wg.Wait()
+
+			slices.Sort(latencies)
+			b.ReportMetric(float64(latencies[len(latencies)*95/100].Microseconds()), "p95-µs")
+			b.ReportMetric(float64(latencies[len(latencies)-1].Microseconds()), "max-µs")
+		})
+	}
+}
+
+func TestQueryTicketsSkipsOnlyReferenceSource(t *testing.T) {
+	s := newTestStorage(t, nil)
+
+	tickets := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Summary: "Collected", Description: `Logged as {"source":"reference"}`},
+		"ABC-2": {Key: "ABC-2", ProjectID: "ABC", Summary: "Custom field", CustomFields: map[string]interface{}{"payload": map[string]interface{}{"source": "reference"}}},
+		"ABC-3": {Key: "ABC-3", ProjectID: "ABC", Source: models.SourceReference},
+	}
+	if _, err := s.SaveTickets("ABC", tickets); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+
+	page, err := s.QueryTickets(models.TicketQuery{Project: "ABC", PageSize: 10})
+	if err != nil {
+		t.Fatalf("QueryTickets: %v", err)
+	}
+	var keys []string
+	for _, ticket := range page.Tickets {
+		keys = append(keys, ticket.Key)
+	}
+	if !slices.Equal(keys, []string{"ABC-1", "ABC-2"}) || page.Total != 2 {
+		t.Errorf("keys = %v, total = %d; want ABC-1 and ABC-2 without the reference", keys, page.Total)
+	}
+
+	page, err = s.QueryTickets(models.TicketQuery{Project: "ABC", PageSize: 10, IncludeReferences: true})
+	if err != nil || page.Total != 3 {
+		t.Errorf("with references: total = %v, err = %v; want 3", page, err)
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+)
+
+// importBatchSize is the number of tickets written per import transaction
+const importBatchSize = 500
+
+// TransferRecord is one line of an NDJSON database export. Exactly one of
+// Project or Ticket is set.
+type TransferRecord struct {
+	Project *models.ProjectData `json:"project,omitempty"`
+	Ticket  *models.TicketData  `json:"ticket,omitempty"`
+}
+
+// TransferCounts summarises an export or import
+type TransferCounts struct {
+	Projects int
+	Tickets  map[string]int // by project key
+	// Tombstoned counts imported tickets skipped because their project was
+	// cleared after the ticket was last updated
+	Tombstoned int
+}
+
+// TotalTickets returns the number of tickets across all projects
+func (c *TransferCounts) TotalTickets() int {
+	total := 0
+	for _, count := range c.Tickets {
+		total += count
+	}
+	return total
+}
+
+// ProjectKeys returns the project keys with tickets, sorted
+func (c *TransferCounts) ProjectKeys() []string {
+	keys := make([]string, 0, len(c.Tickets))
+	for key := range c.Tickets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportData writes all projects followed by all tickets as NDJSON records
+func ExportData(storage interfaces.Storage, w io.Writer) (*TransferCounts, error) {
+	counts := &TransferCounts{Tickets: make(map[string]int)}
+	encoder := json.NewEncoder(w)
+
+	projects, err := storage.LoadProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	for _, project := range projects {
+		if err := encoder.Encode(TransferRecord{Project: project}); err != nil {
+			return nil, fmt.Errorf("failed to write project %s: %w", project.Key, err)
+		}
+		counts.Projects++
+	}
+
+	tickets, err := storage.LoadAllTickets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tickets: %w", err)
+	}
+
+	keys := make([]string, 0, len(tickets))
+	for key := range tickets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ticket := tickets[key]
+		if err := encoder.Encode(TransferRecord{Ticket: ticket}); err != nil {
+			return nil, fmt.Errorf("failed to write ticket %s: %w", ticket.Key, err)
+		}
+		counts.Tickets[ticketProjectKey(ticket.Key)]++
+	}
+
+	return counts, nil
+}
+
+// ImportData reads NDJSON records written by ExportData and stores them,
+// replacing existing entries with the same keys. Unless force is set, tickets
+// of a project cleared after their last update are skipped (see
+// Storage.ClearedAt).
+func ImportData(storage interfaces.Storage, r io.Reader, force bool) (*TransferCounts, error) {
+	counts := &TransferCounts{Tickets: make(map[string]int)}
+	pending := make(map[string][]*models.TicketData)
+	clearedAt := make(map[string]*time.Time)
+
+	flush := func(projectKey string) error {
+		if err := storage.ImportTickets(projectKey, pending[projectKey]); err != nil {
+			return err
+		}
+		counts.Tickets[projectKey] += len(pending[projectKey])
+		delete(pending, projectKey)
+		return nil
+	}
+
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var record TransferRecord
+		if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return counts, fmt.Errorf("invalid record %d: %w", line, err)
+		}
+
+		switch {
+		case record.Project != nil && record.Project.Key != "":
+			if _, err := storage.SaveProjects([]*models.ProjectData{record.Project}); err != nil {
+				return counts, fmt.Errorf("failed to import project %s: %w", record.Project.Key, err)
+			}
+			counts.Projects++
+		case record.Ticket != nil && record.Ticket.Key != "":
+			projectKey := ticketProjectKey(record.Ticket.Key)
+			if !force {
+				cleared, ok := clearedAt[projectKey]
+				if !ok {
+					var err error
+					if cleared, err = storage.ClearedAt(projectKey); err != nil {
+						return counts, err
+					}
+					clearedAt[projectKey] = cleared
+				}
+				if updated, err := time.Parse(time.RFC3339, record.Ticket.Updated); cleared != nil && (err != nil || cleared.After(updated)) {
+					counts.Tombstoned++
+					continue
+				}
+			}
+			pending[projectKey] = append(pending[projectKey], record.Ticket)
+			if len(pending[projectKey]) >= importBatchSize {
+				if err := flush(projectKey); err != nil {
+					return counts, err
+				}
+			}
+		default:
+			return counts, fmt.Errorf("invalid record %d: expected a project or ticket", line)
+		}
+	}
+
+	for projectKey := range pending {
+		if err := flush(projectKey); err != nil {
+			return counts, err
+		}
+	}
+
+	return counts, nil
+}
+
+// ticketProjectKey returns the project prefix of an issue key, e.g. PROJ for PROJ-123
+func ticketProjectKey(issueKey string) string {
+	projectKey, _, _ := strings.Cut(issueKey, "-")
+	return strings.ToUpper(projectKey)
+}
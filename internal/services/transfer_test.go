@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/models"
+)
+
+// newTestStorage opens a database in a temporary directory with the default
+// storage settings, adjusted by configure when it is not nil
+func newTestStorage(t testing.TB, configure func(*common.StorageConfig)) interfaces.Storage {
+	t.Helper()
+	config := common.DefaultConfig().Storage
+	config.DatabasePath = filepath.Join(t.TempDir(), "tickets.db")
+	if configure != nil {
+		configure(&config)
+	}
+	storage, err := NewStorage(&config)
+	if err != nil {
+		t.Fatalf("NewStorage: %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+// exportedTickets stores two ABC tickets updated an hour ago and returns
+// their export
+func exportedTickets(t *testing.T, storage interfaces.Storage) []byte {
+	t.Helper()
+	updated := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tickets := map[string]*models.TicketData{
+		"ABC-1": {Key: "ABC-1", ProjectID: "ABC", Summary: "First", Updated: updated},
+		"ABC-2": {Key: "ABC-2", ProjectID: "ABC", Summary: "Second", Updated: updated},
+	}
+	if _, err := storage.SaveTickets("ABC", tickets); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	var export bytes.Buffer
+	if _, err := ExportData(storage, &export); err != nil {
+		t.Fatalf("ExportData: %v", err)
+	}
+	return export.Bytes()
+}
+
+func TestImportDataSkipsClearedTickets(t *testing.T) {
+	storage := newTestStorage(t, nil)
+	export := exportedTickets(t, storage)
+	if _, err := storage.ClearProjectTickets("ABC"); err != nil {
+		t.Fatalf("ClearProjectTickets: %v", err)
+	}
+
+	counts, err := ImportData(storage, bytes.NewReader(export), false)
+	if err != nil {
+		t.Fatalf("ImportData: %v", err)
+	}
+	if counts.Tombstoned != 2 || counts.TotalTickets() != 0 {
+		t.Errorf("tombstoned %d, imported %d; want 2 and 0", counts.Tombstoned, counts.TotalTickets())
+	}
+	if ticket, _ := storage.GetTicket("ABC", "ABC-1"); ticket != nil {
+		t.Errorf("cleared ticket was resurrected: %+v", ticket)
+	}
+
+	// -force restores them regardless of the clear
+	counts, err = ImportData(storage, bytes.NewReader(export), true)
+	if err != nil {
+		t.Fatalf("ImportData with force: %v", err)
+	}
+	if counts.Tombstoned != 0 || counts.Tickets["ABC"] != 2 {
+		t.Errorf("with force: tombstoned %d, imported %v; want 0 and 2", counts.Tombstoned, counts.Tickets)
+	}
+	if ticket, err := storage.GetTicket("ABC", "ABC-1"); err != nil || ticket == nil || ticket.Summary != "First" {
+		t.Errorf("GetTicket after forced import = %+v, %v", ticket, err)
+	}
+}
+
+func TestImportDataTombstonesDisabled(t *testing.T) {
+	storage := newTestStorage(t, func(config *common.StorageConfig) { config.TombstoneHours = 0 })
+	export := exportedTickets(t, storage)
+	if _, err := storage.ClearProjectTickets("ABC"); err != nil {
+		t.Fatalf("ClearProjectTickets: %v", err)
+	}
+
+	counts, err := ImportData(storage, bytes.NewReader(export), false)
+	if err != nil {
+		t.Fatalf("ImportData: %v", err)
+	}
+	if counts.Tombstoned != 0 || counts.Tickets["ABC"] != 2 {
+		t.Errorf("tombstoned %d, imported %v; want 0 and 2", counts.Tombstoned, counts.Tickets)
+	}
+}
@@ -2,16 +2,24 @@ package services
 
 import (
 	"context"
+	"expvar"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"aktis-collector-jira/internal/common"
 	"aktis-collector-jira/internal/handlers"
 	"aktis-collector-jira/internal/interfaces"
+	"aktis-collector-jira/internal/metrics"
 	"aktis-collector-jira/internal/middleware"
+	"aktis-collector-jira/internal/models"
 
 	"github.com/ternarybob/arbor"
 )
@@ -21,12 +29,17 @@ type webServer struct {
 	config      *common.Config
 	storage     interfaces.Storage
 	server      *http.Server
+	redirect    *http.Server
 	logger      arbor.ILogger
 	apiHandlers *handlers.APIHandlers
 	uiHandlers  *handlers.UIHandlers
 	wsHub       *handlers.WebSocketHub
+	metrics     *metrics.Registry
+	notifier    interfaces.Notifier
 	running     bool
 	startTime   time.Time
+	inFlight    sync.WaitGroup
+	active      atomic.Int64
 }
 
 // NewWebServer creates a new web server instance
@@ -34,13 +47,26 @@ func NewWebServer(cfg *common.Config, storage interfaces.Storage, logger arbor.I
 	mux := http.NewServeMux()
 
 	// Create page assessor service
-	assessor := NewPageAssessor(logger)
+	assessor := NewPageAssessor(common.WithComponent(logger, "parser"))
 
 	// Create WebSocket hub first (needed by API handlers)
-	wsHub := handlers.NewWebSocketHub(logger)
+	wsHub := handlers.NewWebSocketHub(cfg, common.WithComponent(logger, "websocket"))
 
-	// Create API handlers with assessor and WebSocket hub
-	apiHandlers := handlers.NewAPIHandlers(cfg, storage, logger, assessor, wsHub)
+	// Create API handlers with assessor, WebSocket hub, metrics registry and
+	// webhook notifier
+	registry := metrics.NewRegistry()
+	notifier := NewNotifier(&cfg.Notifications, common.WithComponent(logger, "notifier"), registry)
+	apiHandlers := handlers.NewAPIHandlers(cfg, storage, logger, assessor, wsHub, registry, notifier)
+	apiHandlers.Errors().OnRecord(wsHub.SendError)
+	storage.OnStorageFull(func(capacity *models.StorageCapacity) {
+		notifier.Notify(&models.NotificationEvent{
+			Event:     models.EventStorageFull,
+			Timestamp: time.Now(),
+			Collector: cfg.Collector.Name,
+			Source:    "storage",
+			Error:     capacity.Reason,
+		})
+	})
 
 	// Find pages directory - check both relative to working dir and binary location
 	pagesDir := "pages"
@@ -57,6 +83,8 @@ func NewWebServer(cfg *common.Config, storage interfaces.Storage, logger arbor.I
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to initialize UI handlers, only API endpoints will be available")
 	}
+	apiHandlers.SetUIStatus(pagesDir, uiHandlers != nil)
+	apiHandlers.SetIssueFetcher(NewJiraIssueFetcher(&cfg.Jira))
 
 	ws := &webServer{
 		config:      cfg,
@@ -65,34 +93,129 @@ func NewWebServer(cfg *common.Config, storage interfaces.Storage, logger arbor.I
 		apiHandlers: apiHandlers,
 		uiHandlers:  uiHandlers,
 		wsHub:       wsHub,
+		metrics:     registry,
+		notifier:    notifier,
+		// Timeouts bound how long a stalled client can hold a connection. The
+		// WebSocket upgrader clears these deadlines on the hijacked connection,
+		// so /ws clients are not cut off by WriteTimeout; the hub applies its
+		// own per-message write deadline instead.
 		server: &http.Server{
-			Addr:    fmt.Sprintf(":%d", cfg.Collector.Port),
-			Handler: mux,
+			Addr:              cfg.ListenAddress(),
+			ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeoutSeconds) * time.Second,
+			ReadTimeout:       time.Duration(cfg.Server.ReadTimeoutSeconds) * time.Second,
+			WriteTimeout:      time.Duration(cfg.Server.WriteTimeoutSeconds) * time.Second,
+			IdleTimeout:       time.Duration(cfg.Server.IdleTimeoutSeconds) * time.Second,
+			MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 		},
 	}
 
 	// Create middleware chain
 	logMiddleware := middleware.Logging(logger)
 	corsMiddleware := middleware.CORS
+	authMiddleware := middleware.APIKey(&cfg.Collector)
+	uiAuthMiddleware := middleware.BasicAuth(&cfg.Collector)
+	readOnlyMiddleware := middleware.ReadOnly(&cfg.Storage)
 
 	// Register API endpoints with middleware
 	mux.HandleFunc("/health", logMiddleware(corsMiddleware(apiHandlers.HealthHandler)))
 	mux.HandleFunc("/version", logMiddleware(corsMiddleware(apiHandlers.VersionHandler)))
+	mux.HandleFunc("/extension/download", logMiddleware(corsMiddleware(apiHandlers.ExtensionDownloadHandler)))
+	mux.HandleFunc("/extension/upload", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.ExtensionUploadHandler))))
 	mux.HandleFunc("/status", logMiddleware(corsMiddleware(apiHandlers.StatusHandler)))
 	mux.HandleFunc("/projects", logMiddleware(corsMiddleware(apiHandlers.ProjectsHandler)))
-	mux.HandleFunc("/database", logMiddleware(corsMiddleware(apiHandlers.DatabaseHandler)))
+	mux.HandleFunc("POST /projects/{key}/enable", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.ProjectEnableHandler)))))
+	mux.HandleFunc("POST /projects/{key}/disable", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.ProjectDisableHandler)))))
+	mux.HandleFunc("/projects/{key}/tickets", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.ProjectTicketsHandler)))))
+	mux.HandleFunc("/tickets", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.TicketsHandler))))
+	mux.HandleFunc("/tickets/{key}", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.TicketHandler))))
+	mux.HandleFunc("/database", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.DatabaseHandler)))))
 	mux.HandleFunc("/config", logMiddleware(corsMiddleware(apiHandlers.ConfigHandler)))
-	mux.HandleFunc("/assess", logMiddleware(corsMiddleware(apiHandlers.AssessHandler)))
-	mux.HandleFunc("/receiver", logMiddleware(corsMiddleware(apiHandlers.ReceiverHandler)))
+	mux.HandleFunc("PUT /config", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.UpdateConfigHandler))))
+	mux.HandleFunc("/stats", logMiddleware(corsMiddleware(apiHandlers.StatsHandler)))
+	mux.HandleFunc("/aggregate", logMiddleware(corsMiddleware(apiHandlers.AggregateHandler)))
+	mux.HandleFunc("/errors", logMiddleware(corsMiddleware(apiHandlers.ErrorsHandler)))
+	mux.HandleFunc("DELETE /errors", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.ErrorsHandler))))
+	mux.HandleFunc("/extensions", logMiddleware(corsMiddleware(apiHandlers.ExtensionsHandler)))
+	mux.HandleFunc("/selfcheck", logMiddleware(corsMiddleware(apiHandlers.SelfCheckHandler)))
+	mux.HandleFunc("POST /selfcheck", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.SelfCheckHandler))))
+	mux.HandleFunc("/assessments", logMiddleware(corsMiddleware(apiHandlers.AssessmentsHandler)))
+	mux.HandleFunc("/reprocess", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.ReprocessHandler)))))
+	mux.HandleFunc("/backfill", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.BackfillHandler)))))
+	mux.HandleFunc("/logs", uiAuthMiddleware(corsMiddleware(authMiddleware(apiHandlers.LogsHandler))))
+	mux.HandleFunc("/export", logMiddleware(corsMiddleware(uiAuthMiddleware(authMiddleware(apiHandlers.ExportHandler)))))
+	mux.HandleFunc("/assess", logMiddleware(corsMiddleware(authMiddleware(apiHandlers.AssessHandler))))
+	mux.HandleFunc("/receiver", logMiddleware(corsMiddleware(authMiddleware(readOnlyMiddleware(apiHandlers.ReceiverHandler)))))
 
 	// Register WebSocket endpoint
 	mux.HandleFunc("/ws", corsMiddleware(wsHub.WebSocketHandler))
+	mux.HandleFunc("/ws/stats", logMiddleware(corsMiddleware(wsHub.StatsHandler)))
+
+	// Register Prometheus metrics endpoint
+	mux.HandleFunc("/metrics", corsMiddleware(ws.metricsHandler))
+
+	// Register profiling endpoints in development or when explicitly enabled
+	if cfg.IsDevelopment() || cfg.Collector.EnablePprof {
+		mux.HandleFunc("/debug/pprof/", authMiddleware(pprof.Index))
+		mux.HandleFunc("/debug/pprof/cmdline", authMiddleware(pprof.Cmdline))
+		mux.HandleFunc("/debug/pprof/profile", authMiddleware(pprof.Profile))
+		mux.HandleFunc("/debug/pprof/symbol", authMiddleware(pprof.Symbol))
+		mux.HandleFunc("/debug/pprof/trace", authMiddleware(pprof.Trace))
+		mux.HandleFunc("/debug/vars", authMiddleware(expvar.Handler().ServeHTTP))
+		logger.Info().Msg("Profiling endpoints enabled at /debug/pprof and /debug/vars")
+	}
+
+	// Register static assets (dashboard CSS/JS and the extension bundle)
+	staticDir := cfg.Collector.StaticDir
+	if staticDir == "" {
+		staticDir = filepath.Join(pagesDir, "static")
+	}
+	mux.Handle("/static/", handlers.StaticHandler("/static/", staticDir, cfg.IsDevelopment()))
 
 	// Register UI endpoints if available
 	if uiHandlers != nil {
-		mux.HandleFunc("/", logMiddleware(uiHandlers.IndexHandler))
-		mux.HandleFunc("/database/data", logMiddleware(uiHandlers.BufferDataHandler))
+		mux.HandleFunc("/", logMiddleware(uiAuthMiddleware(uiHandlers.IndexHandler)))
+		mux.HandleFunc("/database/data", logMiddleware(uiAuthMiddleware(readOnlyMiddleware(uiHandlers.BufferDataHandler))))
+		mux.HandleFunc("/ui/tickets", logMiddleware(uiAuthMiddleware(uiHandlers.TicketsHandler)))
+		mux.HandleFunc("/ui/tickets/{key}", logMiddleware(uiAuthMiddleware(uiHandlers.TicketPageHandler)))
+		mux.HandleFunc("/ui/tickets/{key}/raw", logMiddleware(uiAuthMiddleware(uiHandlers.TicketRawHandler)))
+		mux.HandleFunc("/ui/settings", logMiddleware(uiAuthMiddleware(uiHandlers.SettingsPageHandler)))
+		mux.HandleFunc("/ui/logs", logMiddleware(uiAuthMiddleware(uiHandlers.LogsPageHandler)))
+		mux.HandleFunc("/ui/dashboard", logMiddleware(uiAuthMiddleware(uiHandlers.DashboardHandler)))
+		mux.HandleFunc("/ui/stats", logMiddleware(uiAuthMiddleware(uiHandlers.StatsHandler)))
+		mux.HandleFunc("/ui/projects", logMiddleware(uiAuthMiddleware(uiHandlers.ProjectsHandler)))
+		mux.HandleFunc("/ui/projects/{key}", logMiddleware(uiAuthMiddleware(uiHandlers.ProjectPageHandler)))
+		mux.HandleFunc("/ui/projects/{key}/tickets", logMiddleware(uiAuthMiddleware(uiHandlers.ProjectTicketsHandler)))
+	}
+
+	// Unmatched routes get a JSON 404, and paths registered only for other
+	// methods a JSON 405, rather than the mux's plain-text defaults. The UI's
+	// "/" matches every path, so it only counts as a match for "/" itself.
+	routes := func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern == "" || (pattern == "/" && r.URL.Path != "/") {
+			if allowed := allowedMethods(mux, r); len(allowed) > 0 {
+				handlers.MethodNotAllowed(w, r, allowed...)
+				return
+			}
+			handlers.NotFound(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}
+
+	// Label request metrics with the registered pattern, not the raw path
+	route := func(r *http.Request) string {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			return pattern
+		}
+		return "unmatched"
 	}
+	metricsMiddleware := middleware.Metrics(ws.metrics, route)
+
+	// Track every request so Stop can drain them before storage closes
+	ws.server.Handler = ws.trackRequests(middleware.RequestID(metricsMiddleware(routes)))
+
+	// Check the setup once at startup; POST /selfcheck runs it again
+	apiHandlers.RunSelfCheck()
 
 	return ws, nil
 }
@@ -102,27 +225,159 @@ func (ws *webServer) Start(ctx context.Context) error {
 	ws.running = true
 	ws.startTime = time.Now()
 
+	tlsConfig := ws.config.Collector.TLS
+
+	// Listen before serving so an unavailable port is reported to the caller
+	listener, err := net.Listen("tcp", ws.server.Addr)
+	if err != nil {
+		ws.running = false
+		return fmt.Errorf("failed to listen on %s: %w", ws.server.Addr, err)
+	}
+
 	go func() {
-		ws.logger.Info().Int("port", ws.config.Collector.Port).Msg("Starting web server")
-		if err := ws.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		ws.logger.Info().
+			Str("address", ws.server.Addr).
+			Str("url", ws.config.ServerURL()).
+			Msg("Starting web server")
+
+		var err error
+		if tlsConfig.Enabled() {
+			err = ws.server.ServeTLS(listener, tlsConfig.CertFile, tlsConfig.KeyFile)
+		} else {
+			err = ws.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			ws.logger.Error().Err(err).Msg("Web server error")
 		}
 	}()
+
+	if tlsConfig.Enabled() && tlsConfig.RedirectPort > 0 {
+		ws.redirect = &http.Server{
+			Addr:    net.JoinHostPort(ws.config.Collector.BindAddress, strconv.Itoa(tlsConfig.RedirectPort)),
+			Handler: http.HandlerFunc(ws.redirectToHTTPS),
+		}
+
+		go func() {
+			ws.logger.Info().Int("port", tlsConfig.RedirectPort).Msg("Starting HTTP to HTTPS redirect server")
+			if err := ws.redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				ws.logger.Error().Err(err).Msg("Redirect server error")
+			}
+		}()
+	}
+
 	return nil
 }
 
-// Stop stops the web server
+// redirectToHTTPS redirects plain HTTP requests to the TLS port
+func (ws *webServer) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	target := fmt.Sprintf("https://%s:%d%s", host, ws.config.Collector.Port, r.URL.RequestURI())
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// metricsHandler refreshes point-in-time gauges and serves the metrics registry
+func (ws *webServer) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handlers.MethodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	hubStats := ws.wsHub.Stats()
+	ws.metrics.SetGauge("websocket_connected_clients", "Connected WebSocket clients", float64(hubStats.ConnectedClients))
+	ws.metrics.SetGauge("websocket_messages_broadcast", "WebSocket messages broadcast since start", float64(hubStats.MessagesBroadcast))
+	ws.metrics.SetGauge("websocket_messages_dropped", "WebSocket messages dropped for slow clients", float64(hubStats.MessagesDropped))
+	ws.metrics.SetGauge("http_requests_in_flight", "HTTP requests currently being served", float64(ws.active.Load()))
+	for errorType, count := range ws.apiHandlers.Errors().Summary().Counts {
+		ws.metrics.SetGauge("collector_errors", "Errors tracked since start or the last reset, by type", float64(count), "type", string(errorType))
+	}
+
+	ws.metrics.Handler()(w, r)
+}
+
+// allowedMethods returns the methods with a route for r's path other than
+// the UI's catch-all "/"
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		probe := r.WithContext(r.Context())
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// trackRequests counts in-flight requests so Stop can wait for them to finish
+// before storage is closed
+func (ws *webServer) trackRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws.inFlight.Add(1)
+		ws.active.Add(1)
+		defer func() {
+			ws.active.Add(-1)
+			ws.inFlight.Done()
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stop closes WebSocket clients, then drains in-flight HTTP requests. It
+// returns once all requests have completed or the shutdown timeout expires.
 func (ws *webServer) Stop() error {
 	ws.running = false
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	timeout := time.Duration(ws.config.Server.ShutdownTimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	ws.logger.Info().Msg("Shutting down web server")
-	return ws.server.Shutdown(ctx)
+	ws.logger.Info().
+		Int64("active_requests", ws.active.Load()).
+		Str("timeout", timeout.String()).
+		Msg("Shutting down web server")
+
+	// WebSocket connections are hijacked, so http.Server.Shutdown does not
+	// close them; the hub sends each client a close frame instead
+	if err := ws.wsHub.Shutdown(ctx); err != nil {
+		ws.logger.Warn().Err(err).Msg("Timed out closing WebSocket clients")
+	}
+
+	if ws.redirect != nil {
+		if err := ws.redirect.Shutdown(ctx); err != nil {
+			ws.logger.Warn().Err(err).Msg("Failed to shut down redirect server")
+		}
+	}
+
+	if err := ws.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to drain web server: %w", err)
+	}
+	// Requests have finished, so no more collection events will arrive
+	ws.notifier.Close()
+
+	drained := make(chan struct{})
+	go func() {
+		ws.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		ws.logger.Info().Msg("Web server drained")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out with %d requests in flight: %w", ws.active.Load(), ctx.Err())
+	}
 }
 
 // IsRunning returns true if the web server is running
 func (ws *webServer) IsRunning() bool {
 	return ws.running
 }
+
+// SelfCheck returns the latest self-check report
+func (ws *webServer) SelfCheck() *models.SelfCheckReport {
+	return ws.apiHandlers.SelfCheck()
+}
@@ -0,0 +1,444 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"aktis-collector-jira/internal/common"
+	"aktis-collector-jira/internal/interfaces"
+
+	"github.com/ternarybob/arbor"
+)
+
+// startTestWebServer starts the web server on a free local port with the
+// default configuration, changed by configure when it is not nil, and returns
+// it with its base URL and storage. It is stopped when the test ends unless
+// the test stops it first.
+func startTestWebServer(t *testing.T, configure func(*common.Config)) (*webServer, string, interfaces.Storage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	config := common.DefaultConfig()
+	dir := t.TempDir()
+	config.Collector.BindAddress = "127.0.0.1"
+	config.Collector.Port = port
+	config.Collector.ExtensionDir = filepath.Join(dir, "extension")
+	config.Storage.DatabasePath = filepath.Join(dir, "test.db")
+	config.Storage.BackupDir = filepath.Join(dir, "backups")
+	if configure != nil {
+		configure(config)
+	}
+
+	storage, err := OpenStorage(&config.Storage, arbor.NewLogger())
+	if err != nil {
+		t.Fatalf("OpenStorage: %v", err)
+	}
+	service, err := NewWebServer(config, storage, arbor.NewLogger())
+	if err != nil {
+		t.Fatalf("NewWebServer: %v", err)
+	}
+	ws := service.(*webServer)
+	if err := ws.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() {
+		if ws.IsRunning() {
+			ws.Stop()
+		}
+		storage.Close()
+	})
+
+	return ws, "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), storage
+}
+
+func TestWebServerClosesSlowHeaders(t *testing.T) {
+	_, baseURL, _ := startTestWebServer(t, func(config *common.Config) {
+		config.Server.ReadHeaderTimeoutSeconds = 1
+	})
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(baseURL, "http://"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Drip one header line at a time and never finish the request; the
+	// header deadline is absolute, so the drip does not extend it
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+	start := time.Now()
+	fmt.Fprintf(conn, "POST /receiver HTTP/1.1\r\nHost: localhost\r\n")
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-closed:
+			if elapsed := time.Since(start); elapsed < time.Second {
+				t.Errorf("connection closed after %v, before the 1s header timeout", elapsed)
+			}
+			// The server still answers complete requests
+			resp, err := http.Get(baseURL + "/health")
+			if err != nil {
+				t.Fatalf("GET /health: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("GET /health status = %d", resp.StatusCode)
+			}
+			return
+		case <-ticker.C:
+			if time.Since(start) > 5*time.Second {
+				t.Fatal("slow header request still open after 5s")
+			}
+			fmt.Fprintf(conn, "X-Drip-%d: x\r\n", i)
+		}
+	}
+}
+
+func TestWebServerStopDrainsReceiver(t *testing.T) {
+	ws, baseURL, storage := startTestWebServer(t, nil)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"url":       "https://example.atlassian.net/projects/ABC/issues",
+		"title":     "ABC issues",
+		"data":      map[string]interface{}{"html": issueListPage(3)},
+		"collector": map[string]string{"name": "aktis-chrome-extension", "version": "0.1.200"},
+	})
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+
+	// Send half the payload so the request is in flight when Stop starts
+	bodyReader, bodyWriter := io.Pipe()
+	type result struct {
+		status   int
+		response map[string]interface{}
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Post(baseURL+"/receiver", "application/json", bodyReader)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		var response map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		done <- result{status: resp.StatusCode, response: response, err: err}
+	}()
+	bodyWriter.Write(body[:len(body)/2])
+	waitFor(t, "the receiver request to start", func() bool { return ws.active.Load() == 1 })
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- ws.Stop() }()
+	waitFor(t, "the listener to close", func() bool {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(baseURL, "http://"))
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	})
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned with a request in flight: %v", err)
+	default:
+	}
+
+	bodyWriter.Write(body[len(body)/2:])
+	bodyWriter.Close()
+
+	res := <-done
+	if res.err != nil || res.status != http.StatusOK || res.response["success"] != true {
+		t.Fatalf("receiver: status %d, response %v, err %v", res.status, res.response, res.err)
+	}
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if tickets, err := storage.LoadTickets("ABC"); err != nil || len(tickets) != 3 {
+		t.Errorf("stored %d tickets, %v; want 3", len(tickets), err)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after 5 seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebServerWrongMethodIsJSON(t *testing.T) {
+	// Run from the repository root so the UI pages and their routes load
+	t.Chdir("../..")
+	ws, baseURL, _ := startTestWebServer(t, nil)
+	if ws.uiHandlers == nil {
+		t.Fatal("UI handlers not loaded")
+	}
+
+	// Every route registered by NewWebServer; no handler accepts PATCH
+	paths := []string{
+		"/health", "/version", "/extension/download", "/extension/upload",
+		"/status", "/projects", "/projects/ABC/enable", "/projects/ABC/disable",
+		"/projects/ABC/tickets", "/tickets", "/tickets/ABC-1", "/database",
+		"/config", "/stats", "/aggregate", "/errors", "/extensions",
+		"/selfcheck", "/assessments", "/reprocess", "/backfill", "/logs", "/export",
+		"/assess", "/receiver", "/ws", "/ws/stats", "/metrics",
+		"/static/app.css", "/", "/database/data", "/ui/tickets",
+		"/ui/tickets/ABC-1", "/ui/tickets/ABC-1/raw", "/ui/settings",
+		"/ui/logs", "/ui/dashboard", "/ui/stats", "/ui/projects",
+		"/ui/projects/ABC", "/ui/projects/ABC/tickets",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			checkJSONError(t, http.MethodPatch, baseURL+path, http.StatusMethodNotAllowed)
+		})
+	}
+
+	// Routes registered for one method name it in the Allow header
+	if resp := checkJSONError(t, http.MethodGet, baseURL+"/projects/ABC/enable", http.StatusMethodNotAllowed); resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET /projects/ABC/enable: Allow = %q, want POST", resp.Header.Get("Allow"))
+	}
+	checkJSONError(t, http.MethodGet, baseURL+"/no/such/route", http.StatusNotFound)
+}
+
+// checkJSONError sends a request without a body and checks it is answered
+// with the API's JSON error shape and the given status
+func checkJSONError(t *testing.T, method, url string, status int) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != status {
+		t.Errorf("%s %s: status = %d, want %d", method, url, resp.StatusCode, status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("%s %s: Content-Type = %q", method, url, contentType)
+	}
+	var body struct {
+		Success   *bool  `json:"success"`
+		Error     string `json:"error"`
+		Status    int    `json:"status"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("%s %s: decoding response: %v", method, url, err)
+	}
+	if body.Success == nil || *body.Success || body.Error == "" || body.Status != resp.StatusCode || body.RequestID == "" {
+		t.Errorf("%s %s: response = %+v", method, url, body)
+	}
+	return resp
+}
+
+func TestWebServerPprofRoutes(t *testing.T) {
+	// Run from the repository root so the UI catch-all route is registered
+	// and production answers its 404 rather than the bare mux
+	t.Chdir("../..")
+
+	tests := []struct {
+		name        string
+		environment string
+		enablePprof bool
+		want        int
+	}{
+		{"development", "development", false, http.StatusOK},
+		{"production with enable_pprof", "production", true, http.StatusOK},
+		{"production", "production", false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, baseURL, _ := startTestWebServer(t, func(config *common.Config) {
+				config.Collector.Environment = tt.environment
+				config.Collector.EnablePprof = tt.enablePprof
+			})
+
+			for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/vars"} {
+				resp, err := http.Get(baseURL + path)
+				if err != nil {
+					t.Fatalf("GET %s: %v", path, err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != tt.want {
+					t.Errorf("GET %s: status = %d, want %d", path, resp.StatusCode, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestReadOnlyServerDuringWrites serves a backup of a database read-only
+// while the collector that owns the original keeps writing to it. The copy
+// has its own lock, so the writes never block the reader; the live file
+// cannot be opened read-only until the writer closes it.
+func TestReadOnlyServerDuringWrites(t *testing.T) {
+	dir := t.TempDir()
+	live := newTestStorage(t, func(config *common.StorageConfig) {
+		config.DatabasePath = filepath.Join(dir, "live.db")
+	})
+	if _, err := live.SaveTickets("ABC", numberedTickets("ABC", 3, "Seeded")); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	if _, err := live.SaveTickets("XYZ", numberedTickets("XYZ", 2, "Seeded")); err != nil {
+		t.Fatalf("SaveTickets: %v", err)
+	}
+	copyPath := filepath.Join(dir, "copy.db")
+	if _, err := live.Backup(copyPath); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	_, baseURL, _ := startTestWebServer(t, func(config *common.Config) {
+		config.Storage.DatabasePath = copyPath
+		config.Storage.ReadOnly = true
+	})
+
+	// Keep the live database busy for the whole test
+	stop := make(chan struct{})
+	writerDone := make(chan error, 1)
+	go func() {
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				writerDone <- nil
+				return
+			default:
+			}
+			if _, err := live.SaveTickets("ABC", numberedTickets("ABC", 50, fmt.Sprintf("Write %d", i))); err != nil {
+				writerDone <- err
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		if err := <-writerDone; err != nil {
+			t.Errorf("writing the live database: %v", err)
+		}
+	}()
+
+	for range 5 {
+		var list struct {
+			Success bool `json:"success"`
+			Total   int  `json:"total"`
+		}
+		getJSON(t, baseURL+"/tickets", &list)
+		if !list.Success || list.Total != 5 {
+			t.Errorf("/tickets: success = %v, total = %d, want 5", list.Success, list.Total)
+		}
+
+		var stats struct {
+			Success bool `json:"success"`
+			Stats   struct {
+				Total     int            `json:"total"`
+				ByProject map[string]int `json:"by_project"`
+			} `json:"stats"`
+		}
+		getJSON(t, baseURL+"/stats", &stats)
+		if !stats.Success || stats.Stats.Total != 5 || stats.Stats.ByProject["ABC"] != 3 {
+			t.Errorf("/stats: success = %v, total = %d, by project = %v", stats.Success, stats.Stats.Total, stats.Stats.ByProject)
+		}
+	}
+
+	// Read-only opens share the lock, so a second reader can open the copy
+	// while the server has it
+	second, err := NewReadOnlyStorage(&common.StorageConfig{DatabasePath: copyPath})
+	if err != nil {
+		t.Fatalf("second read-only open of the copy: %v", err)
+	}
+	tickets, err := second.LoadTickets("XYZ")
+	second.Close()
+	if err != nil || len(tickets) != 2 {
+		t.Errorf("second reader LoadTickets(XYZ) = %d tickets, %v; want 2", len(tickets), err)
+	}
+
+	resp, err := http.Post(baseURL+"/receiver", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST /receiver: %v", err)
+	}
+	var rejected struct {
+		Code string `json:"code"`
+	}
+	json.NewDecoder(resp.Body).Decode(&rejected)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || rejected.Code != "read_only" {
+		t.Errorf("POST /receiver = %d with code %q, want 403 read_only", resp.StatusCode, rejected.Code)
+	}
+
+	if _, err := NewReadOnlyStorage(&common.StorageConfig{DatabasePath: filepath.Join(dir, "live.db")}); !errors.Is(err, ErrDatabaseLocked) {
+		t.Errorf("read-only open of the live database = %v, want ErrDatabaseLocked", err)
+	}
+}
+
+// getJSON fetches url, expecting 200, and decodes the body into out
+func getJSON(t *testing.T, url string, out interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, want 200", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("GET %s: decoding: %v", url, err)
+	}
+}
+
+func TestWebServerProjectToggleKeys(t *testing.T) {
+	_, baseURL, storage := startTestWebServer(t, func(config *common.Config) {
+		config.Projects.Projects = []string{"DEF"}
+	})
+
+	checkJSONError(t, http.MethodPost, baseURL+"/projects/FOO%20BAR/disable", http.StatusBadRequest)
+	checkJSONError(t, http.MethodPost, baseURL+"/projects/1ABC/enable", http.StatusBadRequest)
+	checkJSONError(t, http.MethodPost, baseURL+"/projects/NOPE/enable", http.StatusNotFound)
+	checkJSONError(t, http.MethodPost, baseURL+"/projects/NOPE/disable", http.StatusNotFound)
+
+	// A configured project can be disabled before anything is collected
+	resp, err := http.Post(baseURL+"/projects/def/disable", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST /projects/def/disable: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /projects/def/disable status = %d", resp.StatusCode)
+	}
+
+	projects, err := storage.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Key != "DEF" || !projects[0].Disabled {
+		t.Errorf("projects = %+v, want only DEF disabled", projects)
+	}
+}
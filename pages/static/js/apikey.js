@@ -0,0 +1,33 @@
+// Fetch endpoints that require the collector's API key. On a 401 the user is
+// asked for the key once; it is kept for the browser session and sent as
+// X-API-Key with later requests.
+const apiKeyStorageName = 'aktis-api-key';
+let apiKeyDeclined = false;
+
+function storedAPIKey() {
+    return sessionStorage.getItem(apiKeyStorageName) || '';
+}
+
+async function apiFetch(url, options = {}) {
+    const send = () => {
+        const headers = new Headers(options.headers || {});
+        const key = storedAPIKey();
+        if (key) {
+            headers.set('X-API-Key', key);
+        }
+        return fetch(url, { ...options, headers: headers });
+    };
+
+    const response = await send();
+    if (response.status !== 401 || apiKeyDeclined) {
+        return response;
+    }
+
+    const key = window.prompt('This collector requires an API key');
+    if (!key) {
+        apiKeyDeclined = true;
+        return response;
+    }
+    sessionStorage.setItem(apiKeyStorageName, key);
+    return send();
+}
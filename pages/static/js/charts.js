@@ -0,0 +1,95 @@
+// Render horizontal bar charts from data-chart attributes
+function renderCharts(root) {
+    root.querySelectorAll('[data-chart]').forEach(el => {
+        let entries = [];
+        try {
+            entries = JSON.parse(el.dataset.chart) || [];
+        } catch (e) {
+            console.error('Invalid chart data:', e);
+            return;
+        }
+
+        const max = entries.reduce((m, e) => Math.max(m, e.count), 0);
+        el.innerHTML = '';
+        entries.forEach(entry => {
+            const row = document.createElement('div');
+            row.className = 'chart-row';
+
+            const label = document.createElement('span');
+            label.className = 'chart-label';
+            label.textContent = entry.label;
+
+            const bar = document.createElement('span');
+            bar.className = 'chart-bar';
+            bar.style.width = max > 0 ? (entry.count / max * 100) + '%' : '0';
+
+            row.appendChild(label);
+            row.appendChild(bar);
+            el.appendChild(row);
+        });
+    });
+}
+
+function refreshStats() {
+    htmx.trigger('#stats-content', 'refresh');
+}
+
+// Show a tracked collector error briefly in the corner of the page
+function showErrorToast(error) {
+    const toast = document.createElement('div');
+    toast.className = 'toast error-toast';
+    toast.textContent = '[' + error.component + '] ' + error.message;
+    document.body.appendChild(toast);
+    setTimeout(() => toast.remove(), 8000);
+}
+
+// Refresh statistics when the collector stores new data. Falls back to
+// polling when the WebSocket is unavailable (e.g. an API key is required).
+function connectLiveUpdates() {
+    const status = document.getElementById('live-status');
+    const scheme = location.protocol === 'https:' ? 'wss:' : 'ws:';
+    let pollTimer = null;
+
+    const startPolling = () => {
+        status.textContent = 'POLLING';
+        if (!pollTimer) {
+            pollTimer = setInterval(refreshStats, 30000);
+        }
+    };
+
+    let ws;
+    try {
+        ws = new WebSocket(scheme + '//' + location.host + '/ws');
+    } catch (e) {
+        startPolling();
+        return;
+    }
+
+    ws.onopen = () => {
+        status.textContent = 'LIVE';
+        if (pollTimer) {
+            clearInterval(pollTimer);
+            pollTimer = null;
+        }
+    };
+    ws.onmessage = (event) => {
+        try {
+            const msg = JSON.parse(event.data);
+            if (msg.type === 'collection_success' || msg.type === 'tickets_cleared') {
+                refreshStats();
+            } else if (msg.type === 'error') {
+                showErrorToast(msg.data);
+            }
+        } catch (e) {
+            console.error('Invalid WebSocket message:', e);
+        }
+    };
+    ws.onclose = () => {
+        startPolling();
+        setTimeout(connectLiveUpdates, 30000);
+    };
+}
+
+document.body.addEventListener('htmx:afterSwap', evt => renderCharts(evt.target));
+renderCharts(document);
+connectLiveUpdates();
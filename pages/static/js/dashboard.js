@@ -0,0 +1,220 @@
+// Update performance metrics
+function updateMetrics() {
+    const elements = document.querySelectorAll('.status-value');
+    elements.forEach(el => {
+        if (Math.random() > 0.95) {
+            el.style.transform = 'scale(1.05)';
+            setTimeout(() => {
+                el.style.transform = 'scale(1)';
+            }, 200);
+        }
+    });
+}
+setInterval(updateMetrics, 3000);
+
+function showTab(tabName, event) {
+    if (event) {
+        event.preventDefault();
+    }
+
+    document.querySelectorAll('.tab-content').forEach(tab => {
+        tab.classList.remove('active');
+    });
+
+    document.querySelectorAll('.navbar-link').forEach(link => {
+        link.classList.remove('active');
+    });
+
+    document.getElementById(tabName).classList.add('active');
+
+    if (event) {
+        event.target.classList.add('active');
+    } else {
+        document.querySelector(`.navbar-link[href="#${tabName}"]`).classList.add('active');
+    }
+
+    // Clear the clear-result box when navigating away from settings
+    if (tabName !== 'settings') {
+        const clearResult = document.getElementById('clear-result');
+        if (clearResult) {
+            clearResult.innerHTML = '';
+        }
+    }
+
+    window.location.hash = tabName;
+}
+
+function handleHashChange() {
+    const hash = window.location.hash.slice(1);
+    const validTabs = ['overview', 'storage', 'settings'];
+    const tabName = validTabs.includes(hash) ? hash : 'overview';
+    showTab(tabName);
+}
+
+window.addEventListener('load', function() {
+    handleHashChange();
+});
+
+window.addEventListener('hashchange', handleHashChange);
+
+// Format and highlight responses
+document.body.addEventListener('htmx:afterSwap', function(evt) {
+    // Handle metrics content specially
+    if (evt.target.id === 'metrics-content') {
+        try {
+            const content = evt.target.textContent.trim();
+            if (content.startsWith('{')) {
+                const data = JSON.parse(content);
+                renderJiraMetrics(evt.target, data);
+                return;
+            }
+        } catch (e) {
+            console.error('Error parsing metrics data:', e);
+        }
+    }
+
+    // HTML partials are rendered server-side and swapped in as-is
+    const contentType = evt.detail.xhr ? evt.detail.xhr.getResponseHeader('Content-Type') || '' : '';
+    if (contentType.startsWith('text/html')) {
+        return;
+    }
+
+    if (evt.target.id.includes('-content')) {
+        try {
+            const content = evt.target.textContent.trim();
+
+            if (content.startsWith('<')) {
+                return;
+            }
+
+            if (content.startsWith('{') || content.startsWith('[')) {
+                try {
+                    const jsonData = JSON.parse(content);
+                    const formattedJson = JSON.stringify(jsonData, null, 2);
+
+                    evt.target.innerHTML = '<pre><code class="language-json">' +
+                        escapeHtml(formattedJson) + '</code></pre>';
+
+                    evt.target.querySelectorAll('pre code').forEach((block) => {
+                        hljs.highlightElement(block);
+                    });
+                } catch (jsonError) {
+                    formatAsPlainText(evt.target, content);
+                }
+            } else if (content.length > 0) {
+                formatAsPlainText(evt.target, content);
+            }
+        } catch (e) {
+            console.error('Error formatting content:', e);
+        }
+    }
+});
+
+// Show the result of a per-project clear and refresh dependent views
+function handleProjectCleared(button, event) {
+    const result = button.parentElement.querySelector('.clear-result');
+    let message = 'Failed to clear tickets';
+    try {
+        const data = JSON.parse(event.detail.xhr.responseText);
+        message = data.success ? data.message : (data.error || message);
+    } catch (e) {
+        console.error('Invalid clear response:', e);
+    }
+
+    if (!event.detail.successful) {
+        result.textContent = message;
+        return;
+    }
+
+    // Refresh the projects table after the message has been seen
+    result.textContent = message;
+    htmx.trigger('#tickets-content', 'refresh');
+    htmx.trigger('#buffer-content', 'refresh');
+    htmx.trigger('#metrics-content', 'refresh');
+    setTimeout(() => htmx.trigger('#projects-content', 'refresh'), 2000);
+}
+
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+function formatAsPlainText(target, content) {
+    if (content.includes('\n') || content.includes('=')) {
+        target.innerHTML = '<pre><code>' + escapeHtml(content) + '</code></pre>';
+    } else {
+        target.textContent = content;
+    }
+}
+
+function renderJiraMetrics(target, data) {
+    // Jira-specific metrics rendering
+    const collector = data.collector || {};
+    const projects = data.projects || [];
+    const stats = data.stats || {};
+
+    const formatUptime = (seconds) => {
+        if (!seconds) return 'N/A';
+        const hours = Math.floor(seconds / 3600);
+        const minutes = Math.floor((seconds % 3600) / 60);
+        return hours > 0 ? `${hours}h ${minutes}m` : `${minutes}m`;
+    };
+
+    const getStatusClass = (running, errorCount = 0) => {
+        if (!running) return 'critical';
+        if (errorCount > 0) return 'warning';
+        return 'healthy';
+    };
+
+    const collectorClass = getStatusClass(collector.running, collector.error_count);
+
+    target.innerHTML = `
+        <div class="metrics-header">
+            <h3>Jira Collector & Storage Metrics</h3>
+        </div>
+        <div class="metrics-grid">
+            <div class="jira-metric-card ${collectorClass}">
+                <div class="metric-title">Collector Status</div>
+                <div class="metric-value">${collector.running ? 'RUNNING' : 'STOPPED'}</div>
+                <div class="metric-subtitle">${projects.length || 0} projects • ${formatUptime(collector.uptime)}</div>
+            </div>
+            <div class="jira-metric-card projects">
+                <div class="metric-title">Projects Configured</div>
+                <div class="metric-value">${projects.length || 0}</div>
+                <div class="metric-subtitle">Active configurations</div>
+            </div>
+            <div class="jira-metric-card tickets">
+                <div class="metric-title">Total Tickets</div>
+                <div class="metric-value">${stats.total_tickets || 0}</div>
+                <div class="metric-subtitle">Collected and stored</div>
+            </div>
+            <div class="jira-metric-card issues">
+                <div class="metric-title">Last Collection</div>
+                <div class="metric-value">${stats.last_collection || 'Never'}</div>
+                <div class="metric-subtitle">Most recent update</div>
+            </div>
+            <div class="jira-metric-card healthy">
+                <div class="metric-title">Database Size</div>
+                <div class="metric-value">${stats.database_size || 'N/A'}</div>
+                <div class="metric-subtitle">Storage utilization</div>
+            </div>
+            <div class="jira-metric-card ${collector.error_count > 0 ? 'warning' : 'healthy'}">
+                <div class="metric-title">Collection Errors</div>
+                <div class="metric-value">${collector.error_count || 0}</div>
+                <div class="metric-subtitle">Total error count</div>
+            </div>
+        </div>
+    `;
+}
+
+// Auto-refresh on window focus
+document.addEventListener('visibilitychange', function() {
+    if (!document.hidden) {
+        const activeTab = document.querySelector('.tab-content.active');
+        if (activeTab) {
+            const refreshBtns = activeTab.querySelectorAll('.refresh-btn:not(.clear-btn)');
+            refreshBtns.forEach(btn => btn.click());
+        }
+    }
+});
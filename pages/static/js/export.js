@@ -0,0 +1,60 @@
+// Download tickets from /export. The project comes from the controls'
+// data attribute; an active status filter in the same card is respected.
+async function exportTickets(button, format) {
+    const controls = button.closest('.export-controls');
+    const status = controls.querySelector('.export-status');
+    const params = new URLSearchParams({ format: format });
+
+    if (controls.dataset.project) {
+        params.set('project', controls.dataset.project);
+    }
+    const card = controls.closest('.card');
+    const filters = card ? card.querySelector('form.tickets-filters') : null;
+    if (filters) {
+        for (const name of ['project', 'status']) {
+            const field = filters.elements[name];
+            if (field && field.value) {
+                params.set(name, field.value);
+            }
+        }
+    }
+
+    const buttons = controls.querySelectorAll('button');
+    buttons.forEach(b => b.disabled = true);
+    status.className = 'export-status loading';
+    status.textContent = 'Preparing download...';
+
+    try {
+        const response = await apiFetch('/export?' + params.toString());
+        if (!response.ok) {
+            let message = 'Export failed (' + response.status + ')';
+            try {
+                const data = await response.json();
+                message = data.error || message;
+            } catch (e) {
+                // Non-JSON error body
+            }
+            throw new Error(message);
+        }
+
+        const blob = await response.blob();
+        const disposition = response.headers.get('Content-Disposition') || '';
+        const match = disposition.match(/filename="([^"]+)"/);
+
+        const link = document.createElement('a');
+        link.href = URL.createObjectURL(blob);
+        link.download = match ? match[1] : 'tickets.' + format;
+        document.body.appendChild(link);
+        link.click();
+        link.remove();
+        URL.revokeObjectURL(link.href);
+
+        status.className = 'export-status';
+        status.textContent = '';
+    } catch (e) {
+        status.className = 'export-status error';
+        status.textContent = e.message;
+    } finally {
+        buttons.forEach(b => b.disabled = false);
+    }
+}
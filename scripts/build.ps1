@@ -377,6 +377,15 @@ if (Test-Path $extensionSourcePath) {
     }
 
     Write-Host "Deployed Chrome Extension: bin/aktis-chrome-extension/" -ForegroundColor Green
+
+    # Package extension for download from the web interface (/static/extension/)
+    $extensionZipDir = Join-Path -Path $pagesDestPath -ChildPath "static\extension"
+    if (Test-Path $pagesDestPath) {
+        New-Item -ItemType Directory -Path $extensionZipDir -Force | Out-Null
+        $extensionZipPath = Join-Path -Path $extensionZipDir -ChildPath "aktis-chrome-extension.zip"
+        Compress-Archive -Path (Join-Path -Path $extensionDestPath -ChildPath "*") -DestinationPath $extensionZipPath -Force
+        Write-Host "Packaged Chrome Extension: bin/pages/static/extension/aktis-chrome-extension.zip" -ForegroundColor Green
+    }
 } else {
     Write-Warning "Chrome extension source not found at: $extensionSourcePath"
 }
@@ -0,0 +1,60 @@
+//go:build playwright
+
+// Settings for the browser flows. Paths and Jira credentials come from the
+// environment so none are kept in the source:
+//
+//	JIRA_URL        Jira page to open, e.g. https://example.atlassian.net/jira/projects (required)
+//	JIRA_USERNAME   Jira login email (required)
+//	JIRA_PASSWORD   Jira password (required)
+//	EXTENSION_PATH  Unpacked extension directory (default ../bin/aktis-chrome-extension)
+//	COLLECTOR_PATH  Collector binary (default ../bin/aktis-collector-jira)
+//	COLLECTOR_CONFIG Collector config file (default ../deployments/aktis-collector-jira.toml)
+
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+type browserSettings struct {
+	TargetURL       string
+	Username        string
+	Password        string
+	ExtensionPath   string
+	CollectorPath   string
+	CollectorConfig string
+}
+
+func loadSettings() browserSettings {
+	collector := filepath.Join("..", "bin", "aktis-collector-jira")
+	if runtime.GOOS == "windows" {
+		collector += ".exe"
+	}
+
+	return browserSettings{
+		TargetURL:       requireEnv("JIRA_URL"),
+		Username:        requireEnv("JIRA_USERNAME"),
+		Password:        requireEnv("JIRA_PASSWORD"),
+		ExtensionPath:   envOr("EXTENSION_PATH", filepath.Join("..", "bin", "aktis-chrome-extension")),
+		CollectorPath:   envOr("COLLECTOR_PATH", collector),
+		CollectorConfig: envOr("COLLECTOR_CONFIG", filepath.Join("..", "deployments", "aktis-collector-jira.toml")),
+	}
+}
+
+func requireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		log.Fatalf("%s must be set", name)
+	}
+	return value
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
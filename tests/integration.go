@@ -1,28 +1,29 @@
+//go:build playwright && !manual
+
 // Integration test: Start collector server, open browser with extension, verify data collection
+//
+// Run from the tests directory with the settings described in env.go:
+//
+//	go run -tags playwright .
 
 package main
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"os/exec"
-	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
 
-const extensionPath = "C:\\development\\aktis\\aktis-collector-jira\\bin\\aktis-chrome-extension"
-const collectorPath = "C:\\development\\aktis\\aktis-collector-jira\\bin\\aktis-collector-jira.exe"
-const targetUrl = "https://bobmcallan.atlassian.net/jira/projects"
-
 func main() {
 	log.SetFlags(log.Ltime)
+	settings := loadSettings()
 
 	// Step 1: Start the collector server
 	log.Printf("Starting Aktis collector server...")
-	collectorCmd := exec.Command(collectorPath, "-config", "deployments/aktis-collector-jira.toml")
+	collectorCmd := exec.Command(settings.CollectorPath, "-config", settings.CollectorConfig)
 	collectorCmd.Stdout = os.Stdout
 	collectorCmd.Stderr = os.Stderr
 
@@ -57,7 +58,7 @@ func main() {
 	browser, err := pw.Chromium.LaunchPersistentContext(tempDir, playwright.BrowserTypeLaunchPersistentContextOptions{
 		Headless: playwright.Bool(false),
 		Args: []string{
-			"--load-extension=" + extensionPath,
+			"--load-extension=" + settings.ExtensionPath,
 		},
 		Timeout: playwright.Float(60000),
 	})
@@ -78,7 +79,7 @@ func main() {
 
 	// Step 4: Login to Jira
 	log.Printf("Navigating to Jira and logging in...")
-	if _, err := page.Goto(targetUrl); err != nil {
+	if _, err := page.Goto(settings.TargetURL); err != nil {
 		log.Fatalf("Could not navigate: %v", err)
 	}
 
@@ -91,13 +92,13 @@ func main() {
 	page.WaitForSelector("input[name='username']", playwright.PageWaitForSelectorOptions{
 		State: playwright.WaitForSelectorStateVisible,
 	})
-	page.Fill("input[name='username']", "bobmcallan@gmail.com")
+	page.Fill("input[name='username']", settings.Username)
 	page.Click("button[type='submit']")
 
 	page.WaitForSelector("input[name='password']", playwright.PageWaitForSelectorOptions{
 		State: playwright.WaitForSelectorStateVisible,
 	})
-	page.Fill("input[name='password']", "t!!7LL4#j%Wej4")
+	page.Fill("input[name='password']", settings.Password)
 	page.Click("button[type='submit']")
 
 	log.Printf("Waiting for login to complete...")
@@ -107,7 +108,7 @@ func main() {
 
 	// Step 5: Navigate to projects page and wait for auto-collection
 	log.Printf("Navigating to projects page...")
-	if _, err := page.Goto(targetUrl); err != nil {
+	if _, err := page.Goto(settings.TargetURL); err != nil {
 		log.Fatalf("Could not navigate to projects: %v", err)
 	}
 
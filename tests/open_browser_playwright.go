@@ -1,5 +1,11 @@
+//go:build playwright && manual
+
 // -----------------------------------------------------------------------
 // Playwright-based browser test with extension loading
+//
+// Run from the tests directory with the settings described in env.go:
+//
+//	go run -tags playwright,manual .
 // -----------------------------------------------------------------------
 
 package main
@@ -13,12 +19,9 @@ import (
 	"github.com/playwright-community/playwright-go"
 )
 
-const extensionPath = "C:\\development\\aktis\\aktis-collector-jira\\bin\\aktis-chrome-extension"
-
-// The target URL to automate
-const targetUrl = "https://bobmcallan.atlassian.net/jira/projects"
-
 func main() {
+	settings := loadSettings()
+
 	// Install playwright browsers if needed
 	err := playwright.Install()
 	if err != nil {
@@ -31,7 +34,7 @@ func main() {
 	}
 	defer pw.Stop()
 
-	log.Printf("Launching Chrome with extension from: %s", extensionPath)
+	log.Printf("Launching Chrome with extension from: %s", settings.ExtensionPath)
 
 	// Use a temporary directory for isolation
 	tempDir, err := os.MkdirTemp("", "playwright-profile-")
@@ -45,7 +48,7 @@ func main() {
 		Headless: playwright.Bool(false),
 		// Use the correct flags to load the unpacked extension
 		Args: []string{
-			"--load-extension=" + extensionPath,
+			"--load-extension=" + settings.ExtensionPath,
 		},
 		Timeout: playwright.Float(60000), // 60 seconds timeout for launch
 	})
@@ -67,7 +70,7 @@ func main() {
 
 	// --- LOGIN SEQUENCE ---
 	log.Printf("Navigating to Jira projects page...")
-	if _, err := page.Goto(targetUrl, playwright.PageGotoOptions{
+	if _, err := page.Goto(settings.TargetURL, playwright.PageGotoOptions{
 		Timeout: playwright.Float(30000),
 	}); err != nil {
 		log.Fatalf("Could not navigate: %v", err)
@@ -84,7 +87,7 @@ func main() {
 	page.WaitForSelector("input[name='username']", playwright.PageWaitForSelectorOptions{
 		State: playwright.WaitForSelectorStateVisible,
 	})
-	if err := page.Fill("input[name='username']", "bobmcallan@gmail.com"); err != nil {
+	if err := page.Fill("input[name='username']", settings.Username); err != nil {
 		log.Fatalf("Could not enter username: %v", err)
 	}
 
@@ -98,7 +101,7 @@ func main() {
 	page.WaitForSelector("input[name='password']", playwright.PageWaitForSelectorOptions{
 		State: playwright.WaitForSelectorStateVisible,
 	})
-	if err := page.Fill("input[name='password']", "t!!7LL4#j%Wej4"); err != nil {
+	if err := page.Fill("input[name='password']", settings.Password); err != nil {
 		log.Fatalf("Could not enter password: %v", err)
 	}
 
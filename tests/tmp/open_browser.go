@@ -3,20 +3,31 @@
 // Modified By: Bob McAllan
 // -----------------------------------------------------------------------
 
+//go:build chromedp
+
+// Run with JIRA_URL, JIRA_USERNAME and JIRA_PASSWORD set; CHROME_PATH and
+// EXTENSION_PATH override the default locations:
+//
+//	go run -tags chromedp ./tmp
+
 package main
 
 import (
 	"context"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/chromedp/chromedp"
 )
 
-const chromeExecutablePath = "C:\\Program Files\\Google\\Chrome\\Application\\chrome.exe"
-const extensionPath = "C:\\development\\aktis\\aktis-collector-jira\\bin\\aktis-chrome-extension"
-
 func main() {
+	targetURL := requireEnv("JIRA_URL")
+	username := requireEnv("JIRA_USERNAME")
+	password := requireEnv("JIRA_PASSWORD")
+	extensionPath := envOr("EXTENSION_PATH", filepath.Join("..", "bin", "aktis-chrome-extension"))
+
 	log.Printf("Starting Chromedp without pre-loaded extension")
 	log.Printf("Extension will need to be manually loaded from: %s", extensionPath)
 
@@ -25,8 +36,10 @@ func main() {
 		chromedp.Flag("headless", false),
 		chromedp.Flag("remote-debugging-port", "9222"),
 		chromedp.Flag("disable-gpu", true),
-		chromedp.ExecPath(chromeExecutablePath),
 	)
+	if chromePath := os.Getenv("CHROME_PATH"); chromePath != "" {
+		opts = append(opts, chromedp.ExecPath(chromePath))
+	}
 
 	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
 	defer cancel()
@@ -40,18 +53,18 @@ func main() {
 	log.Printf("Navigating to Jira projects page and attempting login...")
 
 	loginActions := chromedp.Tasks{
-		chromedp.Navigate(`https://bobmcallan.atlassian.net/jira/projects`),
+		chromedp.Navigate(targetURL),
 		chromedp.Sleep(2 * time.Second),
 		chromedp.WaitVisible(`a[href*="login"]`, chromedp.ByQuery),
 		chromedp.Evaluate(`document.querySelector('a[href*="login"]').click()`, nil),
 		chromedp.Sleep(3 * time.Second),
 		chromedp.WaitVisible(`input[name="username"]`, chromedp.ByQuery),
-		chromedp.SendKeys(`input[name="username"]`, "bobmcallan@gmail.com", chromedp.ByQuery),
+		chromedp.SendKeys(`input[name="username"]`, username, chromedp.ByQuery),
 		chromedp.Sleep(500 * time.Millisecond),
 		chromedp.Evaluate(`document.querySelector('button[type="submit"]').click()`, nil),
 		chromedp.Sleep(2 * time.Second),
 		chromedp.WaitVisible(`input[name="password"]`, chromedp.ByQuery),
-		chromedp.SendKeys(`input[name="password"]`, "t!!7LL4#j%Wej4", chromedp.ByQuery),
+		chromedp.SendKeys(`input[name="password"]`, password, chromedp.ByQuery),
 		chromedp.Sleep(500 * time.Millisecond),
 		chromedp.Evaluate(`document.querySelector('button[type="submit"]').click()`, nil),
 		chromedp.Sleep(5 * time.Second),
@@ -97,10 +110,25 @@ func main() {
 	log.Printf("1. Click 'Load unpacked' button")
 	log.Printf("2. Navigate to: %s", extensionPath)
 	log.Printf("3. Click 'Select Folder'")
-	log.Printf("4. Navigate to: https://bobmcallan.atlassian.net/jira/projects")
+	log.Printf("4. Navigate to: %s", targetURL)
 	log.Printf("5. Click the extension icon to test")
 	log.Printf("========================================\n")
 
 	log.Printf("Browser will remain open for 3 minutes...")
 	time.Sleep(180 * time.Second)
 }
+
+func requireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		log.Fatalf("%s must be set", name)
+	}
+	return value
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}